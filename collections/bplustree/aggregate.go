@@ -0,0 +1,133 @@
+package bplustree
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Aggregates
+//
+// A tree built WithAggregate caches the combined values of the records beneath each
+// node. Rather than recomputing them on every write, a write marks the aggregates of
+// the nodes it changes as out of date, along with those of their ancestors, and they
+// are brought up to date the next time they are read. The ancestors of an out of date
+// node are always out of date themselves, so marking stops at the first that already
+// is.
+//
+// Reads share the tree, so several may bring the same aggregate up to date at once.
+// Each node has a lock of its own for doing so, and publishes its aggregate by setting
+// the aggregated flag once it has been written. Writes take the tree exclusively, and
+// trees keeping aggregates never insert concurrently, so the nodes a read passes
+// through can't change beneath it.
+//
+// Nodes that are frozen by a snapshot are never written to, so a snapshot brings every
+// aggregate up to date before it freezes the tree. Snapshots then only ever read them.
+
+// aggregator combines values for range reductions. Combining identity with any value
+// gives back the value, and combine is associative.
+type aggregator[V any] struct {
+	identity V
+	combine  func(a, b V) V
+}
+
+// Aggregate combines the values of the records with keys between from and to inclusive,
+// in key order. Whole subtrees within the range contribute their cached aggregates, so
+// only the nodes along the edges of the range are visited.
+func (t *tree[K, V]) Aggregate(from K, to K) V {
+	if t.aggregates == nil {
+		panic(collections.ErrNoAggregate)
+	}
+
+	v := t.lockRead()
+	defer t.unlockRead()
+
+	return v.aggregate(from, to)
+}
+
+// invalidateAggregates marks the aggregates of a node that has changed, and those of
+// its ancestors, as out of date. The node must be mutable.
+func (t *tree[K, V]) invalidateAggregates(node *treeNode[K, V]) {
+	if t.aggregates == nil {
+		return
+	}
+
+	for ; node != nil && node.aggregated.Load(); node = node.Parent {
+		node.aggregated.Store(false)
+	}
+}
+
+// aggregate combines the values of the records with keys between from and to inclusive
+func (v view[K, V]) aggregate(from K, to K) V {
+	if v.root == nil || v.compare(from, to) > 0 {
+		return v.aggregates.identity
+	}
+
+	return v.aggregateRange(v.root, from, to, nil)
+}
+
+// aggregateRange combines the values of the records beneath a node with keys between
+// from and to. No key beneath the node exceeds upper, if it is set. The children that
+// fall wholly inside the range contribute their aggregates, and only those straddling
+// its ends are descended into.
+func (v view[K, V]) aggregateRange(node *treeNode[K, V], from K, to K, upper *K) V {
+	result := v.aggregates.identity
+
+	if node.Leaf {
+		for i, key := range node.Keys[0:node.Count] {
+			if v.compare(key, from) >= 0 && v.compare(key, to) <= 0 {
+				result = v.aggregates.combine(result, node.Records[i].Value)
+			}
+		}
+		return result
+	}
+
+	for i, child := range node.Children[0:node.Count] {
+		if v.compare(node.Keys[i], to) > 0 {
+			break
+		}
+
+		// A child holds nothing beyond the lead key of the next, though duplicates of
+		// that key may straddle the two.
+		childUpper := upper
+		if i+1 < node.Count {
+			childUpper = &node.Keys[i+1]
+		}
+		if childUpper != nil && v.compare(*childUpper, from) < 0 {
+			continue
+		}
+
+		if v.compare(node.Keys[i], from) >= 0 && childUpper != nil && v.compare(*childUpper, to) <= 0 {
+			result = v.aggregates.combine(result, v.summarize(child))
+		} else {
+			result = v.aggregates.combine(result, v.aggregateRange(child, from, to, childUpper))
+		}
+	}
+
+	return result
+}
+
+// summarize gets the aggregate of every record beneath a node, bringing any that are
+// out of date up to date on the way.
+func (v view[K, V]) summarize(node *treeNode[K, V]) V {
+	if node.aggregated.Load() {
+		return node.Aggregate
+	}
+
+	node.aggregateLock.Lock()
+	defer node.aggregateLock.Unlock()
+	if node.aggregated.Load() {
+		return node.Aggregate // Brought up to date while we waited
+	}
+
+	result := v.aggregates.identity
+	if node.Leaf {
+		for _, rec := range node.Records[0:node.Count] {
+			result = v.aggregates.combine(result, rec.Value)
+		}
+	} else {
+		for _, child := range node.Children[0:node.Count] {
+			result = v.aggregates.combine(result, v.summarize(child))
+		}
+	}
+
+	node.Aggregate = result
+	node.aggregated.Store(true)
+	return result
+}
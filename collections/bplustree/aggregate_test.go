@@ -0,0 +1,232 @@
+package bplustree
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// sum is the aggregate used by most of the tests
+func sum(a int, b int) int {
+	return a + b
+}
+
+// requireAggregates checks the aggregate of random ranges against adding up the
+// records of the range one at a time.
+func requireAggregates(t *testing.T, rnd *rand.Rand, tree collections.TreeMap[int, int], keyRange int) {
+	for i := 0; i < 50; i++ {
+		from := rnd.Intn(keyRange+20) - 10
+		to := from + rnd.Intn(keyRange/2+1)
+
+		expected := 0
+		for _, kvp := range tree.Range(from, to) {
+			expected += kvp.Value
+		}
+		require.Equal(t, expected, tree.Aggregate(from, to), "Aggregate of %d to %d should match the records", from, to)
+	}
+}
+
+// TestAggregate checks range aggregates stay right through inserts, updates and
+// deletes of every kind, and that snapshots keep theirs.
+func TestAggregate(t *testing.T) {
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order)))
+			tree, err := New[int, int](order, DefaultTestPreAlloc, WithAggregate(0, sum))
+			require.NoError(t, err, "Should be able to initialize")
+			require.Equal(t, 0, tree.Aggregate(0, 100), "Empty tree should give the identity")
+
+			var ids []collections.RecordID
+			for round := 0; round < 10; round++ {
+				for i := 0; i < 200; i++ {
+					k := rnd.Intn(500)
+					switch rnd.Intn(6) {
+					case 0:
+						tree.Delete(k)
+					case 1:
+						tree.Upsert(k, rnd.Intn(100))
+					case 2:
+						tree.Update(k, func(old int, exists bool) int { return old + 1 })
+					case 3:
+						if len(ids) > 0 {
+							tree.UpdateByID(ids[rnd.Intn(len(ids))], rnd.Intn(100))
+						}
+					default:
+						ids = append(ids, tree.Insert(k, rnd.Intn(100)))
+					}
+				}
+				requireAggregates(t, rnd, tree, 500)
+
+				snap := tree.Snapshot()
+				expected := snap.Aggregate(100, 300)
+
+				from := rnd.Intn(500)
+				tree.DeleteRange(from, from+rnd.Intn(50))
+				tree.DeleteByID(ids[rnd.Intn(len(ids))])
+				requireAggregates(t, rnd, tree, 500)
+
+				require.Equal(t, expected, snap.Aggregate(100, 300), "Snapshot aggregate should be unchanged")
+				requireAggregates(t, rnd, snap, 500)
+			}
+
+			tree.(collections.Diagnosable).CheckConsistency()
+		})
+	}
+}
+
+// TestAggregateKeyOrder combines values that don't commute, which only come out right
+// if they are combined in key order.
+func TestAggregateKeyOrder(t *testing.T) {
+	concat := func(a string, b string) string {
+		return a + b
+	}
+	tree, err := New[int, string](3, DefaultTestPreAlloc, WithAggregate("", concat))
+	require.NoError(t, err, "Should be able to initialize")
+
+	for _, k := range rand.New(rand.NewSource(1)).Perm(26) {
+		tree.Insert(k, string(rune('a'+k)))
+	}
+
+	require.Equal(t, "abcdefghijklmnopqrstuvwxyz", tree.Aggregate(0, 25), "Should combine everything in key order")
+	require.Equal(t, "efghij", tree.Aggregate(4, 9), "Should combine the range in key order")
+	require.Equal(t, "z", tree.Aggregate(25, 100), "Should combine the last record")
+	require.Equal(t, "", tree.Aggregate(9, 4), "Reversed bounds should give the identity")
+}
+
+// TestAggregateDuplicateKeys aggregates keys whose records straddle several leaves
+func TestAggregateDuplicateKeys(t *testing.T) {
+	tree, err := New[int, int](2, DefaultTestPreAlloc, WithAggregate(0, sum))
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i++ {
+		tree.Insert(i%4, 1)
+	}
+
+	require.Equal(t, 25, tree.Aggregate(1, 1), "Should combine every record of the key")
+	require.Equal(t, 50, tree.Aggregate(2, 3), "Should combine every record of the keys")
+	require.Equal(t, 100, tree.Aggregate(-1, 4), "Should combine every record")
+}
+
+// TestAggregateConcurrentReads has readers bring the same aggregates up to date at
+// once, between writes that put them out of date.
+func TestAggregateConcurrentReads(t *testing.T) {
+	tree, err := New[int, int](3, DefaultTestPreAlloc, WithAggregate(0, sum))
+	require.NoError(t, err, "Should be able to initialize")
+
+	expected := 0
+	for i := 0; i < 1000; i++ {
+		tree.Insert(i, i)
+		expected += i
+	}
+
+	for round := 0; round < 10; round++ {
+		tree.Upsert(round, 0)
+		expected -= round
+
+		var wg sync.WaitGroup
+		results := make([]int, 4)
+		for r := range results {
+			wg.Add(1)
+			go func(r int) {
+				defer wg.Done()
+				results[r] = tree.Aggregate(-1, 1000)
+			}(r)
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			require.Equal(t, expected, result, "Every reader should get the same aggregate")
+		}
+	}
+}
+
+// TestAggregateSplitAndMerge checks the aggregates of trees that have been split apart
+// and merged back together, both whole and a record at a time.
+func TestAggregateSplitAndMerge(t *testing.T) {
+	option := WithAggregate(0, sum)
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order)))
+			tree, err := New[int, int](order, DefaultTestPreAlloc, option)
+			require.NoError(t, err, "Should be able to initialize")
+			for i := 0; i < 1000; i++ {
+				tree.Insert(rnd.Intn(1000), rnd.Intn(100))
+			}
+			total := tree.Aggregate(0, 1000)
+
+			for round := 0; round < 10; round++ {
+				left, right := tree.SplitAt(rnd.Intn(1000))
+				requireAggregates(t, rnd, left, 1000)
+				requireAggregates(t, rnd, right, 1000)
+				require.Equal(t, total, left.Aggregate(0, 1000)+right.Aggregate(0, 1000), "Halves should add up")
+
+				left.Merge(right)
+				left.(collections.Diagnosable).CheckConsistency()
+				requireAggregates(t, rnd, left, 1000)
+				require.Equal(t, total, left.Aggregate(0, 1000), "Merged tree should add up")
+				tree = left
+			}
+
+			// A tree with an aggregate of its own is merged a record at a time
+			other, err := New[int, int](order, DefaultTestPreAlloc, WithAggregate(0, sum))
+			require.NoError(t, err, "Should be able to initialize")
+			other.Insert(2000, 7)
+			tree.Merge(other)
+			require.Equal(t, total+7, tree.Aggregate(0, 2000), "Should add the merged record")
+			requireAggregates(t, rnd, tree, 2000)
+		})
+	}
+}
+
+// TestAggregateBuildAndLoad aggregates trees that were bulk loaded and read back in
+func TestAggregateBuildAndLoad(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var input []generics.KeyValuePair[int64, int64]
+	for i := int64(0); i < 1000; i++ {
+		input = append(input, generics.KeyValuePair[int64, int64]{Key: i, Value: i})
+	}
+	add := func(a int64, b int64) int64 {
+		return a + b
+	}
+
+	built, err := BuildSorted(5, DefaultTestPreAlloc, 0.7, slices.Values(input), WithAggregate(int64(0), add), WithCodecs[int64, int64](BinaryCodec[int64]{}, BinaryCodec[int64]{}))
+	require.NoError(t, err, "Should build the tree")
+	require.Equal(t, int64(999*1000/2), built.Aggregate(0, 999), "Should add up every value")
+	require.Equal(t, int64(10+11+12), built.Aggregate(10, 12), "Should add up the range")
+
+	var buffer bytes.Buffer
+	_, err = built.(io.WriterTo).WriteTo(&buffer)
+	require.NoError(t, err, "Should write the tree")
+	loaded, err := Load[int64, int64](&buffer, DefaultTestPreAlloc, BinaryCodec[int64]{}, BinaryCodec[int64]{}, WithAggregate(int64(0), add))
+	require.NoError(t, err, "Should load the tree")
+
+	for i := 0; i < 50; i++ {
+		from := int64(rnd.Intn(1000))
+		to := from + int64(rnd.Intn(100))
+		require.Equal(t, built.Aggregate(from, to), loaded.Aggregate(from, to), "Loaded tree should aggregate the same")
+	}
+}
+
+// TestAggregateMissing asks for aggregates that can't be had
+func TestAggregateMissing(t *testing.T) {
+	tree, err := New[int, int](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+	tree.Insert(1, 1)
+
+	require.PanicsWithError(t, collections.ErrNoAggregate.Error(), func() { tree.Aggregate(0, 1) }, "Tree should panic")
+	require.PanicsWithError(t, collections.ErrNoAggregate.Error(), func() { tree.Snapshot().Aggregate(0, 1) }, "Snapshot should panic")
+
+	_, err = New[int, int](3, DefaultTestPreAlloc, WithAggregate("", func(a string, b string) string { return a + b }))
+	require.Error(t, err, "Should refuse an aggregate of another type")
+	_, err = New[int, int](3, DefaultTestPreAlloc, WithAggregate[int](0, nil))
+	require.Error(t, err, "Should refuse an aggregate without a combine function")
+}
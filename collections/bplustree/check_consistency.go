@@ -5,94 +5,244 @@ import (
 	"os"
 )
 
+// InvariantError describes an invariant of the tree that does not hold, as found by
+// Validate.
+type InvariantError struct {
+	NodeID  int64  // Node the problem was found at, or zero if it concerns the whole tree
+	Tag     string // Short name for the kind of problem, used to annotate dumps
+	Message string // Description of the problem
+}
+
+// Error describes the problem, and where it was found
+func (e *InvariantError) Error() string {
+	if e.NodeID == 0 {
+		return e.Message
+	}
+
+	return fmt.Sprintf("NODE(%d) %s", e.NodeID, e.Message)
+}
+
 // CheckConsistency checks the consistency of the data-structure and ensures that there are
-// no obvious problems with it.
+// no obvious problems with it. Any problems are written out along with a dump of the tree,
+// before we panic.
 func (t *tree[K, V]) CheckConsistency() {
-	// Find the leftmost edge of the tree
-	var defKey K
-	node := t.findLeaf(defKey)
-	for node.PreviousSibling != nil {
-		node = node.PreviousSibling
+	t.lockExclusive()
+	defer t.lock.Unlock()
+
+	problems := t.validate()
+	if len(problems) == 0 {
+		if t.Root == nil {
+			fmt.Println("--- TREE EMPTY ---")
+		} else {
+			fmt.Println("--- TREE CONSISTENT ---")
+		}
+		return
 	}
 
-	levels := []*treeNode[K, V]{}
-	for node != nil {
-		levels = append(levels, node)
-		node = node.Parent
+	annotations := map[int64]string{}
+	for _, problem := range problems {
+		fmt.Printf("         %v\n", problem)
+		if invariant, ok := problem.(*InvariantError); ok {
+			annotations[invariant.NodeID] = invariant.Tag
+		}
 	}
-	for i, j := 0, len(levels)-1; i < j; i, j = i+1, j-1 {
-		levels[i], levels[j] = levels[j], levels[i]
+	if t.Root != nil {
+		for _, level := range (&validator[K, V]{t: t}).levels() {
+			for _, node := range level {
+				if tag, found := annotations[node.ID]; found {
+					node.Annotation = tag
+				}
+			}
+		}
 	}
 
-	for level, leftMost := range levels {
-		fmt.Printf("Consistency checking level %d: Leftmost NODE(%v)\n", level, leftMost.NodeID())
-		t.checkLevelConsistency(leftMost)
+	fmt.Println("==================== TREE DUMP =============== ")
+	t.Dump(os.Stderr)
+	panic("Inconsistent state")
+}
+
+// Validate checks every invariant of the tree, returning an *InvariantError for each
+// problem found. Writes are blocked while it is checked.
+func (t *tree[K, V]) Validate() []error {
+	t.lockQuiescent()
+	defer t.unlockQuiescent()
+
+	return t.validate()
+}
+
+// validator gathers the problems found while validating a tree
+type validator[K any, V any] struct {
+	t        *tree[K, V]
+	problems []error
+}
+
+// report adds a problem found at a node, or with the tree as a whole if it is nil
+func (v *validator[K, V]) report(node *treeNode[K, V], tag string, format string, args ...any) {
+	var nodeID int64
+	if node != nil {
+		nodeID = node.ID
 	}
 
-	fmt.Println("--- TREE CONSISTENT ---")
+	v.problems = append(v.problems, &InvariantError{
+		NodeID:  nodeID,
+		Tag:     tag,
+		Message: fmt.Sprintf(format, args...),
+	})
 }
 
-// checkLevelConsistency assumes that the node input is the first/leftmost edge of a tree level
-// and walks along the tree, ensuring values are correctly managed.
-func (t *tree[K, V]) checkLevelConsistency(node *treeNode[K, V]) {
-	if node == nil {
-		return
+// validate checks the invariants of the tree. The caller must hold the lock exclusively,
+// or through lockQuiescent.
+func (t *tree[K, V]) validate() []error {
+	v := &validator[K, V]{t: t}
+	length := t.length()
+	var leaves []*treeNode[K, V]
+
+	if t.Root == nil {
+		if length != 0 {
+			v.report(nil, "SIZE", "Tree is empty but claims %d records", length)
+		}
+	} else {
+		if t.Root.Parent != nil {
+			v.report(t.Root, "ROOT", "Root has parent %v", t.Root.Parent.NodeID())
+		}
+		if t.Root.subtreeSize() != length {
+			v.report(t.Root, "SIZE", "Root has size %d but the tree holds %d records", t.Root.subtreeSize(), length)
+		}
+
+		levels := v.levels()
+		for depth, level := range levels {
+			v.checkLevel(level, depth, len(levels)-1)
+		}
+		leaves = levels[len(levels)-1]
 	}
 
-	// Check all keys ordered
-	currentKey := node.Keys[0]
-	current := node
-	orderOK := true
-orderCheck:
-	for current != nil {
-		for i := 0; i < current.Count; i++ {
-			visitKey := current.Keys[i]
-			if visitKey < currentKey {
-				current.Annotation = "FAIL"
-				fmt.Printf("         NODE(%d) had a rewind. Key rewound from %v to %v!\n", current.ID, currentKey, visitKey)
-				orderOK = false
-				break orderCheck
+	v.checkIndex(leaves)
+	return v.problems
+}
+
+// levels gathers the nodes of each level of the tree by following the children down
+// from the root. A node reached twice is reported rather than followed again, so that
+// a damaged tree can't send us round in circles.
+func (v *validator[K, V]) levels() [][]*treeNode[K, V] {
+	seen := map[*treeNode[K, V]]bool{v.t.Root: true}
+	levels := [][]*treeNode[K, V]{{v.t.Root}}
+
+	for {
+		var next []*treeNode[K, V]
+		for _, node := range levels[len(levels)-1] {
+			if node.Leaf {
+				continue
+			}
+
+			for i, child := range node.Children[0:min(node.Count, len(node.Children))] {
+				if child == nil {
+					v.report(node, "CHILD", "Child %d is missing", i)
+					continue
+				} else if seen[child] {
+					v.report(node, "CHILD", "Child %d (%d) is reachable more than once", i, child.ID)
+					continue
+				}
+
+				seen[child] = true
+				next = append(next, child)
 			}
 		}
-		current = current.NextSibling
+
+		if len(next) == 0 {
+			return levels
+		}
+		levels = append(levels, next)
 	}
+}
+
+// checkLevel checks the nodes of a level, from left to right
+func (v *validator[K, V]) checkLevel(level []*treeNode[K, V], depth int, bottom int) {
+	t := v.t
 
-	// Check prev/next links
-	current = node
-	sequenceOK := true
-	for current != nil {
-		// Our predecessor should link together
-		if current.PreviousSibling != nil {
-			if current.PreviousSibling.NextSibling != current {
-				current.Annotation = "FAIL"
-				fmt.Printf("         NODE(%d) Our previous sibling (%d) next does not link next to us. Points to (%v)!\n", current.ID, current.PreviousSibling.ID, current.PreviousSibling.NextSibling.NodeID())
-				sequenceOK = false
+	if first := level[0]; first.PreviousSibling != nil {
+		v.report(first, "LINK", "First node of level %d links back to %v", depth, first.PreviousSibling.NodeID())
+	}
+	if last := level[len(level)-1]; last.NextSibling != nil {
+		v.report(last, "LINK", "Last node of level %d links on to %v", depth, last.NextSibling.NodeID())
+	}
+
+	var previousKey *K
+	for i, node := range level {
+		if node.Leaf != (depth == bottom) {
+			v.report(node, "DEPTH", "Leaf=%v at level %d, but the bottom level is %d", node.Leaf, depth, bottom)
+		}
+		if node.Count > t.Order {
+			v.report(node, "OVERFULL", "Has %d entries, above the order of %d", node.Count, t.Order)
+			continue
+		}
+
+		// Keys never rewind, even across nodes
+		for _, key := range node.Keys[0:node.Count] {
+			if previousKey != nil && t.compare(key, *previousKey) < 0 {
+				v.report(node, "ORDER", "Key rewound from %v to %v", *previousKey, key)
 			}
+			previousKey = &key
+		}
 
-			if current.PreviousSibling.Parent != nil {
-				childIndex := current.PreviousSibling.Parent.indexOf(current.PreviousSibling)
-				if childIndex < 0 {
-					current.PreviousSibling.Annotation = "ORPHAN"
-					fmt.Printf("         NODE(%d) Our previous sibling (%d) is not registered to its parent %v\n", current.ID, current.PreviousSibling.ID, current.PreviousSibling.Parent.NodeID())
-					sequenceOK = false
+		// Occupancy and family ties
+		if node != t.Root && node.Count < t.minimumCount() {
+			v.report(node, "UNDERFULL", "Has %d entries, below the minimum of %d", node.Count, t.minimumCount())
+		}
+		if node != t.Root && (node.Parent == nil || node.Parent.indexOf(node) < 0) {
+			v.report(node, "ORPHAN", "Is not registered to its parent %v", node.Parent.NodeID())
+		}
+		if contentSize := node.contentSize(); node.subtreeSize() != contentSize {
+			v.report(node, "SIZE", "Has size %d but its contents add up to %d", node.subtreeSize(), contentSize)
+		}
+
+		if !node.Leaf {
+			for c, child := range node.Children[0:node.Count] {
+				if child == nil {
+					continue
+				}
+				if child.Parent != node {
+					v.report(node, "FAIL", "Child %d (%d) points to parent %v", c, child.ID, child.Parent.NodeID())
+				}
+				if child.Count > 0 && t.compare(child.Keys[0], node.Keys[c]) != 0 {
+					v.report(node, "FAIL", "Key %d is %v but child (%d) leads with %v", c, node.Keys[c], child.ID, child.Keys[0])
 				}
 			}
 		}
 
-		if current.NextSibling != nil {
-			if current.NextSibling.PreviousSibling != current {
-				current.Annotation = "FAIL"
-				fmt.Printf("         NODE(%d) Our next sibling (%d) previous does not link next to us. Points to (%v)!\n", current.ID, current.NextSibling.ID, current.NextSibling.PreviousSibling.NodeID())
-				sequenceOK = false
+		// Siblings link together in both directions
+		if i+1 < len(level) {
+			next := level[i+1]
+			if node.NextSibling != next {
+				v.report(node, "LINK", "Next sibling is %v, expected %d", node.NextSibling.NodeID(), next.ID)
+			}
+			if next.PreviousSibling != node {
+				v.report(next, "LINK", "Previous sibling is %v, expected %d", next.PreviousSibling.NodeID(), node.ID)
 			}
 		}
+	}
+}
 
-		current = current.NextSibling
+// checkIndex ensures that the record index holds exactly the records of the leaves, each
+// against the key it is stored under.
+func (v *validator[K, V]) checkIndex(leaves []*treeNode[K, V]) {
+	t := v.t
+	t.indexLock.Lock()
+	defer t.indexLock.Unlock()
+	t.settleIndex()
+
+	if length := t.length(); len(t.index) != length {
+		v.report(nil, "INDEX", "Index holds %d records but the tree holds %d", len(t.index), length)
 	}
 
-	if !orderOK || !sequenceOK {
-		fmt.Println("==================== TREE DUMP =============== ")
-		t.Dump(os.Stderr)
-		panic("Inconsistent state")
+	for _, leaf := range leaves {
+		if !leaf.Leaf {
+			continue
+		}
+
+		for i, rec := range leaf.Records[0:leaf.Count] {
+			if key, found := t.index[rec.RecordID]; !found || t.compare(key, leaf.Keys[i]) != 0 {
+				v.report(leaf, "INDEX", "Record %d is stored against %v but indexed as %v", rec.RecordID, leaf.Keys[i], key)
+			}
+		}
 	}
 }
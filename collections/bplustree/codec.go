@@ -0,0 +1,76 @@
+package bplustree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+// Codec converts keys or values to and from bytes, so that a tree can be written out
+// with WriteTo and read back with Load.
+type Codec[T any] interface {
+	// Encode a value to bytes
+	Encode(value T) ([]byte, error)
+
+	// Decode a value from the bytes produced by Encode
+	Decode(data []byte) (T, error)
+}
+
+// StringCodec encodes strings as their raw bytes
+type StringCodec struct{}
+
+// Encode a string
+func (StringCodec) Encode(value string) ([]byte, error) {
+	return []byte(value), nil
+}
+
+// Decode a string
+func (StringCodec) Decode(data []byte) (string, error) {
+	return string(data), nil
+}
+
+// BinaryCodec encodes fixed-size values, such as sized integers, floats and structs of
+// them, using encoding/binary in little-endian order. Types without a fixed size, such
+// as int or string, are rejected when encoding.
+type BinaryCodec[T any] struct{}
+
+// Encode a fixed-size value
+func (BinaryCodec[T]) Encode(value T) ([]byte, error) {
+	size := binary.Size(value)
+	if size < 0 {
+		return nil, fmt.Errorf("type %T does not have a fixed size", value)
+	}
+
+	return binary.Append(make([]byte, 0, size), binary.LittleEndian, value)
+}
+
+// Decode a fixed-size value
+func (BinaryCodec[T]) Decode(data []byte) (T, error) {
+	var result T
+	size := binary.Size(result)
+	if size < 0 {
+		return result, fmt.Errorf("type %T does not have a fixed size", result)
+	} else if len(data) != size {
+		return result, fmt.Errorf("expected %d bytes for %T, got %d", size, result, len(data))
+	}
+
+	err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &result)
+	return result, err
+}
+
+// JSONCodec encodes values as JSON, which suits any type encoding/json can handle at
+// the cost of space.
+type JSONCodec[T any] struct{}
+
+// Encode a value as JSON
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode a value from JSON
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var result T
+	err := json.Unmarshal(data, &result)
+	return result, err
+}
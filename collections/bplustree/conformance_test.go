@@ -0,0 +1,41 @@
+package bplustree
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/collectionstest"
+)
+
+// TestConformance checks the tree honours the TreeMap[K, V] contract, with and without
+// its optional behaviours. Small orders give trees deep enough to split and merge
+// nodes at every level.
+func TestConformance(t *testing.T) {
+	for _, order := range []int{4, 27} {
+		for _, unique := range []bool{false, true} {
+			for _, summed := range []bool{false, true} {
+				t.Run(fmt.Sprintf("Order=%d_UniqueKeys=%v_Aggregate=%v", order, unique, summed), func(t *testing.T) {
+					var opts []Option
+					var suiteOpts []collectionstest.Option
+					if unique {
+						opts = append(opts, WithUniqueKeys())
+						suiteOpts = append(suiteOpts, collectionstest.WithUniqueKeys())
+					}
+					if summed {
+						opts = append(opts, WithAggregate(0, func(a int, b int) int { return a + b }))
+						suiteOpts = append(suiteOpts, collectionstest.WithSummedAggregate())
+					}
+
+					collectionstest.RunTreeMapSuite(t, func() collections.TreeMap[int, int] {
+						tree, err := New[int, int](order, DefaultTestPreAlloc, opts...)
+						if err != nil {
+							t.Fatalf("Error: %v", err)
+						}
+						return tree
+					}, suiteOpts...)
+				})
+			}
+		}
+	}
+}
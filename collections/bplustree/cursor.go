@@ -0,0 +1,264 @@
+package bplustree
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// cursorState describes where a cursor sits relative to the records of the tree
+type cursorState int
+
+const (
+	cursorSeeking     cursorState = iota // Just before the seek position, no current record
+	cursorOnRecord                       // On a record
+	cursorBeforeStart                    // Moved off the front of the tree
+	cursorAfterEnd                       // Moved off the back of the tree
+	cursorClosed                         // Closed by the consumer
+)
+
+// cursor is a movable position within the tree. Rather than holding the read lock for
+// its lifetime, which would block writers for as long as a consumer keeps the cursor
+// open, it takes the lock for each move, along with a shared latch on the leaf it is
+// moving within. Every leaf carries a version that changes
+// whenever its slots do, so if a write touches the leaf the cursor sits in - such as
+// an insert that splits it - the next move fails with ErrCursorInvalidated. Seek again
+// from the last key seen to carry on, or seek on a snapshot for the cursor's snapshot
+// mode, where nothing is invalidated.
+type cursor[K any, V any] struct {
+	tree    *tree[K, V]
+	state   cursorState
+	leaf    *treeNode[K, V] // Leaf of the current record or seek position, nil for the end
+	index   int             // Slot within the leaf
+	version uint64          // Version of the leaf when we positioned on it
+	err     error
+
+	// The current record, copied when we moved onto it
+	key      K
+	value    V
+	recordID collections.RecordID
+	resets   uint64 // Resets of the tree when we were created
+}
+
+// Seek creates a cursor positioned just before the first record with a key greater
+// than or equal to the key. The position is taken within the leaf the key belongs in,
+// which may be just past its last slot, so that records added to it after we sought
+// invalidate the cursor like any other change to its leaf. Looking further afield
+// would let go of the leaf, and concurrent inserts could slip in behind us.
+func (t *tree[K, V]) Seek(key K) collections.Cursor[K, V] {
+	v := t.lockRead()
+	p := v.searchLeaf(key, true)
+
+	c := &cursor[K, V]{
+		tree:   t,
+		state:  cursorSeeking,
+		resets: t.resets,
+	}
+	c.leaf, c.index = p.leaf()
+	if c.leaf != nil {
+		c.version = c.leaf.Version
+	}
+
+	p.clear()
+	t.unlockRead()
+	return c
+}
+
+// Next moves to the following record
+func (c *cursor[K, V]) Next() bool {
+	return c.move(func() (*treeNode[K, V], int) {
+		switch c.state {
+		case cursorSeeking:
+			if c.leaf != nil && c.index == c.leaf.Count {
+				return latchedNext(c.leaf, c.index-1)
+			}
+			return c.leaf, c.index
+		case cursorOnRecord:
+			return latchedNext(c.leaf, c.index)
+		case cursorBeforeStart:
+			return c.tree.latchedEdge(false), 0
+		default:
+			return nil, 0
+		}
+	}, cursorAfterEnd)
+}
+
+// Prev moves to the preceding record
+func (c *cursor[K, V]) Prev() bool {
+	return c.move(func() (*treeNode[K, V], int) {
+		switch c.state {
+		case cursorSeeking:
+			if c.leaf == nil {
+				return nil, 0 // The tree was empty when we sought
+			}
+			return latchedPrevious(c.leaf, c.index)
+		case cursorOnRecord:
+			return latchedPrevious(c.leaf, c.index)
+		case cursorAfterEnd:
+			return c.tree.latchedLast()
+		default:
+			return nil, 0
+		}
+	}, cursorBeforeStart)
+}
+
+// Key of the current record
+func (c *cursor[K, V]) Key() K {
+	return c.key
+}
+
+// Value of the current record
+func (c *cursor[K, V]) Value() V {
+	return c.value
+}
+
+// RecordID of the current record
+func (c *cursor[K, V]) RecordID() collections.RecordID {
+	return c.recordID
+}
+
+// Err gets the reason the cursor stopped being usable
+func (c *cursor[K, V]) Err() error {
+	return c.err
+}
+
+// Close the cursor. Any further moves will fail.
+func (c *cursor[K, V]) Close() {
+	c.state = cursorClosed
+	c.leaf = nil
+	c.clearRecord()
+}
+
+// move validates our position, then moves to the slot picked by target. Target is
+// called with our leaf latched, and returns its slot with the leaf latched, letting go
+// of ours if it moved off it. If there is no such slot, we park in the overflow state.
+func (c *cursor[K, V]) move(target func() (*treeNode[K, V], int), overflow cursorState) bool {
+	if c.state == cursorClosed || c.err != nil {
+		return false
+	}
+
+	c.tree.lock.RLock()
+	defer c.tree.lock.RUnlock()
+
+	// If the tree handed its nodes on, our leaf may now belong to another tree
+	if c.tree.resets != c.resets {
+		c.invalidate()
+		return false
+	}
+
+	if c.leaf != nil {
+		c.leaf.latch.RLock()
+		if c.leaf.Version != c.version {
+			c.leaf.latch.RUnlock()
+			c.invalidate()
+			return false
+		}
+	}
+
+	leaf, index := target()
+	if leaf == nil {
+		c.state = overflow
+		c.leaf = nil
+		c.clearRecord()
+		return false
+	}
+
+	c.state = cursorOnRecord
+	c.leaf = leaf
+	c.index = index
+	c.version = leaf.Version
+	c.key = leaf.Keys[index]
+	c.value = leaf.Records[index].Value
+	c.recordID = leaf.Records[index].RecordID
+	leaf.latch.RUnlock()
+	return true
+}
+
+// latchedNext gets the slot after one in a latched leaf. Moving to the next leaf, we
+// latch it before letting go of ours, which keeps to the left to right order.
+func latchedNext[K any, V any](leaf *treeNode[K, V], index int) (*treeNode[K, V], int) {
+	if index+1 < leaf.Count {
+		return leaf, index + 1
+	}
+
+	next := leaf.NextSibling
+	if next != nil {
+		next.latch.RLock()
+	}
+	leaf.latch.RUnlock()
+
+	return next, 0
+}
+
+// latchedPrevious gets the slot before one in a latched leaf. The previous leaf can't
+// be latched while we hold ours, so we let go first. It may split in the meantime, in
+// which case we walk right until we find the leaf just before ours. Ours can't go away
+// meanwhile, as that takes the tree lock exclusively.
+func latchedPrevious[K any, V any](leaf *treeNode[K, V], index int) (*treeNode[K, V], int) {
+	if index > 0 {
+		return leaf, index - 1
+	}
+
+	previous := leaf.PreviousSibling
+	leaf.latch.RUnlock()
+	if previous == nil {
+		return nil, 0
+	}
+
+	previous.latch.RLock()
+	for previous.NextSibling != leaf {
+		next := previous.NextSibling
+		next.latch.RLock()
+		previous.latch.RUnlock()
+		previous = next
+	}
+
+	return previous, previous.Count - 1
+}
+
+// latchedLast gets the last slot of the tree, with its leaf latched
+func (t *tree[K, V]) latchedLast() (*treeNode[K, V], int) {
+	last := t.latchedEdge(true)
+	if last == nil {
+		return nil, 0
+	}
+
+	return last, last.Count - 1
+}
+
+// latchedEdge latches its way down to the first or last leaf, returning it latched
+func (t *tree[K, V]) latchedEdge(last bool) *treeNode[K, V] {
+	t.rootLatch.RLock()
+	current := t.Root
+	if current == nil {
+		t.rootLatch.RUnlock()
+		return nil
+	}
+	current.latch.RLock()
+	t.rootLatch.RUnlock()
+
+	for !current.Leaf {
+		child := current.Children[0]
+		if last {
+			child = current.Children[current.Count-1]
+		}
+
+		child.latch.RLock()
+		current.latch.RUnlock()
+		current = child
+	}
+
+	return current
+}
+
+// invalidate stops the cursor, as the tree changed beneath it
+func (c *cursor[K, V]) invalidate() {
+	c.err = collections.ErrCursorInvalidated
+	c.leaf = nil
+	c.clearRecord()
+}
+
+// clearRecord drops the copy of the current record
+func (c *cursor[K, V]) clearRecord() {
+	var blankKey K
+	var blankValue V
+	c.key = blankKey
+	c.value = blankValue
+	c.recordID = 0
+}
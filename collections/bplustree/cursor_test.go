@@ -0,0 +1,167 @@
+package bplustree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestCursorWalk moves a cursor both ways across leaf boundaries and off both ends
+func TestCursorWalk(t *testing.T) {
+	tree, err := New[int, int](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	ids := map[int]collections.RecordID{}
+	for i := 0; i < 50; i++ {
+		ids[i*2] = tree.Insert(i*2, i*20)
+	}
+
+	// Forward from a key that doesn't exist
+	c := tree.Seek(21)
+	for expected := 22; expected < 100; expected += 2 {
+		require.True(t, c.Next(), "Should move onto %d", expected)
+		require.Equal(t, expected, c.Key(), "Should have the right key")
+		require.Equal(t, expected*10, c.Value(), "Should have the right value")
+		require.Equal(t, ids[expected], c.RecordID(), "Should have the right record ID")
+	}
+	require.False(t, c.Next(), "Should run off the end")
+	require.False(t, c.Next(), "Should stay off the end")
+	require.NoError(t, c.Err(), "Running off the end isn't an error")
+
+	// And back again, off the front
+	for expected := 98; expected >= 0; expected -= 2 {
+		require.True(t, c.Prev(), "Should move back onto %d", expected)
+		require.Equal(t, expected, c.Key(), "Should have the right key")
+	}
+	require.False(t, c.Prev(), "Should run off the front")
+	require.True(t, c.Next(), "Should come back onto the first record")
+	require.Equal(t, 0, c.Key(), "Should be on the first record")
+	c.Close()
+
+	require.False(t, c.Next(), "Should not move once closed")
+	require.False(t, c.Prev(), "Should not move once closed")
+}
+
+// TestCursorSeekPositions checks where Seek leaves the cursor
+func TestCursorSeekPositions(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	c := tree.Seek(1)
+	require.False(t, c.Next(), "Should not move in an empty tree")
+	require.False(t, c.Prev(), "Should not move in an empty tree")
+
+	// Records added after seeking an empty tree come after the seek position
+	c = tree.Seek(1)
+	tree.Insert(0, 0)
+	require.False(t, c.Prev(), "Should not find records added after seeking")
+
+	for i := 1; i < 10; i++ {
+		tree.Insert(i, i)
+	}
+
+	c = tree.Seek(5)
+	require.True(t, c.Prev(), "Should move before the seek position")
+	require.Equal(t, 4, c.Key(), "Should land on the preceding key")
+	require.True(t, c.Next(), "Should move forward again")
+	require.Equal(t, 5, c.Key(), "Should land on the seek key")
+
+	c = tree.Seek(100)
+	require.False(t, c.Next(), "Nothing should follow the end")
+	c = tree.Seek(100)
+	require.True(t, c.Prev(), "Should move back from past the end")
+	require.Equal(t, 9, c.Key(), "Should land on the last key")
+
+	// A record added after the end lands in the leaf we sit past
+	c = tree.Seek(100)
+	tree.Insert(200, 200)
+	require.False(t, c.Prev(), "Should not move once the last leaf changed")
+	require.ErrorIs(t, c.Err(), collections.ErrCursorInvalidated, "Should report invalidation")
+	tree.Delete(200)
+
+	c = tree.Seek(-100)
+	require.False(t, c.Prev(), "Nothing should precede the start")
+	c = tree.Seek(-100)
+	require.True(t, c.Next(), "Should move onto the first record")
+	require.Equal(t, 0, c.Key(), "Should land on the first key")
+}
+
+// TestCursorInvalidatedBySplit checks we report when a write splits our leaf
+func TestCursorInvalidatedBySplit(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i += 10 {
+		tree.Insert(i, i)
+	}
+
+	c := tree.Seek(50)
+	require.True(t, c.Next(), "Should move onto a record")
+
+	// Fill the neighbourhood until the leaf must split
+	for i := 51; i < 60; i++ {
+		tree.Insert(i, i)
+	}
+
+	require.False(t, c.Next(), "Should not move after the leaf changed")
+	require.ErrorIs(t, c.Err(), collections.ErrCursorInvalidated, "Should report invalidation")
+	require.False(t, c.Prev(), "Should stay invalidated")
+	require.Zero(t, c.Key(), "Should not hold a current record")
+}
+
+// TestCursorUnaffectedElsewhere checks writes to other leaves leave the cursor alone
+func TestCursorUnaffectedElsewhere(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, i)
+	}
+
+	c := tree.Seek(0)
+	require.True(t, c.Next(), "Should move onto a record")
+
+	for i := 1000; i < 1100; i++ {
+		tree.Insert(i, i)
+	}
+	tree.Delete(99)
+
+	require.True(t, c.Next(), "Should keep moving")
+	require.Equal(t, 1, c.Key(), "Should be on the next key")
+	require.NoError(t, c.Err(), "Should not be invalidated")
+}
+
+// TestCursorSnapshotMode checks a cursor sought on a snapshot of the live tree keeps
+// walking the records it was sought among, however the tree is written to
+func TestCursorSnapshotMode(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i += 10 {
+		tree.Insert(i, i)
+	}
+
+	live := tree.Seek(50)
+	c := tree.Snapshot().Seek(50)
+	require.True(t, live.Next(), "Should move onto a record")
+	require.True(t, c.Next(), "Should move onto a record")
+
+	// Split the leaf both cursors sit in, and remove what follows
+	for i := 51; i < 60; i++ {
+		tree.Insert(i, i)
+	}
+	tree.Delete(60)
+
+	require.False(t, live.Next(), "Should not move the live cursor after the leaf changed")
+	require.ErrorIs(t, live.Err(), collections.ErrCursorInvalidated, "Should invalidate the live cursor")
+
+	for _, expected := range []int{60, 70, 80, 90} {
+		require.True(t, c.Next(), "Should move onto %d", expected)
+		require.Equal(t, expected, c.Key(), "Should walk the records as they were sought")
+	}
+	require.False(t, c.Next(), "Should run off the end")
+	require.NoError(t, c.Err(), "Should never invalidate a snapshot cursor")
+	c.Close()
+}
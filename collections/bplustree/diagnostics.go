@@ -0,0 +1,111 @@
+package bplustree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// Stats gathers statistics about the shape of the tree. Writes are blocked while they
+// are gathered.
+func (t *tree[K, V]) Stats() collections.Stats {
+	t.lockQuiescent()
+	defer t.unlockQuiescent()
+
+	result := collections.Stats{
+		PoolHits:   t.poolHits,
+		PoolMisses: t.poolMisses,
+		Records:    t.length(),
+	}
+
+	for _, level := range t.levels() {
+		result.Height++
+		result.NodesPerLevel = append(result.NodesPerLevel, len(level))
+		for _, node := range level {
+			bucket := min(node.Count*len(result.FillHistogram)/t.Order, len(result.FillHistogram)-1)
+			result.FillHistogram[bucket]++
+		}
+	}
+
+	return result
+}
+
+// DumpDOT writes the tree out as a Graphviz DOT graph, with the links between leaves
+// drawn dashed. Writes are blocked while it is written.
+func (t *tree[K, V]) DumpDOT(f io.Writer) error {
+	t.lockQuiescent()
+	defer t.unlockQuiescent()
+
+	// A buffered writer holds on to the first error, so we only check once we're done
+	w := bufio.NewWriter(f)
+	_, _ = fmt.Fprintf(w, "digraph bplustree {\n\tnode [shape=record];\n")
+	if levels := t.levels(); len(levels) > 0 {
+		t.Root.DumpDOT(w)
+		for _, leaf := range levels[len(levels)-1] {
+			if leaf.NextSibling != nil {
+				_, _ = fmt.Fprintf(w, "\tnode%d -> node%d [style=dashed, constraint=false];\n", leaf.ID, leaf.NextSibling.ID)
+			}
+		}
+	}
+	_, _ = fmt.Fprintln(w, "}")
+
+	return w.Flush()
+}
+
+// DumpJSON writes the tree out as JSON, with the nodes nested beneath the root. Writes
+// are blocked while it is written.
+func (t *tree[K, V]) DumpJSON(f io.Writer) error {
+	t.lockQuiescent()
+	defer t.unlockQuiescent()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(t)
+}
+
+// jsonTree is the form the tree takes when written as JSON
+type jsonTree[K any, V any] struct {
+	NodeCount   int64                `json:"node_count"`
+	RecordCount collections.RecordID `json:"record_count"`
+	Length      int                  `json:"length"`
+	Generation  uint64               `json:"generation"`
+	Order       int                  `json:"order"`
+	UniqueKeys  bool                 `json:"unique_keys"`
+	Root        *treeNode[K, V]      `json:"root"`
+}
+
+// MarshalJSON writes the tree as JSON, counting the records of concurrent inserts that
+// are yet to be folded into the length.
+func (t *tree[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTree[K, V]{
+		NodeCount:   t.NodeCount,
+		RecordCount: t.RecordCount,
+		Length:      t.length(),
+		Generation:  t.Generation,
+		Order:       t.Order,
+		UniqueKeys:  t.UniqueKeys,
+		Root:        t.Root,
+	})
+}
+
+// levels gets the nodes of each level of the tree, from the root down. The caller must
+// hold the lock exclusively, or through lockQuiescent.
+func (t *tree[K, V]) levels() [][]*treeNode[K, V] {
+	var levels [][]*treeNode[K, V]
+	for level := []*treeNode[K, V]{t.Root}; t.Root != nil && len(level) > 0; {
+		levels = append(levels, level)
+
+		var next []*treeNode[K, V]
+		for _, node := range level {
+			if !node.Leaf {
+				next = append(next, node.Children[0:node.Count]...)
+			}
+		}
+		level = next
+	}
+
+	return levels
+}
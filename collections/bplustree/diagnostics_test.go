@@ -0,0 +1,144 @@
+package bplustree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// buildFull bulk loads a tree of order 4 with full nodes: four leaves under a root
+func buildFull(t *testing.T) *tree[int, int] {
+	var input []generics.KeyValuePair[int, int]
+	for i := 0; i < 16; i++ {
+		input = append(input, generics.KeyValuePair[int, int]{Key: i, Value: i})
+	}
+
+	built, err := BuildSorted(4, DefaultTestPreAlloc, 1, slices.Values(input))
+	require.NoError(t, err, "Should build the tree")
+	return built.(*tree[int, int])
+}
+
+// TestValidate damages a tree in several ways at once, and checks every problem is
+// reported against the right node.
+func TestValidate(t *testing.T) {
+	tree := buildFull(t)
+	require.Empty(t, tree.Validate(), "Should find nothing wrong with a new tree")
+
+	empty, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+	require.Empty(t, empty.(collections.Diagnosable).Validate(), "Should find nothing wrong with an empty tree")
+
+	leaves := tree.Root.Children
+	leaves[1].setSize(3)
+	leaves[2].Keys[0], leaves[2].Keys[1] = leaves[2].Keys[1], leaves[2].Keys[0]
+	leaves[3].PreviousSibling = nil
+
+	tags := map[string]int64{}
+	for _, problem := range tree.Validate() {
+		var invariant *InvariantError
+		require.True(t, errors.As(problem, &invariant), "Should describe the invariant")
+		tags[invariant.Tag] = invariant.NodeID
+		require.Contains(t, problem.Error(), "NODE(", "Should name the node")
+	}
+	require.Equal(t, leaves[1].ID, tags["SIZE"], "Should report the wrong size")
+	require.Equal(t, leaves[2].ID, tags["ORDER"], "Should report the keys out of order")
+	require.Equal(t, leaves[3].ID, tags["LINK"], "Should report the broken link")
+
+	require.PanicsWithValue(t, "Inconsistent state", func() {
+		tree.CheckConsistency()
+	}, "CheckConsistency should still panic")
+	require.Equal(t, "SIZE", leaves[1].Annotation, "CheckConsistency should annotate the dump")
+}
+
+// TestStats checks the statistics of a tree of a known shape
+func TestStats(t *testing.T) {
+	tree := buildFull(t)
+
+	stats := tree.Stats()
+	require.Equal(t, 2, stats.Height, "Should have a root and leaves")
+	require.Equal(t, []int{1, 4}, stats.NodesPerLevel, "Should count the nodes at each level")
+	require.Equal(t, [10]int{9: 5}, stats.FillHistogram, "Every node should be full")
+	require.Equal(t, 16, stats.Records, "Should count the records")
+	require.Equal(t, int64(3), stats.PoolMisses, "Should miss once for each kind of slot set")
+	require.Equal(t, int64(7), stats.PoolHits, "Should take the rest from the pools")
+
+	tree.Delete(0)
+	tree.Delete(1)
+	stats = tree.Stats()
+	require.Equal(t, 14, stats.Records, "Should count the remaining records")
+	require.Equal(t, []int{1, 4}, stats.NodesPerLevel, "Should keep the same nodes")
+	require.Equal(t, [10]int{5: 1, 9: 4}, stats.FillHistogram, "Should have a half full leaf")
+
+	empty, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+	require.Equal(t, collections.Stats{}, empty.(collections.Diagnosable).Stats(), "Empty tree should have no shape")
+}
+
+// TestDumpDOT checks the graph has every node, child edge and leaf link
+func TestDumpDOT(t *testing.T) {
+	tree := buildFull(t)
+
+	var buffer bytes.Buffer
+	require.NoError(t, tree.DumpDOT(&buffer), "Should write the graph")
+	graph := buffer.String()
+
+	require.True(t, strings.HasPrefix(graph, "digraph bplustree {"), "Should be a digraph")
+	require.True(t, strings.HasSuffix(graph, "}\n"), "Should close the graph")
+	require.Equal(t, 5, strings.Count(graph, "[label="), "Should declare every node")
+	require.Equal(t, 3, strings.Count(graph, "style=dashed"), "Should link every leaf but the last")
+	for i, child := range tree.Root.Children[0:tree.Root.Count] {
+		edge := fmt.Sprintf("node%d:s%d -> node%d;", tree.Root.ID, i, child.ID)
+		require.Contains(t, graph, edge, "Should link the root to child %d", i)
+	}
+	require.Contains(t, graph, fmt.Sprintf("node%d [label=\"NODE(%d)|0 #1|1 #2|2 #3|3 #4\"];", tree.Root.Children[0].ID, tree.Root.Children[0].ID), "Should label the leaf with its records")
+
+	require.Equal(t, `a\|b \<c\> \{d\} \"e\"`, dotEscape(`a|b <c> {d} "e"`), "Should escape label characters")
+}
+
+// TestDumpJSON reads the dump back, checking only the occupied slots were written
+func TestDumpJSON(t *testing.T) {
+	tree := buildFull(t)
+	tree.Delete(15)
+
+	var buffer bytes.Buffer
+	require.NoError(t, tree.DumpJSON(&buffer), "Should write the tree")
+
+	var dump struct {
+		Length int `json:"length"`
+		Order  int `json:"order"`
+		Root   struct {
+			Count    int   `json:"count"`
+			Keys     []int `json:"key"`
+			Children []struct {
+				Leaf    bool  `json:"leaf"`
+				Keys    []int `json:"key"`
+				Records []struct {
+					RecordID collections.RecordID `json:"rid"`
+					Value    int                  `json:"value"`
+				} `json:"records"`
+			} `json:"children"`
+		} `json:"root"`
+	}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &dump), "Should read the dump back")
+
+	require.Equal(t, 15, dump.Length, "Should have the record count")
+	require.Equal(t, 4, dump.Order, "Should have the order")
+	require.Equal(t, []int{0, 4, 8, 12}, dump.Root.Keys, "Should have the root keys")
+	require.Len(t, dump.Root.Children, 4, "Should nest the leaves beneath the root")
+
+	last := dump.Root.Children[3]
+	require.True(t, last.Leaf, "Should mark the leaves")
+	require.Equal(t, []int{12, 13, 14}, last.Keys, "Should leave out the unused slots")
+	require.Len(t, last.Records, 3, "Should leave out the unused records")
+	require.Equal(t, collections.RecordID(15), last.Records[2].RecordID, "Should have the record IDs")
+	require.Equal(t, 14, last.Records[2].Value, "Should have the values")
+}
@@ -0,0 +1,369 @@
+package bplustree
+
+import (
+	"sync/atomic"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// Locking
+//
+// The tree lock is taken in one of two modes. Inserts and reads take it shared, and
+// then coordinate amongst themselves using a latch on each node, plus one over the
+// root pointer. Reads that take in the whole tree at once, such as Stats, Validate and
+// WriteTo, also take it shared, but close the insert gate behind them, so they see the
+// tree stand still without holding up other reads. Everything else - deletes, updates,
+// snapshots and the like - takes it exclusively, and has the whole tree to itself.
+//
+// Latches are always taken from the root down, and left to right within a level, so
+// nobody ever waits on a latch above one they hold. Inserts crab their way down: the
+// common case holds shared latches on the path and an exclusive latch only on the
+// leaf, which is enough if the leaf has room. Failing that we retry with exclusive
+// latches all the way down, letting go of the ancestors as soon as we reach a node
+// with room for one more slot, as a split below can't spread any higher than it.
+//
+// Inserts keep the subtree sizes exact as they go, counting themselves in the size of
+// every node above their record. Under the shared lock sizes are written atomically, so
+// inserts sharing an ancestor can count themselves in it together, and readers can read
+// them. A node is always counted before its parent, so the sizes a reader sees beneath
+// a node never fall short of the size it saw for the node. Only the length of the tree
+// is left for later: inserts count themselves in pendingLength, which the next
+// exclusive lock folds in.
+//
+// None of this is needed by a writer with the tree to itself, which goes about its
+// business as if there were no latches at all. Inserts take that route whenever nobody
+// else is about, so a lone writer pays nothing for the company it doesn't have.
+
+// concurrentOutcome is the result of an attempt to insert under the shared lock
+type concurrentOutcome int
+
+const (
+	concurrentDone      concurrentOutcome = iota // Record stored
+	concurrentRetry                              // Try again with exclusive latches
+	concurrentExclusive                          // Needs the tree lock exclusively
+)
+
+// lockExclusive takes the tree lock exclusively, folding in the records added by
+// concurrent inserts.
+func (t *tree[K, V]) lockExclusive() {
+	t.lock.Lock()
+	t.foldPendingLength()
+}
+
+// tryLockExclusive takes the tree lock exclusively as lockExclusive does, but only if
+// nobody else holds it. Returns false if it couldn't.
+func (t *tree[K, V]) tryLockExclusive() bool {
+	if !t.lock.TryLock() {
+		return false
+	}
+	t.foldPendingLength()
+
+	return true
+}
+
+// foldPendingLength adds the records counted by concurrent inserts to the length. The
+// caller must hold the lock exclusively.
+func (t *tree[K, V]) foldPendingLength() {
+	if t.pendingLength.Load() != 0 {
+		t.Length += int(t.pendingLength.Swap(0))
+	}
+}
+
+// lockQuiescent takes the tree lock shared, then waits for concurrent inserts to finish
+// and holds off any more, returning an unlatched view of the tree. Release with
+// unlockQuiescent.
+func (t *tree[K, V]) lockQuiescent() view[K, V] {
+	t.lock.RLock()
+	t.insertGate.Lock()
+
+	return t.readView()
+}
+
+// unlockQuiescent releases what was taken by lockQuiescent
+func (t *tree[K, V]) unlockQuiescent() {
+	t.insertGate.Unlock()
+	t.lock.RUnlock()
+}
+
+// length gets the number of records in the tree, including those added by concurrent
+// inserts.
+func (t *tree[K, V]) length() int {
+	return t.Length + int(t.pendingLength.Load())
+}
+
+// lockRead takes the tree lock shared, returning a latched view of the live tree.
+// Release with unlockRead.
+func (t *tree[K, V]) lockRead() view[K, V] {
+	t.lock.RLock()
+	t.rootLatch.RLock()
+
+	return view[K, V]{
+		root:       t.Root,
+		length:     t.length(),
+		compare:    t.compare,
+		aggregates: t.aggregates,
+		latched:    true,
+	}
+}
+
+// unlockRead releases what was taken by lockRead
+func (t *tree[K, V]) unlockRead() {
+	t.rootLatch.RUnlock()
+	t.lock.RUnlock()
+}
+
+// insertConcurrent tries to insert a record under the shared lock. Returns false if
+// the insert needs the tree to itself, such as when the tree is empty, the key would
+// become the smallest in its leaf, or the path is frozen by a snapshot. Trees keeping
+// aggregates always need it, as they maintain them through the parent links.
+func (t *tree[K, V]) insertConcurrent(key K, value V) (collections.RecordID, bool) {
+	if t.aggregates != nil {
+		return 0, false
+	}
+
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	t.insertGate.RLock()
+	defer t.insertGate.RUnlock()
+
+	recordID, outcome := t.insertOptimistic(key, value)
+	if outcome == concurrentRetry {
+		recordID, outcome = t.insertPessimistic(key, value)
+	}
+
+	return recordID, outcome == concurrentDone
+}
+
+// insertOptimistic inserts holding shared latches down to the leaf, and an exclusive
+// latch on the leaf itself. If the leaf is full we ask to be retried.
+func (t *tree[K, V]) insertOptimistic(key K, value V) (collections.RecordID, concurrentOutcome) {
+	var shared []*treeNode[K, V]
+	var leaf *treeNode[K, V]
+
+	t.rootLatch.RLock()
+	defer func() {
+		if leaf != nil {
+			leaf.latch.Unlock()
+		}
+		for i := len(shared) - 1; i >= 0; i-- {
+			shared[i].latch.RUnlock()
+		}
+		t.rootLatch.RUnlock()
+	}()
+
+	current := t.Root
+	if current == nil {
+		return 0, concurrentExclusive
+	}
+
+	for {
+		// Whether a node is a leaf never changes, so it's safe to check before latching
+		if current.Leaf {
+			current.latch.Lock()
+			leaf = current
+		} else {
+			current.latch.RLock()
+			shared = append(shared, current)
+		}
+
+		if t.frozen(current) {
+			return 0, concurrentExclusive
+		}
+		if current.Leaf {
+			break
+		}
+		current = current.Children[current.childIndex(key, t.compare)]
+	}
+
+	targetIndex := leaf.getInsertIndex(key, t.compare)
+	if recordID, replaced := t.replaceConcurrent(leaf, targetIndex, key, value); replaced {
+		return recordID, concurrentDone
+	}
+	if targetIndex == 0 {
+		return 0, concurrentExclusive
+	}
+	if leaf.Count == t.Order {
+		return 0, concurrentRetry
+	}
+
+	recordID := t.storeConcurrent(leaf, targetIndex, key, value)
+	for i := len(shared) - 1; i >= 0; i-- {
+		shared[i].addSizeConcurrent(1)
+	}
+	t.pendingLength.Add(1)
+
+	return recordID, concurrentDone
+}
+
+// insertPessimistic inserts holding exclusive latches on every node a split could
+// reach, splitting from the leaf upwards if it is full. The whole path is held until
+// the leaf shows the insert will go ahead. If it must split, we count the record in
+// the sizes of the nodes down to the last with room for another slot, which no split
+// can spread beyond, and let go of those above it.
+func (t *tree[K, V]) insertPessimistic(key K, value V) (collections.RecordID, concurrentOutcome) {
+	var held []*treeNode[K, V] // Exclusively latched, from the highest that may change down
+	var created []*treeNode[K, V]
+	holdsRoot := true
+
+	t.rootLatch.Lock()
+	defer func() {
+		for _, node := range created {
+			node.latch.Unlock()
+		}
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].latch.Unlock()
+		}
+		if holdsRoot {
+			t.rootLatch.Unlock()
+		}
+	}()
+
+	current := t.Root
+	if current == nil {
+		return 0, concurrentExclusive
+	}
+
+	top := -1 // Last node of the path with room for another slot
+	for {
+		current.latch.Lock()
+		held = append(held, current)
+		if t.frozen(current) {
+			return 0, concurrentExclusive
+		}
+		if current.Count < t.Order {
+			top = len(held) - 1
+		}
+
+		if current.Leaf {
+			break
+		}
+		current = current.Children[current.childIndex(key, t.compare)]
+	}
+
+	leaf := current
+	targetIndex := leaf.getInsertIndex(key, t.compare)
+	if recordID, replaced := t.replaceConcurrent(leaf, targetIndex, key, value); replaced {
+		return recordID, concurrentDone
+	}
+	if targetIndex == 0 {
+		return 0, concurrentExclusive
+	}
+
+	if leaf.Count < t.Order {
+		recordID := t.storeConcurrent(leaf, targetIndex, key, value)
+		t.countAncestors(leaf, nil)
+		t.pendingLength.Add(1)
+
+		return recordID, concurrentDone
+	}
+
+	// The record is going in beneath the top node, whatever splits, so count it there
+	// and above, where nothing else will change.
+	var counted *treeNode[K, V]
+	if top >= 0 {
+		for _, node := range held[0 : top+1] {
+			node.addSizeConcurrent(1)
+		}
+		for _, node := range held[0:top] {
+			node.latch.Unlock()
+		}
+		held = held[top:]
+		counted = held[0]
+		t.rootLatch.Unlock()
+		holdsRoot = false
+	}
+
+	newSibling := t.splitConcurrent(held, len(held)-1, &created)
+	if t.compare(key, newSibling.Keys[0]) >= 0 {
+		leaf = newSibling
+	}
+	targetIndex = leaf.getInsertIndex(key, t.compare)
+
+	recordID := t.storeConcurrent(leaf, targetIndex, key, value)
+	t.countAncestors(leaf, counted)
+	t.pendingLength.Add(1)
+
+	return recordID, concurrentDone
+}
+
+// replaceConcurrent replaces the value of an existing record for the key if the tree
+// has unique keys. The insert index places any such record just before it.
+func (t *tree[K, V]) replaceConcurrent(leaf *treeNode[K, V], targetIndex int, key K, value V) (collections.RecordID, bool) {
+	if !t.UniqueKeys || targetIndex == 0 || t.compare(leaf.Keys[targetIndex-1], key) != 0 {
+		return 0, false
+	}
+
+	leaf.Records[targetIndex-1].Value = value
+	return leaf.Records[targetIndex-1].RecordID, true
+}
+
+// storeConcurrent adds a record to an exclusively latched leaf with room for it. The
+// new record is never the first in the leaf, so the keys of the ancestors stand. The
+// caller counts the record in the sizes of the ancestors, and in the length.
+func (t *tree[K, V]) storeConcurrent(leaf *treeNode[K, V], targetIndex int, key K, value V) collections.RecordID {
+	recordID := collections.RecordID(atomic.AddInt64((*int64)(&t.RecordCount), 1))
+	leaf.insertRecordSlot(targetIndex, key, record[V]{
+		RecordID: recordID,
+		Value:    value,
+	})
+	leaf.addSizeConcurrent(1)
+	t.indexRecordConcurrent(recordID, key)
+
+	return recordID
+}
+
+// countAncestors counts a record just stored in a leaf in the sizes of its ancestors,
+// up to one that has already counted it.
+func (t *tree[K, V]) countAncestors(leaf *treeNode[K, V], counted *treeNode[K, V]) {
+	for node := leaf.Parent; node != counted; node = node.Parent {
+		node.addSizeConcurrent(1)
+	}
+}
+
+// splitConcurrent splits the full node at a level of the held path, where every node
+// from the top of the path down is exclusively latched. The top node is only ever
+// split if it is the root, as otherwise it has room. A full parent is split before us,
+// so our sibling always has room to join it. The sibling's records were already
+// counted through us, so it joins before it is sized, and no size above us changes -
+// not even for a moment, as the top node's size may be read by anyone with its parent.
+// Returns the new sibling, which is latched and added to created.
+func (t *tree[K, V]) splitConcurrent(held []*treeNode[K, V], level int, created *[]*treeNode[K, V]) *treeNode[K, V] {
+	node := held[level]
+	if level > 0 && held[level-1].Count == t.Order {
+		t.splitConcurrent(held, level-1, created)
+	}
+
+	newSibling := t.moveUpperHalf(node)
+	newSibling.latch.Lock()
+	*created = append(*created, newSibling)
+	node.setSizeConcurrent(node.contentSize())
+
+	// Link the sibling in beside us. Its neighbour's latch is taken left to right, and
+	// only at leaf level, as the internal sibling links are only followed exclusively.
+	next := node.NextSibling
+	if next != nil && node.Leaf {
+		next.latch.Lock()
+		defer next.latch.Unlock()
+	}
+	newSibling.PreviousSibling = node
+	newSibling.NextSibling = next
+	node.NextSibling = newSibling
+	if next != nil {
+		next.PreviousSibling = newSibling
+	}
+
+	if level == 0 {
+		newSibling.setSizeConcurrent(newSibling.contentSize())
+		newRoot := t.createNode(false)
+		newRoot.insertChildAt(0, node.Keys[0], node)
+		newRoot.insertChildAt(1, newSibling.Keys[0], newSibling)
+		t.Root = newRoot
+		return newSibling
+	}
+
+	parent := node.Parent
+	parent.insertChildSlot(parent.indexOf(node)+1, newSibling.Keys[0], newSibling)
+	newSibling.setSizeConcurrent(newSibling.contentSize())
+
+	return newSibling
+}
@@ -0,0 +1,247 @@
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestConcurrentInserts has several writers insert at once, then checks nothing was
+// lost and the tree holds together.
+func TestConcurrentInserts(t *testing.T) {
+	const writers = 8
+	const perWriter = 2000
+
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			tree, err := New[int, int](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			ids := make([][]collections.RecordID, writers)
+			var wg sync.WaitGroup
+			for w := 0; w < writers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					rnd := rand.New(rand.NewSource(int64(order*writers + w)))
+					for i := 0; i < perWriter; i++ {
+						k := rnd.Intn(perWriter * writers)
+						ids[w] = append(ids[w], tree.Insert(k, k))
+					}
+				}(w)
+			}
+			wg.Wait()
+
+			require.Equal(t, writers*perWriter, tree.Count(), "Should hold every record")
+			tree.(collections.Diagnosable).CheckConsistency()
+
+			seen := map[collections.RecordID]bool{}
+			for _, writerIDs := range ids {
+				for _, id := range writerIDs {
+					require.False(t, seen[id], "Record ID %d should be unique", id)
+					seen[id] = true
+				}
+			}
+
+			var keys []int
+			for k, v := range tree.All() {
+				require.Equal(t, k, v, "Value should match its key")
+				keys = append(keys, k)
+			}
+			require.Len(t, keys, writers*perWriter, "Should iterate every record")
+			require.True(t, slices.IsSorted(keys), "Should iterate in key order")
+			require.Equal(t, writers*perWriter, tree.CountRange(-1, perWriter*writers), "Sizes should add up")
+		})
+	}
+}
+
+// TestConcurrentUniqueInserts has writers race over the same keys of a unique tree
+func TestConcurrentUniqueInserts(t *testing.T) {
+	const writers = 8
+	const keys = 500
+
+	tree, err := New[int, int](4, DefaultTestPreAlloc, WithUniqueKeys())
+	require.NoError(t, err, "Should be able to initialize")
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < keys*2; i++ {
+				k := rnd.Intn(keys)
+				tree.Insert(k, k)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	tree.(collections.Diagnosable).CheckConsistency()
+	expected := 0
+	for k := 0; k < keys; k++ {
+		if _, found := tree.Get(k); found {
+			require.Len(t, tree.GetAll(k), 1, "Key %d should be held once", k)
+			expected++
+		}
+	}
+	require.Equal(t, expected, tree.Count(), "Should count each key once")
+}
+
+// TestConcurrentRankQueries runs rank queries and whole-tree reads alongside inserts,
+// which share the tree with them, checking the sizes they rely on always add up.
+func TestConcurrentRankQueries(t *testing.T) {
+	tree, err := New[int, int](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	var writers sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		writers.Add(1)
+		go func(w int) {
+			defer writers.Done()
+			rnd := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 2000; i++ {
+				k := rnd.Intn(1000)
+				tree.Insert(k, k)
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for r := 0; r < 2; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			rnd := rand.New(rand.NewSource(int64(100 + r)))
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				// Whole-tree reads hold off inserts, so only now and then
+				if r == 0 && rnd.Intn(100) == 0 {
+					assert.Empty(t, tree.(collections.Diagnosable).Validate(), "Should be valid between inserts")
+				}
+
+				count := tree.Count()
+				if count == 0 {
+					continue
+				}
+				i := rnd.Intn(count)
+				k, v, found := tree.Select(i)
+				if assert.True(t, found, "Should select %d of %d", i, count) {
+					assert.Equal(t, k, v, "Should select a real record")
+					assert.LessOrEqual(t, tree.Rank(k), tree.Count(), "Rank should be within the tree")
+				}
+				assert.LessOrEqual(t, tree.CountRange(0, 1000), tree.Count(), "Should not count more than the tree holds")
+			}
+		}(r)
+	}
+
+	writers.Wait()
+	close(done)
+	readers.Wait()
+
+	require.Equal(t, 8000, tree.CountRange(0, 1000), "Should count every record")
+	require.Empty(t, tree.(collections.Diagnosable).Validate(), "Should be valid")
+}
+
+// TestConcurrentMixedWorkload runs inserts alongside readers, cursors, deletes and
+// snapshots, which take the tree exclusively, checking readers only ever see ordered
+// data.
+func TestConcurrentMixedWorkload(t *testing.T) {
+	tree, err := New[int, int](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	var writers sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		writers.Add(1)
+		go func(w int) {
+			defer writers.Done()
+			rnd := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 2000; i++ {
+				k := rnd.Intn(1000)
+				switch {
+				case w == 0 && i%10 == 0:
+					tree.Delete(k)
+				case w == 1 && i%100 == 0:
+					tree.Snapshot()
+				default:
+					tree.Insert(k, k)
+				}
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			rnd := rand.New(rand.NewSource(int64(100 + r)))
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				probe := rnd.Intn(1000)
+				if v, found := tree.Get(probe); found {
+					assert.Equal(t, probe, v, "Should read the right value")
+				}
+				if k, _, found := tree.Floor(probe); found {
+					assert.LessOrEqual(t, k, probe, "Floor should not exceed the probe")
+				}
+
+				last := -1
+				for k := range tree.All() {
+					assert.GreaterOrEqual(t, k, last, "Should iterate in order")
+					last = k
+					if k > probe {
+						break
+					}
+				}
+
+				c := tree.Seek(probe)
+				last = -1
+				for c.Next() {
+					assert.GreaterOrEqual(t, c.Key(), max(last, probe), "Cursor should move forward in order")
+					last = c.Key()
+				}
+				if c.Err() != nil {
+					assert.ErrorIs(t, c.Err(), collections.ErrCursorInvalidated, "Should only fail by invalidation")
+				}
+
+				c = tree.Seek(probe)
+				last = probe
+				for c.Prev() {
+					assert.Less(t, c.Key(), last+1, "Cursor should move backward in order")
+					last = c.Key()
+				}
+			}
+		}(r)
+	}
+
+	writers.Wait()
+	close(done)
+	readers.Wait()
+
+	tree.(collections.Diagnosable).CheckConsistency()
+	count := 0
+	for range tree.All() {
+		count++
+	}
+	require.Equal(t, count, tree.Count(), "Count should match the records held")
+}
@@ -0,0 +1,152 @@
+package bplustree
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// BuildSorted bulk-loads a new tree from input that is already sorted by key. Rather
+// than inserting one record at a time, leaves are packed in order and the internal
+// levels are built bottom-up on top of them. The fill factor (0 < f <= 1) controls how
+// full each node is packed, leaving room for later inserts without splitting. Input
+// that is not in ascending key order is rejected with an error.
+func BuildSorted[K generics.Comparable, V any](order int, preallocateSize int, fillFactor float64, input iter.Seq[generics.KeyValuePair[K, V]], opts ...Option) (collections.TreeMap[K, V], error) {
+	return BuildSortedFunc(order, preallocateSize, fillFactor, cmp.Compare[K], input, opts...)
+}
+
+// BuildSortedFunc bulk-loads a new tree, as per BuildSorted, from input that is already
+// sorted according to the comparator. Trees with unique keys reject duplicate input.
+func BuildSortedFunc[K any, V any](order int, preallocateSize int, fillFactor float64, compare func(a, b K) int, input iter.Seq[generics.KeyValuePair[K, V]], opts ...Option) (collections.TreeMap[K, V], error) {
+	if fillFactor <= 0 || fillFactor > 1 {
+		return nil, fmt.Errorf("invalid fill factor %v: must be greater than 0 and at most 1", fillFactor)
+	}
+
+	created, err := NewFunc[K, V](order, preallocateSize, compare, opts...)
+	if err != nil {
+		return nil, err
+	}
+	t := created.(*tree[K, V])
+
+	// Work out how many entries we're packing per node. Internal nodes need at
+	// least two children, or the levels would never converge on a root.
+	perLeaf := max(int(math.Ceil(fillFactor*float64(order))), t.minimumCount())
+	perInternal := max(perLeaf, 2)
+
+	// Pack the leaves
+	var level []*treeNode[K, V]
+	var current *treeNode[K, V]
+	for kvp := range input {
+		if current != nil {
+			previousKey := current.Keys[current.Count-1]
+			comparison := compare(kvp.Key, previousKey)
+			if comparison < 0 {
+				return nil, fmt.Errorf("input is not sorted: key %v follows %v", kvp.Key, previousKey)
+			} else if comparison == 0 && t.UniqueKeys {
+				return nil, fmt.Errorf("input has duplicate key %v", kvp.Key)
+			}
+		}
+
+		if current == nil || current.Count == perLeaf {
+			current = t.appendLevelNode(level, true)
+			level = append(level, current)
+		}
+
+		t.RecordCount++
+		t.Length++
+		current.Keys[current.Count] = kvp.Key
+		current.Records[current.Count] = record[V]{
+			RecordID: t.RecordCount,
+			Value:    kvp.Value,
+		}
+		current.Count++
+		current.addSize(1)
+		t.index[t.RecordCount] = kvp.Key
+	}
+
+	if len(level) == 0 {
+		return t, nil
+	}
+	level = t.balanceLevelTail(level)
+
+	// Build each internal level on top of the last, until we converge on a root
+	for len(level) > 1 {
+		var parents []*treeNode[K, V]
+		var parent *treeNode[K, V]
+		for _, child := range level {
+			if parent == nil || parent.Count == perInternal {
+				parent = t.appendLevelNode(parents, false)
+				parents = append(parents, parent)
+			}
+
+			parent.insertChildAt(parent.Count, child.Keys[0], child)
+		}
+
+		level = t.balanceLevelTail(parents)
+	}
+
+	t.Root = level[0]
+	return t, nil
+}
+
+// appendLevelNode creates a node and links it after the last node of a level that
+// is being built.
+func (t *tree[K, V]) appendLevelNode(level []*treeNode[K, V], leaf bool) *treeNode[K, V] {
+	node := t.createNode(leaf)
+	if len(level) > 0 {
+		previous := level[len(level)-1]
+		previous.NextSibling = node
+		node.PreviousSibling = previous
+	}
+
+	return node
+}
+
+// balanceLevelTail makes sure the last node of a freshly built level meets the minimum
+// occupancy. It either takes entries from its predecessor, or if there aren't enough
+// to go around, is merged into it.
+func (t *tree[K, V]) balanceLevelTail(level []*treeNode[K, V]) []*treeNode[K, V] {
+	if len(level) < 2 {
+		return level
+	}
+
+	minimum := t.minimumCount()
+	last := level[len(level)-1]
+	previous := level[len(level)-2]
+	if last.Count >= minimum {
+		return level
+	}
+
+	if previous.Count+last.Count >= 2*minimum {
+		for last.Count < minimum {
+			if last.Leaf {
+				key, rec := previous.removeRecordAt(previous.Count - 1)
+				last.insertRecordAt(0, key, rec)
+			} else {
+				key, child := previous.removeChildAt(previous.Count - 1)
+				last.insertChildAt(0, key, child)
+			}
+		}
+
+		return level
+	}
+
+	for last.Count > 0 {
+		if last.Leaf {
+			key, rec := last.removeRecordAt(0)
+			previous.insertRecordAt(previous.Count, key, rec)
+		} else {
+			key, child := last.removeChildAt(0)
+			previous.insertChildAt(previous.Count, key, child)
+		}
+	}
+
+	previous.NextSibling = nil
+	t.releaseNode(last)
+
+	return level[:len(level)-1]
+}
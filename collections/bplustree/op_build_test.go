@@ -0,0 +1,106 @@
+package bplustree
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestBuildSorted bulk-loads trees of various shapes and checks they behave like
+// trees built by insertion.
+func TestBuildSorted(t *testing.T) {
+	for _, order := range []int{2, 3, 4, 7, 23} {
+		for _, fillFactor := range []float64{0.01, 0.5, 0.75, 1} {
+			for _, size := range []int{0, 1, 2, 3, 10, 99, 1000} {
+				t.Run(fmt.Sprintf("Order=%d_Fill=%v_Size=%d", order, fillFactor, size), func(t *testing.T) {
+					var input []generics.KeyValuePair[int, int]
+					for i := 0; i < size; i++ {
+						input = append(input, generics.KeyValuePair[int, int]{Key: i / 2, Value: i})
+					}
+
+					tree, err := BuildSorted(order, DefaultTestPreAlloc, fillFactor, slices.Values(input))
+					require.NoError(t, err, "Should build the tree")
+					tree.(collections.Diagnosable).CheckConsistency()
+					require.Equal(t, size, tree.Count(), "Should have every record")
+
+					var output []generics.KeyValuePair[int, int]
+					for k, v := range tree.All() {
+						output = append(output, generics.KeyValuePair[int, int]{Key: k, Value: v})
+					}
+					require.Equal(t, input, output, "Should have the input in order")
+
+					// The tree should carry on working as normal
+					for i := 0; i < 50; i++ {
+						tree.Insert(i*7%(size+1), -i)
+						tree.(collections.Diagnosable).CheckConsistency()
+					}
+					for i := 0; i < size; i += 3 {
+						tree.Delete(i / 2)
+						tree.(collections.Diagnosable).CheckConsistency()
+					}
+				})
+			}
+		}
+	}
+}
+
+// TestBuildSortedRecordIDs checks records are allocated IDs in input order
+func TestBuildSortedRecordIDs(t *testing.T) {
+	input := []generics.KeyValuePair[string, int]{{Key: "a", Value: 1}, {Key: "b", Value: 2}}
+	tree, err := BuildSorted(4, DefaultTestPreAlloc, 1, slices.Values(input))
+	require.NoError(t, err, "Should build the tree")
+
+	id := tree.Insert("c", 3)
+	require.Equal(t, collections.RecordID(3), id, "Should carry on from the loaded records")
+
+	require.True(t, tree.DeleteByID(1), "Should find the first loaded record")
+	value, found := tree.Get("a")
+	require.False(t, found, "Should have removed the first record")
+	require.Zero(t, value, "Should have no value")
+}
+
+// TestBuildSortedErrors checks we reject bad input and parameters
+func TestBuildSortedErrors(t *testing.T) {
+	unsorted := []generics.KeyValuePair[int, int]{{Key: 1}, {Key: 3}, {Key: 2}}
+	_, err := BuildSorted(4, DefaultTestPreAlloc, 1, slices.Values(unsorted))
+	require.ErrorContains(t, err, "not sorted", "Should reject unsorted input")
+
+	sorted := []generics.KeyValuePair[int, int]{{Key: 1}, {Key: 2}}
+	_, err = BuildSorted(4, DefaultTestPreAlloc, 0, slices.Values(sorted))
+	require.Error(t, err, "Should reject a zero fill factor")
+
+	_, err = BuildSorted(4, DefaultTestPreAlloc, 1.5, slices.Values(sorted))
+	require.Error(t, err, "Should reject a fill factor above one")
+
+	_, err = BuildSorted(1, DefaultTestPreAlloc, 1, slices.Values(sorted))
+	require.Error(t, err, "Should reject an invalid order")
+}
+
+// BenchmarkBuildSorted compares bulk-loading against sequential inserts of the same
+// data.
+func BenchmarkBuildSorted(b *testing.B) {
+	var input []generics.KeyValuePair[int, int]
+	for i := 0; i < 500000; i++ {
+		input = append(input, generics.KeyValuePair[int, int]{Key: i, Value: i})
+	}
+
+	b.Run("Inserts", func(b *testing.B) {
+		for b.Loop() {
+			tree, _ := New[int, int](27, DefaultTestPreAlloc)
+			for _, kvp := range input {
+				tree.Insert(kvp.Key, kvp.Value)
+			}
+		}
+	})
+
+	b.Run("BuildSorted", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = BuildSorted(27, DefaultTestPreAlloc, 1, slices.Values(input))
+		}
+	})
+}
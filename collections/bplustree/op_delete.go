@@ -1,3 +1,249 @@
 package bplustree
 
-// TODO: Delete by key
+import (
+	"slices"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// Delete removes all records stored against the key. Returns true if any records
+// were removed.
+func (t *tree[K, V]) Delete(key K) bool {
+	t.lockExclusive()
+
+	deleted := false
+	for {
+		leaf, index := t.lowerBound(key)
+		if leaf == nil || t.compare(leaf.Keys[index], key) != 0 {
+			break
+		}
+
+		t.deleteAt(t.mutable(leaf), index)
+		deleted = true
+	}
+
+	t.lock.Unlock()
+	return deleted
+}
+
+// DeleteByID removes the record with the specified ID. Returns true if the record
+// was found and removed.
+func (t *tree[K, V]) DeleteByID(id collections.RecordID) bool {
+	t.lockExclusive()
+	defer t.lock.Unlock()
+
+	leaf, index := t.findByID(id)
+	if leaf == nil {
+		return false
+	}
+
+	t.deleteAt(t.mutable(leaf), index)
+	return true
+}
+
+// DeleteRange removes the records with keys between from and to inclusive, returning
+// how many were removed. Rather than deleting records one at a time, we cut the tree
+// either side of the range and join the outer parts back together, dropping whole
+// subtrees at once.
+func (t *tree[K, V]) DeleteRange(from K, to K) int {
+	t.lockExclusive()
+	defer t.lock.Unlock()
+
+	removed := t.readView().countRange(from, to)
+	if removed == 0 {
+		return 0
+	}
+
+	leftPieces, restPieces := t.cut(t.Root, func(key K) bool {
+		return t.compare(key, from) < 0
+	}, t, t)
+	slices.Reverse(restPieces)
+	t.Root = nil
+	left := t.joinPieces(leftPieces)
+
+	t.Root = nil
+	rest := t.joinPieces(restPieces)
+	rangePieces, rightPieces := t.cut(rest, func(key K) bool {
+		return t.compare(key, to) <= 0
+	}, t, t)
+	for _, piece := range rangePieces {
+		t.releaseSubtree(piece)
+	}
+	slices.Reverse(rightPieces)
+
+	t.Root = nil
+	right := t.joinPieces(rightPieces)
+	t.join(left, right)
+	t.Length -= removed
+
+	return removed
+}
+
+// deleteAt removes the record in the specified slot of a leaf, then restores the
+// balance of the tree. The leaf must be mutable.
+func (t *tree[K, V]) deleteAt(leaf *treeNode[K, V], index int) {
+	t.unindexRecord(leaf.Records[index].RecordID)
+	leaf.removeRecordAt(index)
+	t.adjustAncestorSizes(leaf, -1)
+	t.invalidateAggregates(leaf)
+	t.Length--
+
+	// If we removed the lead slot, our parents need our new lead key
+	if index == 0 && leaf.Count > 0 {
+		leaf.updateParentReference()
+	}
+
+	t.rebalance(leaf)
+}
+
+// rebalance restores the minimum occupancy of a node after a removal, by borrowing
+// from a sibling or merging with one. Merges remove a child from the parent, so we
+// work our way up the tree until things settle.
+func (t *tree[K, V]) rebalance(node *treeNode[K, V]) {
+	parent := node.Parent
+	if parent == nil {
+		t.collapseRoot()
+		return
+	}
+
+	minimum := t.minimumCount()
+	if node.Count >= minimum {
+		return
+	}
+
+	// Only siblings under the same parent are candidates, as borrowing across
+	// parents would mean fixing the keys of two ancestries.
+	index := parent.indexOf(node)
+	var left, right *treeNode[K, V]
+	if index > 0 {
+		left = parent.Children[index-1]
+	}
+	if index < parent.Count-1 {
+		right = parent.Children[index+1]
+	}
+
+	// Our parent is already mutable, but the sibling we lean on may still be shared
+	// with a snapshot.
+	switch {
+	case left != nil && left.Count > minimum:
+		t.borrowFromLeft(node, t.mutable(left))
+	case right != nil && right.Count > minimum:
+		t.borrowFromRight(node, t.mutable(right))
+	case left != nil:
+		t.merge(t.mutable(left), node)
+		t.rebalance(parent)
+	case right != nil:
+		t.merge(node, t.mutable(right))
+		t.rebalance(parent)
+	case node.Count == 0:
+		// Low orders allow a node to be the only child of its parent. There is
+		// nobody to borrow from, so once empty the node is removed outright.
+		t.detach(node)
+		t.rebalance(parent)
+	}
+}
+
+// collapseRoot shrinks the height of the tree when the root no longer does any work.
+func (t *tree[K, V]) collapseRoot() {
+	for t.Root != nil {
+		root := t.Root
+
+		switch {
+		case root.Count == 0:
+			t.Root = nil
+		case !root.Leaf && root.Count == 1:
+			t.Root = root.Children[0]
+			t.Root.Parent = nil
+		default:
+			return
+		}
+
+		t.releaseNode(root)
+	}
+}
+
+// borrowFromLeft moves the last entry of the left sibling to the front of the node.
+func (t *tree[K, V]) borrowFromLeft(node *treeNode[K, V], left *treeNode[K, V]) {
+	lastIndex := left.Count - 1
+	if node.Leaf {
+		key, rec := left.removeRecordAt(lastIndex)
+		node.insertRecordAt(0, key, rec)
+	} else {
+		key, child := left.removeChildAt(lastIndex)
+		node.insertChildAt(0, key, child)
+	}
+	t.invalidateAggregates(left)
+	t.invalidateAggregates(node)
+
+	node.updateParentReference()
+}
+
+// borrowFromRight moves the first entry of the right sibling to the end of the node.
+func (t *tree[K, V]) borrowFromRight(node *treeNode[K, V], right *treeNode[K, V]) {
+	if node.Leaf {
+		key, rec := right.removeRecordAt(0)
+		node.insertRecordAt(node.Count, key, rec)
+	} else {
+		key, child := right.removeChildAt(0)
+		node.insertChildAt(node.Count, key, child)
+	}
+	t.invalidateAggregates(right)
+	t.invalidateAggregates(node)
+
+	right.updateParentReference()
+
+	// An empty node has just gained its lead key
+	if node.Count == 1 {
+		node.updateParentReference()
+	}
+}
+
+// merge moves every entry of source onto the end of target, then removes source
+// from the tree. The two nodes must be adjacent children of the same parent.
+func (t *tree[K, V]) merge(target *treeNode[K, V], source *treeNode[K, V]) {
+	wasEmpty := target.Count == 0
+
+	copy(target.Keys[target.Count:], source.Keys[0:source.Count])
+	if target.Leaf {
+		copy(target.Records[target.Count:], source.Records[0:source.Count])
+	} else {
+		for i, child := range source.Children[0:source.Count] {
+			child.Parent = target
+			target.Children[target.Count+i] = child
+		}
+	}
+
+	target.Count += source.Count
+	target.addSize(source.subtreeSize())
+	target.Version++
+	t.invalidateAggregates(target)
+	source.Count = 0
+	source.setSize(0)
+	t.detach(source)
+
+	if wasEmpty && target.Count > 0 {
+		target.updateParentReference()
+	}
+}
+
+// detach removes a node from its parent and unlinks it from its level, before
+// returning its storage to the pools.
+func (t *tree[K, V]) detach(node *treeNode[K, V]) {
+	parent := node.Parent
+	index := parent.indexOf(node)
+	parent.removeChildAt(index)
+	t.invalidateAggregates(parent)
+
+	if index == 0 && parent.Count > 0 {
+		parent.updateParentReference()
+	}
+
+	if node.PreviousSibling != nil {
+		node.PreviousSibling.NextSibling = node.NextSibling
+	}
+	if node.NextSibling != nil {
+		node.NextSibling.PreviousSibling = node.PreviousSibling
+	}
+
+	t.releaseNode(node)
+}
@@ -0,0 +1,216 @@
+package bplustree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestDeleteBasic removes keys one at a time, checking the tree after every step
+func TestDeleteBasic(t *testing.T) {
+	for order := 2; order < 10; order++ {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			tr, err := New[int, int](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			keys := rand.New(rand.NewSource(int64(order))).Perm(200)
+			for _, k := range keys {
+				tr.Insert(k, k*10)
+			}
+			tr.(collections.Diagnosable).CheckConsistency()
+
+			for i, k := range keys {
+				require.True(t, tr.Delete(k), "Should delete key %d", k)
+				require.False(t, tr.Delete(k), "Should not delete key %d twice", k)
+				tr.(collections.Diagnosable).CheckConsistency()
+				require.Equal(t, len(keys)-i-1, tr.Count(), "Should have the right count after deleting")
+			}
+
+			require.Nil(t, tr.(*tree[int, int]).Root, "Should have collapsed the tree completely")
+		})
+	}
+}
+
+// TestDeleteRandomWithDuplicates mixes inserts and deletes of a small key space, so
+// duplicate keys span leaves, and compares the tree against a simple model.
+func TestDeleteRandomWithDuplicates(t *testing.T) {
+	for _, order := range []int{2, 3, 4, 5, 7, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order * 1337)))
+			tree, err := New[int, int](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			model := map[int]int{}
+			for i := 0; i < 3000; i++ {
+				k := rnd.Intn(50)
+				if rnd.Float64() < 0.6 {
+					tree.Insert(k, i)
+					model[k]++
+				} else {
+					require.Equal(t, model[k] > 0, tree.Delete(k), "Delete result should match model for key %d", k)
+					delete(model, k)
+				}
+
+				tree.(collections.Diagnosable).CheckConsistency()
+			}
+
+			expected := []int{}
+			for k, n := range model {
+				for range n {
+					expected = append(expected, k)
+				}
+			}
+			slices.Sort(expected)
+
+			actual := []int{}
+			for kvp := range tree.Scan() {
+				actual = append(actual, kvp.Key)
+			}
+			require.Equal(t, expected, actual, "Tree contents should match the model")
+		})
+	}
+}
+
+// TestDeleteByID removes individual records, leaving other records with the same key
+func TestDeleteByID(t *testing.T) {
+	tree, err := New[int, string](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	ids := []collections.RecordID{}
+	for i := 0; i < 20; i++ {
+		ids = append(ids, tree.Insert(i%4, fmt.Sprintf("value-%d", i)))
+	}
+
+	for i, id := range ids {
+		if i%2 == 0 {
+			require.True(t, tree.DeleteByID(id), "Should delete record %d", id)
+			tree.(collections.Diagnosable).CheckConsistency()
+		}
+	}
+
+	require.False(t, tree.DeleteByID(ids[0]), "Should not delete a record twice")
+	require.False(t, tree.DeleteByID(collections.RecordID(1000)), "Should not delete an unknown record")
+	require.Equal(t, 10, tree.Count(), "Should have half the records left")
+
+	for kvp := range tree.Scan() {
+		require.Equal(t, 1, kvp.Key%2, "Only odd keys should remain")
+	}
+}
+
+// TestDeleteRecyclesStorage checks freed node storage goes back into the pools
+func TestDeleteRecyclesStorage(t *testing.T) {
+	tr, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+	impl := tr.(*tree[int, int])
+
+	for i := 0; i < 100; i++ {
+		tr.Insert(i, i)
+	}
+	keysPooled := impl.preallocatedKeySets.Count()
+	recordsPooled := impl.preallocatedRecordSets.Count()
+	childrenPooled := impl.preallocatedChildSets.Count()
+
+	for i := 0; i < 100; i++ {
+		tr.Delete(i)
+	}
+
+	require.Greater(t, impl.preallocatedKeySets.Count(), keysPooled, "Key sets should be returned")
+	require.Greater(t, impl.preallocatedRecordSets.Count(), recordsPooled, "Record sets should be returned")
+	require.Greater(t, impl.preallocatedChildSets.Count(), childrenPooled, "Child sets should be returned")
+
+	// Reused storage must come back clean
+	for i := 0; i < 100; i++ {
+		tr.Insert(i, i)
+	}
+	tr.(collections.Diagnosable).CheckConsistency()
+	require.Equal(t, 100, tr.Count(), "Should have all records after reinsert")
+}
+
+// TestDeleteRange removes random ranges of a tree with duplicate keys, comparing it
+// against a sorted slice after every removal.
+func TestDeleteRange(t *testing.T) {
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order)))
+			tree, err := New[int, int](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			var model []int
+			for i := 0; i < 200; i++ {
+				// Top the tree back up every so often, so we keep cutting through
+				// trees of some height
+				if i%20 == 0 {
+					for j := 0; j < 200; j++ {
+						k := rnd.Intn(1000)
+						tree.Insert(k, k)
+						index, _ := slices.BinarySearch(model, k+1)
+						model = slices.Insert(model, index, k)
+					}
+				}
+
+				from := rnd.Intn(1100) - 50
+				to := from + rnd.Intn(200) - 20
+				expected := slices.DeleteFunc(slices.Clone(model), func(k int) bool {
+					return k >= from && k <= to
+				})
+
+				require.Equal(t, len(model)-len(expected), tree.DeleteRange(from, to), "Should remove the records from %d to %d", from, to)
+				model = expected
+				tree.(collections.Diagnosable).CheckConsistency()
+
+				require.Equal(t, len(model), tree.Count(), "Should have the right count")
+				require.Equal(t, model, collectKeys(tree), "Should hold the right keys")
+				require.Equal(t, model, cursorKeys(tree), "Cursors should walk the right keys")
+			}
+		})
+	}
+}
+
+// TestDeleteRangeEverything removes the whole tree in one go
+func TestDeleteRangeEverything(t *testing.T) {
+	tr, err := New[int, int](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i++ {
+		tr.Insert(i, i)
+	}
+
+	require.Zero(t, tr.DeleteRange(200, 300), "Should remove nothing beyond the keys")
+	require.Zero(t, tr.DeleteRange(50, 10), "Should remove nothing from a backwards range")
+	require.Equal(t, 100, tr.DeleteRange(0, 99), "Should remove every record")
+	require.Zero(t, tr.Count(), "Should be empty")
+	require.Nil(t, tr.(*tree[int, int]).Root, "Should have no root")
+
+	tr.Insert(5, 5)
+	tr.(collections.Diagnosable).CheckConsistency()
+	require.Equal(t, []int{5}, collectKeys(tr), "Should take inserts again")
+}
+
+// collectKeys gets the keys of a tree in order
+func collectKeys(tree collections.TreeMap[int, int]) []int {
+	keys := []int{}
+	for k := range tree.All() {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// cursorKeys gets the keys of a tree by walking a cursor over it, which follows the
+// sibling links rather than descending from the root.
+func cursorKeys(tree collections.TreeMap[int, int]) []int {
+	keys := []int{}
+	c := tree.Seek(math.MinInt)
+	for c.Next() {
+		keys = append(keys, c.Key())
+	}
+	c.Close()
+
+	return keys
+}
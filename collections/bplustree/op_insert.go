@@ -2,36 +2,66 @@ package bplustree
 
 import "github.com/zeroflucs-given/generics/collections"
 
-// Insert a value into the tree
+// Insert a value into the tree. If the tree has unique keys, an existing record for
+// the key has its value replaced instead. Inserts run concurrently with each other
+// where they can, falling back to taking the tree to themselves where they can't. With
+// nobody else about, we take the tree to ourselves straight away, as latching our way
+// down only pays when there's someone to share with.
 func (t *tree[K, V]) Insert(key K, value V) collections.RecordID {
-	t.lock.Lock()
+	if !t.tryLockExclusive() {
+		if recordID, ok := t.insertConcurrent(key, value); ok {
+			return recordID
+		}
+		t.lockExclusive()
+	}
+
+	recordID := t.insertOrReplace(key, value)
+	t.lock.Unlock()
+
+	return recordID
+}
+
+// insertOrReplace inserts a record as Insert does, replacing the value of an existing
+// record if the tree has unique keys. The caller must hold the write lock.
+func (t *tree[K, V]) insertOrReplace(key K, value V) collections.RecordID {
+	if t.UniqueKeys {
+		recordID, _ := t.upsertInternal(key, value)
+		return recordID
+	}
+
+	return t.insertInternal(key, value)
+}
 
+// insertInternal adds a new record to the tree. The caller must hold the write lock.
+func (t *tree[K, V]) insertInternal(key K, value V) collections.RecordID {
 	// Allocate a record ID
 	t.RecordCount++
+	t.Length++
 	recordID := t.RecordCount
 	record := record[V]{
 		RecordID: recordID,
 		Value:    value,
 	}
+	t.indexRecord(recordID, key)
 
 	// Case: Empty tree
 	if t.Root == nil {
 		root := t.createNode(true)
-		root.insertRecord(key, record)
+		root.insertRecord(key, record, t.compare)
 		t.Root = root
-		t.lock.Unlock()
 		return recordID
 	}
 
-	targetLeaf := t.findLeaf(key)
+	targetLeaf := t.mutable(t.findLeaf(key))
 	if targetLeaf.Count == t.Order {
 		targetLeaf = t.split(targetLeaf, key, 0)
 	}
 
 	// Write to the records list
-	targetLeaf.insertRecord(key, record)
+	targetLeaf.insertRecord(key, record, t.compare)
+	t.adjustAncestorSizes(targetLeaf, 1)
+	t.invalidateAggregates(targetLeaf)
 
-	t.lock.Unlock()
 	return recordID
 }
 
@@ -39,8 +69,47 @@ func (t *tree[K, V]) Insert(key K, value V) collections.RecordID {
 func (t *tree[K, V]) split(existingNode *treeNode[K, V], keyToAccomodate K, depth int) *treeNode[K, V] {
 	// Track some state before things get exciting
 	existingParent := existingNode.Parent
+	t.invalidateAggregates(existingNode)
 
 	// Split the children/data of the subject node
+	newSibling := t.moveUpperHalf(existingNode)
+	newSiblingFirstKey := newSibling.Keys[0]
+
+	// The records of the new sibling are detached from the tree until it has been
+	// given a parent, so take them off the sizes of our ancestry for now.
+	newSibling.recomputeSize()
+	existingNode.addSize(-newSibling.subtreeSize())
+	t.adjustAncestorSizes(existingNode, -newSibling.subtreeSize())
+
+	if existingParent == nil {
+		// Case 1 - We're splitting the root
+		newRoot := t.createNode(false)
+		newRoot.insertChild(existingNode.Keys[0], existingNode, t.compare)
+		newRoot.insertChild(newSiblingFirstKey, newSibling, t.compare)
+		t.Root = newRoot
+	} else if existingParent.Count < t.Order {
+		// Case 2 - We're inserting into a parent that has space
+		existingParent.insertChildAfter(existingNode, newSiblingFirstKey, newSibling)
+		t.adjustAncestorSizes(existingParent, newSibling.subtreeSize())
+	} else {
+		// Case 3 - Split recursively. Our node may have moved to the new half of
+		// the parent, so we follow it rather than relying on the key.
+		t.split(existingParent, newSiblingFirstKey, depth+1)
+		existingNode.Parent.insertChildAfter(existingNode, newSiblingFirstKey, newSibling)
+		t.adjustAncestorSizes(existingNode.Parent, newSibling.subtreeSize())
+	}
+
+	// Now determine which of the two nodes we call home
+	if t.compare(keyToAccomodate, newSiblingFirstKey) < 0 {
+		return existingNode
+	}
+
+	return newSibling
+}
+
+// moveUpperHalf moves the upper half of the slots of a full node into a new sibling.
+// The sibling is returned without being linked into the tree, and without a size.
+func (t *tree[K, V]) moveUpperHalf(existingNode *treeNode[K, V]) *treeNode[K, V] {
 	newSibling := t.createNode(existingNode.Leaf)
 	splitPoint := existingNode.Count / 2
 
@@ -59,28 +128,8 @@ func (t *tree[K, V]) split(existingNode *treeNode[K, V], keyToAccomodate K, dept
 	}
 
 	existingNode.Count = splitPoint
+	existingNode.Version++
 	newSibling.Count = t.Order - splitPoint
-	newSiblingFirstKey := newSibling.Keys[0]
-
-	if existingParent == nil {
-		// Case 1 - We're splitting the root
-		newRoot := t.createNode(false)
-		newRoot.insertChild(existingNode.Keys[0], existingNode)
-		newRoot.insertChild(newSiblingFirstKey, newSibling)
-		t.Root = newRoot
-	} else if existingParent.Count < t.Order {
-		// Case 2 - We're inserting into a parent that has space
-		existingParent.insertChild(newSiblingFirstKey, newSibling)
-	} else {
-		// Case 3 - Split recursively
-		newParent := t.split(existingNode.Parent, newSiblingFirstKey, depth+1)
-		newParent.insertChild(newSiblingFirstKey, newSibling)
-	}
-
-	// Now determine which of the two nodes we call home
-	if keyToAccomodate < newSiblingFirstKey {
-		return existingNode
-	}
 
 	return newSibling
 }
@@ -0,0 +1,232 @@
+package bplustree
+
+// Range operations - deleting a range, splitting and merging - work on whole subtrees
+// rather than records. A tree is cut in two by dismantling the path down to a key:
+// every subtree hanging off the path to the left of it goes to one side, every one to
+// the right goes to the other. The pieces are then joined back up into trees, by
+// grafting the shorter of two trees onto the edge of the taller one at the matching
+// height. Only the nodes along the path and the graft points are ever touched.
+
+// cut dismantles the path to a key through a detached subtree, returning the pieces
+// either side of it from the top down. Pieces on the left hold the keys that precede
+// the key. The pieces are built by the owners of each side, so they can go straight
+// into another tree.
+func (t *tree[K, V]) cut(root *treeNode[K, V], precedes func(key K) bool, leftOwner *tree[K, V], rightOwner *tree[K, V]) ([]*treeNode[K, V], []*treeNode[K, V]) {
+	var leftPieces, rightPieces []*treeNode[K, V]
+
+	for node := root; node != nil; {
+		// The neighbours of the path end up on either side of the cut
+		if node.PreviousSibling != nil {
+			node.PreviousSibling.NextSibling = nil
+		}
+		if node.NextSibling != nil {
+			node.NextSibling.PreviousSibling = nil
+		}
+
+		if node.Leaf {
+			index := 0
+			for index < node.Count && precedes(node.Keys[index]) {
+				index++
+			}
+
+			leftPieces = appendPiece(leftPieces, leftOwner.leafPiece(node, 0, index))
+			rightPieces = appendPiece(rightPieces, rightOwner.leafPiece(node, index, node.Count))
+			t.releaseNode(node)
+			break
+		}
+
+		// As with a search, we follow the last child that leads with a key before the
+		// cut, as anything in the children before it is also before the cut.
+		index := 0
+		for index+1 < node.Count && precedes(node.Keys[index+1]) {
+			index++
+		}
+
+		next := node.Children[index]
+		leftPieces = appendPiece(leftPieces, leftOwner.internalPiece(node, 0, index))
+		rightPieces = appendPiece(rightPieces, rightOwner.internalPiece(node, index+1, node.Count))
+		t.releaseNode(node)
+		node = next
+	}
+
+	return leftPieces, rightPieces
+}
+
+// appendPiece adds a piece to a list of pieces, skipping empty ones
+func appendPiece[K any, V any](pieces []*treeNode[K, V], piece *treeNode[K, V]) []*treeNode[K, V] {
+	if piece == nil {
+		return pieces
+	}
+
+	return append(pieces, piece)
+}
+
+// leafPiece copies a run of the records of a leaf into a new detached leaf. Returns
+// nil if the run is empty.
+func (t *tree[K, V]) leafPiece(node *treeNode[K, V], from int, to int) *treeNode[K, V] {
+	if from == to {
+		return nil
+	}
+
+	piece := t.createNode(true)
+	copy(piece.Keys, node.Keys[from:to])
+	copy(piece.Records, node.Records[from:to])
+	piece.Count = to - from
+	piece.setSize(piece.Count)
+
+	return piece
+}
+
+// internalPiece gathers a run of the children of a node into a new detached node. A
+// single child stands as a piece by itself. Returns nil if the run is empty.
+func (t *tree[K, V]) internalPiece(node *treeNode[K, V], from int, to int) *treeNode[K, V] {
+	switch to - from {
+	case 0:
+		return nil
+	case 1:
+		piece := node.Children[from]
+		piece.Parent = nil
+		return piece
+	}
+
+	piece := t.createNode(false)
+	for i := from; i < to; i++ {
+		piece.insertChildAt(piece.Count, node.Keys[i], node.Children[i])
+	}
+
+	return piece
+}
+
+// join combines two detached trees into the root of this one, where no key of left
+// comes after any key of right. Either may be nil. The shorter tree is grafted onto
+// the facing edge of the taller, splitting nodes on the way up if need be. Its root
+// is exempt from the minimum occupancy no longer, so we rebalance it afterwards.
+func (t *tree[K, V]) join(left *treeNode[K, V], right *treeNode[K, V]) *treeNode[K, V] {
+	switch {
+	case left == nil:
+		t.Root = right
+		return right
+	case right == nil:
+		t.Root = left
+		return left
+	}
+
+	linkSpines(left, right)
+
+	leftHeight := left.height()
+	rightHeight := right.height()
+	switch {
+	case leftHeight == rightHeight:
+		root := t.createNode(false)
+		root.insertChildAt(0, left.Keys[0], left)
+		root.insertChildAt(1, right.Keys[0], right)
+		t.Root = root
+
+		t.restoreOccupancy(left)
+		if right.Parent != nil {
+			t.restoreOccupancy(right)
+		}
+	case leftHeight > rightHeight:
+		t.Root = left
+		host := left
+		for height := leftHeight; height > rightHeight+1; height-- {
+			host = host.Children[host.Count-1]
+		}
+
+		host = t.mutable(host)
+		if host.Count == t.Order {
+			host = t.split(host, right.Keys[0], 0)
+		}
+		host.insertChildAt(host.Count, right.Keys[0], right)
+		t.adjustAncestorSizes(host, right.subtreeSize())
+		t.invalidateAggregates(host)
+		t.restoreOccupancy(right)
+	default:
+		t.Root = right
+		host := right
+		for height := rightHeight; height > leftHeight+1; height-- {
+			host = host.Children[0]
+		}
+
+		// Splitting leaves us in the lower half, which is where we're headed
+		host = t.mutable(host)
+		if host.Count == t.Order {
+			t.split(host, left.Keys[0], 0)
+		}
+		host.insertChildAt(0, left.Keys[0], left)
+		host.updateParentReference()
+		t.adjustAncestorSizes(host, left.subtreeSize())
+		t.invalidateAggregates(host)
+		t.restoreOccupancy(left)
+	}
+
+	return t.Root
+}
+
+// restoreOccupancy rebalances a node that was grafted into the tree, until it meets
+// the minimum occupancy. Unlike a node that has lost a single entry, it may be well
+// short, so it borrows an entry at a time until its sibling has no more to spare, at
+// which point the two are merged.
+func (t *tree[K, V]) restoreOccupancy(node *treeNode[K, V]) {
+	for node.Parent != nil && node.Count < t.minimumCount() {
+		node = t.mutable(node)
+		count := node.Count
+		t.rebalance(node)
+
+		// An only child has nobody to lean on, and is left as it is
+		if node.Count == count {
+			return
+		}
+	}
+}
+
+// linkSpines links the right edge of one tree to the left edge of another, at every
+// level they share.
+func linkSpines[K any, V any](left *treeNode[K, V], right *treeNode[K, V]) {
+	leftHeight := left.height()
+	rightHeight := right.height()
+	for ; leftHeight > rightHeight; leftHeight-- {
+		left = left.Children[left.Count-1]
+	}
+	for ; rightHeight > leftHeight; rightHeight-- {
+		right = right.Children[0]
+	}
+
+	for {
+		left.NextSibling = right
+		right.PreviousSibling = left
+		if left.Leaf {
+			return
+		}
+
+		left = left.Children[left.Count-1]
+		right = right.Children[0]
+	}
+}
+
+// joinPieces joins the pieces from one side of a cut into a single tree, returning its
+// root. The pieces are in key order.
+func (t *tree[K, V]) joinPieces(pieces []*treeNode[K, V]) *treeNode[K, V] {
+	var root *treeNode[K, V]
+	for _, piece := range pieces {
+		root = t.join(root, piece)
+	}
+
+	return root
+}
+
+// releaseSubtree drops the records of a detached subtree from the index, and returns
+// every node to the pools.
+func (t *tree[K, V]) releaseSubtree(node *treeNode[K, V]) {
+	if node.Leaf {
+		for _, rec := range node.Records[0:node.Count] {
+			t.unindexRecord(rec.RecordID)
+		}
+	} else {
+		for _, child := range node.Children[0:node.Count] {
+			t.releaseSubtree(child)
+		}
+	}
+
+	t.releaseNode(node)
+}
@@ -0,0 +1,182 @@
+package bplustree
+
+import (
+	"sync/atomic"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// serials numbers trees, so that a merge always locks the pair in the same order
+var serials atomic.Uint64
+
+// Merge moves the records of another tree into this one, leaving it empty. Where every
+// key of the other tree follows ours, or precedes them, it is grafted on whole. Its
+// records keep their IDs, unless one is already taken by a record of ours, in which
+// case they are all given new IDs following on from ours - which means visiting every
+// leaf. Trees split apart by SplitAt never share an ID, so can always be put back
+// together as they were. Otherwise, or if the trees are of different orders, the
+// records are inserted one at a time, with new IDs.
+//
+// Read-only trees, such as snapshots, and other implementations are copied from rather
+// than emptied.
+func (t *tree[K, V]) Merge(other collections.TreeMap[K, V]) {
+	source, ok := other.(*tree[K, V])
+	if !ok {
+		t.copyFrom(other)
+		return
+	} else if source == t {
+		return
+	}
+
+	// Lock both trees, always in the same order, so that two trees merging into each
+	// other can't deadlock.
+	first, second := t, source
+	if second.serial < first.serial {
+		first, second = second, first
+	}
+	first.lockExclusive()
+	defer first.lock.Unlock()
+	second.lockExclusive()
+	defer second.lock.Unlock()
+
+	root := source.Root
+	generation := source.Generation
+	recordCount := source.RecordCount
+	source.settleIndex()
+	index := source.index
+	source.reset()
+	if root == nil {
+		return
+	}
+
+	// Grafting on whole needs nodes of the same size and aggregates, and can't bring
+	// duplicate keys into a tree that doesn't allow them.
+	after, before := false, false
+	if source.Order == t.Order && source.aggregates == t.aggregates && (source.UniqueKeys || !t.UniqueKeys) {
+		after, before = t.placement(root)
+	}
+	if !after && !before {
+		t.insertFrom(root)
+		return
+	}
+
+	t.adopt(root, generation)
+	if t.sharesID(index) {
+		root = t.renumber(root, t.RecordCount)
+		for id, key := range index {
+			t.index[id+t.RecordCount] = key
+		}
+		t.RecordCount += recordCount
+	} else {
+		for id, key := range index {
+			t.index[id] = key
+		}
+		t.RecordCount = max(t.RecordCount, recordCount)
+	}
+	t.Length += root.subtreeSize()
+
+	if after {
+		t.join(t.Root, root)
+	} else {
+		t.join(root, t.Root)
+	}
+}
+
+// placement works out if every key of a detached subtree comes after ours, or before
+// them. Keys equal to our last may follow us, as the merged records are newer, but
+// not precede us.
+func (t *tree[K, V]) placement(root *treeNode[K, V]) (after bool, before bool) {
+	if t.Root == nil {
+		return true, false
+	}
+
+	firstLeaf := root.edgeLeaf(false)
+	lastLeaf := root.edgeLeaf(true)
+	ourFirst := t.Root.edgeLeaf(false)
+	ourLast := t.Root.edgeLeaf(true)
+
+	comparison := t.compare(ourLast.Keys[ourLast.Count-1], firstLeaf.Keys[0])
+	if comparison < 0 || (comparison == 0 && !t.UniqueKeys) {
+		return true, false
+	}
+
+	return false, t.compare(lastLeaf.Keys[lastLeaf.Count-1], ourFirst.Keys[0]) < 0
+}
+
+// sharesID checks if any record in the index of another tree has the ID of one of ours.
+// The caller must hold the lock exclusively.
+func (t *tree[K, V]) sharesID(index map[collections.RecordID]K) bool {
+	t.settleIndex()
+	for id := range index {
+		if _, found := t.index[id]; found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// adopt takes over the nodes of a detached subtree that were in the current generation
+// of the tree they came from. No snapshot shares them, so they can be written to in
+// place rather than copied. Beneath a node from an older generation, everything is
+// older still, so we stop there.
+func (t *tree[K, V]) adopt(node *treeNode[K, V], generation uint64) {
+	if node.Generation != generation {
+		return
+	}
+
+	node.Generation = t.Generation
+	if !node.Leaf {
+		for _, child := range node.Children[0:node.Count] {
+			t.adopt(child, generation)
+		}
+	}
+}
+
+// renumber offsets the record IDs of a detached subtree, copying any leaves shared
+// with a snapshot. Returns the root, which may have been copied along the way.
+func (t *tree[K, V]) renumber(root *treeNode[K, V], offset collections.RecordID) *treeNode[K, V] {
+	// Copies replace the root of the tree, so the subtree stands in for ours meanwhile
+	ours := t.Root
+	t.Root = root
+
+	for leaf := root.edgeLeaf(false); leaf != nil; leaf = leaf.NextSibling {
+		leaf = t.mutable(leaf)
+		for i := range leaf.Records[0:leaf.Count] {
+			leaf.Records[i].RecordID += offset
+		}
+	}
+
+	root, t.Root = t.Root, ours
+	return root
+}
+
+// insertFrom inserts the records of a detached subtree one at a time. The caller must
+// hold the write lock.
+func (t *tree[K, V]) insertFrom(root *treeNode[K, V]) {
+	for leaf := root.edgeLeaf(false); leaf != nil; leaf = leaf.NextSibling {
+		for i, key := range leaf.Keys[0:leaf.Count] {
+			t.insertOrReplace(key, leaf.Records[i].Value)
+		}
+	}
+}
+
+// copyFrom inserts the records of a tree we can't take the nodes of. They are read
+// before taking our lock, as the other tree may be a snapshot of this one.
+func (t *tree[K, V]) copyFrom(other collections.TreeMap[K, V]) {
+	var records []generics.KeyValuePair[K, V]
+	for k, v := range other.All() {
+		records = append(records, generics.KeyValuePair[K, V]{
+			Key:   k,
+			Value: v,
+		})
+	}
+
+	t.lockExclusive()
+	defer t.lock.Unlock()
+
+	for _, kvp := range records {
+		t.insertOrReplace(kvp.Key, kvp.Value)
+	}
+}
@@ -0,0 +1,184 @@
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// buildRandom fills a new tree with random keys from a range, returning the sorted keys
+func buildRandom(t *testing.T, rnd *rand.Rand, order int, count int, from int, to int, opts ...Option) (collections.TreeMap[int, int], []int) {
+	tree, err := New[int, int](order, DefaultTestPreAlloc, opts...)
+	require.NoError(t, err, "Should be able to initialize")
+
+	var model []int
+	for i := 0; i < count; i++ {
+		k := from + rnd.Intn(to-from)
+		tree.Insert(k, k)
+		index, _ := slices.BinarySearch(model, k+1)
+		model = slices.Insert(model, index, k)
+	}
+
+	return tree, model
+}
+
+// requireUniqueIDs checks no two records of a tree share an ID
+func requireUniqueIDs(t *testing.T, tree collections.TreeMap[int, int]) {
+	seen := map[collections.RecordID]bool{}
+	c := tree.Seek(-1)
+	for c.Next() {
+		require.False(t, seen[c.RecordID()], "Record ID %d should be unique", c.RecordID())
+		seen[c.RecordID()] = true
+	}
+}
+
+// TestMergeDisjoint grafts trees of every relative height onto either end of another
+func TestMergeDisjoint(t *testing.T) {
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order)))
+
+			for _, sizes := range [][2]int{{1, 1}, {1, 500}, {500, 1}, {30, 500}, {500, 30}, {200, 200}} {
+				// Others come after, with a shared boundary key, then before
+				target, model := buildRandom(t, rnd, order, sizes[0], 100, 200)
+				after, afterModel := buildRandom(t, rnd, order, sizes[1], model[len(model)-1], 300)
+				before, beforeModel := buildRandom(t, rnd, order, sizes[1], 0, model[0])
+				afterSnapshot := after.Snapshot()
+
+				target.Merge(after)
+				target.(collections.Diagnosable).CheckConsistency()
+				model = append(model, afterModel...)
+				require.Equal(t, model, collectKeys(target), "Should append the other tree")
+				require.Equal(t, model, cursorKeys(target), "Should link the other tree")
+				require.Zero(t, after.Count(), "Should empty the other tree")
+				require.Equal(t, afterModel, collectKeys(afterSnapshot), "Snapshots of the other tree should be untouched")
+
+				target.Merge(before)
+				target.(collections.Diagnosable).CheckConsistency()
+				model = append(beforeModel, model...)
+				require.Equal(t, model, collectKeys(target), "Should prepend the other tree")
+				require.Equal(t, model, cursorKeys(target), "Should link the other tree")
+				require.Equal(t, len(model), target.CountRange(-1, 300), "Should size the subtrees")
+				requireUniqueIDs(t, target)
+
+				// The merged nodes carry on as part of the tree
+				for i := 0; i < 200; i++ {
+					target.Delete(rnd.Intn(300))
+					target.Insert(rnd.Intn(300), 0)
+				}
+				target.(collections.Diagnosable).CheckConsistency()
+				requireUniqueIDs(t, target)
+			}
+		})
+	}
+}
+
+// TestMergeSplitRoundTrip splits a tree and merges it back together, from either side,
+// checking the records keep their IDs
+func TestMergeSplitRoundTrip(t *testing.T) {
+	for _, fromLeft := range []bool{true, false} {
+		rnd := rand.New(rand.NewSource(1))
+		tree, model := buildRandom(t, rnd, 4, 1000, 0, 500)
+		ids := map[collections.RecordID]int{}
+		for c := tree.Seek(-1); c.Next(); {
+			ids[c.RecordID()] = c.Key()
+		}
+
+		left, right := tree.SplitAt(250)
+		merged, other := left, right
+		if !fromLeft {
+			merged, other = right, left
+		}
+		merged.Merge(other)
+		merged.(collections.Diagnosable).CheckConsistency()
+		require.Equal(t, model, collectKeys(merged), "Should hold every key again")
+		require.Zero(t, other.Count(), "Should empty the other side")
+		requireUniqueIDs(t, merged)
+
+		for id, key := range ids {
+			found, _, ok := merged.GetByID(id)
+			require.True(t, ok, "Record %d should keep its ID", id)
+			require.Equal(t, key, found, "Record %d should keep its key", id)
+		}
+
+		// New records carry on from the IDs of both sides
+		for i := 0; i < 100; i++ {
+			merged.Insert(rnd.Intn(500), 0)
+		}
+		requireUniqueIDs(t, merged)
+	}
+}
+
+// TestMergeOverlapping merges trees whose keys interleave, which falls back to
+// inserting records one at a time.
+func TestMergeOverlapping(t *testing.T) {
+	for _, order := range []int{2, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order)))
+			target, model := buildRandom(t, rnd, order, 300, 0, 200)
+			other, otherModel := buildRandom(t, rnd, order, 300, 100, 300)
+
+			target.Merge(other)
+			target.(collections.Diagnosable).CheckConsistency()
+
+			model = append(model, otherModel...)
+			slices.Sort(model)
+			require.Equal(t, model, collectKeys(target), "Should hold the keys of both")
+			require.Zero(t, other.Count(), "Should empty the other tree")
+			requireUniqueIDs(t, target)
+		})
+	}
+}
+
+// TestMergeUniqueKeys checks values of the other tree win where keys collide, and that
+// a multimap is never grafted whole onto a tree with unique keys.
+func TestMergeUniqueKeys(t *testing.T) {
+	target, err := New[int, string](3, DefaultTestPreAlloc, WithUniqueKeys())
+	require.NoError(t, err, "Should be able to initialize")
+	other, err := New[int, string](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 50; i++ {
+		target.Insert(i, "target")
+		other.Insert(i+100, "other")
+		other.Insert(i+100, "other again")
+	}
+	other.Insert(10, "other")
+
+	target.Merge(other)
+	target.(collections.Diagnosable).CheckConsistency()
+	require.Equal(t, 100, target.Count(), "Should hold each key once")
+	value, _ := target.Get(10)
+	require.Equal(t, "other", value, "Should take the value of the other tree")
+	value, _ = target.Get(120)
+	require.Equal(t, "other again", value, "Should take the latest value for a key")
+}
+
+// TestMergeCopies merges from a snapshot and a tree of another order, which are
+// copied record by record.
+func TestMergeCopies(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	target, model := buildRandom(t, rnd, 4, 200, 0, 100)
+	other, otherModel := buildRandom(t, rnd, 7, 200, 100, 200)
+	snapshot := target.Snapshot()
+
+	target.Merge(snapshot)
+	target.(collections.Diagnosable).CheckConsistency()
+	require.Equal(t, 400, target.Count(), "Should copy the snapshot")
+	require.Equal(t, model, collectKeys(snapshot), "Should leave the snapshot alone")
+
+	target.Merge(other)
+	target.(collections.Diagnosable).CheckConsistency()
+	require.Equal(t, 600, target.Count(), "Should copy the tree of another order")
+	require.Equal(t, otherModel, collectKeys(target)[400:], "Should place its records")
+	require.Zero(t, other.Count(), "Should empty the tree of another order")
+
+	target.Merge(target)
+	require.Equal(t, 600, target.Count(), "Merging with ourselves should change nothing")
+	requireUniqueIDs(t, target)
+}
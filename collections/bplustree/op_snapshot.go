@@ -0,0 +1,105 @@
+package bplustree
+
+import (
+	"sync/atomic"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// generations hands out snapshot generations. They are unique across every tree, as
+// splits and merges move nodes between trees, and a node must never be mistaken for
+// one belonging to the tree it has landed in.
+var generations atomic.Uint64
+
+// nextGeneration gets an unused generation
+func nextGeneration() uint64 {
+	return generations.Add(1)
+}
+
+// Snapshot takes a point-in-time, read-only copy of the tree in constant time. Rather
+// than copying the nodes up front, we freeze them: every node created before the
+// snapshot belongs to an older generation, and a write that needs to change one
+// copies it - along with its path back to the root - first. The snapshot keeps the
+// old root, so sees none of the copies.
+//
+// Snapshots navigate from the root down and never use the sibling or parent links, as
+// those are rewired for the live tree as nodes are copied. The write lock is only held
+// while the snapshot is taken, so reading a snapshot never blocks writers, and vice
+// versa.
+func (t *tree[K, V]) Snapshot() collections.TreeMap[K, V] {
+	t.lockExclusive()
+
+	// Frozen nodes are never written to again, so their aggregates must be up to date
+	if t.aggregates != nil && t.Root != nil {
+		t.readView().summarize(t.Root)
+	}
+
+	result := &snapshot[K, V]{
+		view:       t.readView(),
+		uniqueKeys: t.UniqueKeys,
+	}
+	t.Generation = nextGeneration()
+
+	t.lock.Unlock()
+	return result
+}
+
+// frozen tells us if a node may be shared with a snapshot, and so must not have its
+// slots changed.
+func (t *tree[K, V]) frozen(node *treeNode[K, V]) bool {
+	return node.Generation != t.Generation
+}
+
+// mutable gets a version of a node that can be written to, copying it and its frozen
+// ancestors if need be. The copy takes the place of the node in the live tree, so
+// callers must carry on with the result. As we copy from the top down, the ancestors
+// of a mutable node are always mutable too.
+func (t *tree[K, V]) mutable(node *treeNode[K, V]) *treeNode[K, V] {
+	if !t.frozen(node) {
+		return node
+	}
+
+	clone := t.createNode(node.Leaf)
+	copy(clone.Keys, node.Keys[0:node.Count])
+	if node.Leaf {
+		copy(clone.Records, node.Records[0:node.Count])
+	} else {
+		copy(clone.Children, node.Children[0:node.Count])
+		for _, child := range clone.Children[0:node.Count] {
+			child.Parent = clone
+		}
+	}
+	clone.Count = node.Count
+	clone.setSize(node.subtreeSize())
+	clone.Aggregate = node.Aggregate
+	clone.aggregated.Store(node.aggregated.Load())
+	clone.Annotation = node.Annotation
+
+	// Take the place of the node in its parent, which must be copied first
+	if node.Parent == nil {
+		t.Root = clone
+	} else {
+		parent := t.mutable(node.Parent)
+		parent.Children[parent.indexOf(node)] = clone
+		clone.Parent = parent
+	}
+
+	// Take the place of the node in its level
+	clone.PreviousSibling = node.PreviousSibling
+	clone.NextSibling = node.NextSibling
+	if clone.PreviousSibling != nil {
+		clone.PreviousSibling.NextSibling = clone
+	}
+	if clone.NextSibling != nil {
+		clone.NextSibling.PreviousSibling = clone
+	}
+
+	// The old node now belongs to snapshots alone. Anyone holding it as part of the
+	// live tree, such as a cursor, needs to know it has moved on.
+	node.Version++
+	node.Parent = nil
+	node.PreviousSibling = nil
+	node.NextSibling = nil
+
+	return clone
+}
@@ -0,0 +1,50 @@
+package bplustree
+
+import (
+	"slices"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// SplitAt moves the records of the tree into two new trees: those with keys less than
+// the key, and those with keys greater than or equal to it. Only the path down to the
+// key is rebuilt, with the subtrees either side of it moving across whole. The bigger
+// of the two trees takes over our record index, handing the entries of the other's
+// records across, so only the smaller side is visited. The left tree carries on our
+// generation, so the nodes it takes stay writable without being copied. The tree is
+// left empty, and its cursors are invalidated.
+func (t *tree[K, V]) SplitAt(key K) (collections.TreeMap[K, V], collections.TreeMap[K, V]) {
+	t.lockExclusive()
+	defer t.lock.Unlock()
+
+	left := t.emptyLike()
+	left.Generation = t.Generation
+	right := t.emptyLike()
+
+	leftPieces, rightPieces := t.cut(t.Root, func(k K) bool {
+		return t.compare(k, key) < 0
+	}, left, right)
+	slices.Reverse(rightPieces)
+
+	if root := left.joinPieces(leftPieces); root != nil {
+		left.Length = root.subtreeSize()
+	}
+	if root := right.joinPieces(rightPieces); root != nil {
+		right.Length = root.subtreeSize()
+	}
+
+	bigger, smaller := left, right
+	if left.Length < right.Length {
+		bigger, smaller = right, left
+	}
+	t.settleIndex()
+	bigger.index = t.index
+	for p := smaller.readView().first(); p.valid(); p.next() {
+		id := p.record().RecordID
+		smaller.index[id] = p.key()
+		delete(bigger.index, id)
+	}
+
+	t.reset()
+	return left, right
+}
@@ -0,0 +1,93 @@
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestSplitAt splits trees with duplicate keys at every kind of position, checking
+// both sides hold the right records with their IDs, and that a snapshot of the tree
+// taken beforehand is untouched.
+func TestSplitAt(t *testing.T) {
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order)))
+
+			for probe := -10; probe <= 310; probe += 7 {
+				tree, err := New[int, int](order, DefaultTestPreAlloc)
+				require.NoError(t, err, "Should be able to initialize")
+
+				ids := map[collections.RecordID]int{}
+				var model []int
+				for i := 0; i < 400; i++ {
+					k := rnd.Intn(300)
+					ids[tree.Insert(k, k)] = k
+					index, _ := slices.BinarySearch(model, k+1)
+					model = slices.Insert(model, index, k)
+				}
+				snapshot := tree.Snapshot()
+				c := tree.Seek(probe)
+
+				left, right := tree.SplitAt(probe)
+				left.(collections.Diagnosable).CheckConsistency()
+				right.(collections.Diagnosable).CheckConsistency()
+
+				split, _ := slices.BinarySearch(model, probe)
+				require.Equal(t, model[:split], collectKeys(left), "Left should hold the keys before %d", probe)
+				require.Equal(t, model[split:], collectKeys(right), "Right should hold the keys from %d", probe)
+				require.Equal(t, model[:split], cursorKeys(left), "Left should link its leaves")
+				require.Equal(t, model[split:], cursorKeys(right), "Right should link its leaves")
+				require.Equal(t, split, left.Count(), "Left should count its records")
+				require.Equal(t, len(model)-split, right.Count(), "Right should count its records")
+				require.Equal(t, len(model)-split, right.CountRange(-1, 300), "Right should size its subtrees")
+
+				for _, side := range []collections.TreeMap[int, int]{left, right} {
+					c := side.Seek(-1)
+					for c.Next() {
+						require.Equal(t, ids[c.RecordID()], c.Key(), "Should keep record IDs")
+						k, _, found := side.GetByID(c.RecordID())
+						require.True(t, found && k == c.Key(), "Should index the records it holds")
+					}
+				}
+
+				require.Zero(t, tree.Count(), "Should have emptied the tree")
+				require.False(t, c.Next(), "Cursors of the tree should stop")
+				require.ErrorIs(t, c.Err(), collections.ErrCursorInvalidated, "Cursors should be invalidated")
+				require.Equal(t, model, collectKeys(snapshot), "Snapshot should be untouched")
+
+				// Both sides carry on as trees in their own right
+				for i := 0; i < 100; i++ {
+					k := rnd.Intn(300)
+					id := left.Insert(k, k)
+					require.Greater(t, id, collections.RecordID(400), "New records should get new IDs")
+					right.Insert(k, k)
+					left.Delete(rnd.Intn(300))
+					right.Delete(rnd.Intn(300))
+				}
+				left.(collections.Diagnosable).CheckConsistency()
+				right.(collections.Diagnosable).CheckConsistency()
+				require.Equal(t, model, collectKeys(snapshot), "Snapshot should still be untouched")
+			}
+		})
+	}
+}
+
+// TestSplitAtEmpty splits an empty tree
+func TestSplitAtEmpty(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc, WithUniqueKeys())
+	require.NoError(t, err, "Should be able to initialize")
+
+	left, right := tree.SplitAt(5)
+	require.Zero(t, left.Count(), "Left should be empty")
+	require.Zero(t, right.Count(), "Right should be empty")
+
+	right.Insert(1, 1)
+	right.Insert(1, 2)
+	require.Equal(t, 1, right.Count(), "Should keep the unique keys option")
+}
@@ -0,0 +1,51 @@
+package bplustree
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Upsert replaces the value of the first record stored against a key, or inserts a new
+// record if there is none. Returns the ID of the record, and true if it was replaced.
+func (t *tree[K, V]) Upsert(key K, value V) (collections.RecordID, bool) {
+	t.lockExclusive()
+	recordID, replaced := t.upsertInternal(key, value)
+	t.lock.Unlock()
+
+	return recordID, replaced
+}
+
+// Update computes a new value for the first record stored against a key, or for a new
+// record if there is none. The function is called under the write lock, so must not
+// call back into the tree.
+func (t *tree[K, V]) Update(key K, fn func(old V, exists bool) V) collections.RecordID {
+	t.lockExclusive()
+
+	leaf, index := t.lowerBound(key)
+	if leaf != nil && t.compare(leaf.Keys[index], key) == 0 {
+		leaf = t.mutable(leaf)
+		existing := &leaf.Records[index]
+		existing.Value = fn(existing.Value, true)
+		t.invalidateAggregates(leaf)
+		recordID := existing.RecordID
+		t.lock.Unlock()
+		return recordID
+	}
+
+	var blank V
+	recordID := t.insertInternal(key, fn(blank, false))
+
+	t.lock.Unlock()
+	return recordID
+}
+
+// upsertInternal replaces the value of the first record for a key in place, or inserts
+// a new one. The caller must hold the write lock.
+func (t *tree[K, V]) upsertInternal(key K, value V) (collections.RecordID, bool) {
+	leaf, index := t.lowerBound(key)
+	if leaf != nil && t.compare(leaf.Keys[index], key) == 0 {
+		leaf = t.mutable(leaf)
+		leaf.Records[index].Value = value
+		t.invalidateAggregates(leaf)
+		return leaf.Records[index].RecordID, true
+	}
+
+	return t.insertInternal(key, value), false
+}
@@ -0,0 +1,99 @@
+package bplustree
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestUniqueKeysInsert checks inserts replace existing records in unique-key mode
+func TestUniqueKeysInsert(t *testing.T) {
+	tree, err := New[int, string](3, DefaultTestPreAlloc, WithUniqueKeys())
+	require.NoError(t, err, "Should be able to initialize")
+
+	ids := map[int]collections.RecordID{}
+	for i := 0; i < 100; i++ {
+		ids[i%10] = tree.Insert(i%10, "first")
+	}
+	require.Equal(t, 10, tree.Count(), "Should hold one record per key")
+
+	for i := 0; i < 10; i++ {
+		id := tree.Insert(i, "second")
+		require.Equal(t, ids[i], id, "Should keep the record ID of the existing record")
+		require.Equal(t, []string{"second"}, tree.GetAll(i), "Should have replaced the value")
+	}
+	tree.(collections.Diagnosable).CheckConsistency()
+}
+
+// TestUpsert checks upserts in both unique and multimap modes
+func TestUpsert(t *testing.T) {
+	t.Run("UniqueKeys", func(t *testing.T) {
+		tree, err := New[string, int](4, DefaultTestPreAlloc, WithUniqueKeys())
+		require.NoError(t, err, "Should be able to initialize")
+
+		id, replaced := tree.Upsert("selection", 1)
+		require.False(t, replaced, "Should insert a new key")
+
+		again, replaced := tree.Upsert("selection", 2)
+		require.True(t, replaced, "Should replace an existing key")
+		require.Equal(t, id, again, "Should keep the record ID")
+
+		value, _ := tree.Get("selection")
+		require.Equal(t, 2, value, "Should have the new value")
+		require.Equal(t, 1, tree.Count(), "Should have one record")
+	})
+
+	t.Run("Multimap", func(t *testing.T) {
+		tree, err := New[string, int](4, DefaultTestPreAlloc)
+		require.NoError(t, err, "Should be able to initialize")
+
+		tree.Insert("selection", 1)
+		tree.Insert("selection", 2)
+
+		_, replaced := tree.Upsert("selection", 3)
+		require.True(t, replaced, "Should replace an existing key")
+		require.Equal(t, []int{3, 2}, tree.GetAll("selection"), "Should replace only the first record")
+	})
+}
+
+// TestUpdate checks the update function sees existing values
+func TestUpdate(t *testing.T) {
+	tree, err := New[int, int](2, DefaultTestPreAlloc, WithUniqueKeys())
+	require.NoError(t, err, "Should be able to initialize")
+
+	increment := func(old int, exists bool) int {
+		if !exists {
+			require.Zero(t, old, "Should get the zero value for a new key")
+			return 1
+		}
+		return old + 1
+	}
+
+	for i := 0; i < 200; i++ {
+		tree.Update(i%20, increment)
+	}
+	tree.(collections.Diagnosable).CheckConsistency()
+
+	require.Equal(t, 20, tree.Count(), "Should hold one record per key")
+	for k, v := range tree.All() {
+		require.Equal(t, 10, v, "Key %d should have been updated every time", k)
+	}
+}
+
+// TestBuildSortedUniqueKeys checks bulk-loading rejects duplicates in unique-key mode
+func TestBuildSortedUniqueKeys(t *testing.T) {
+	input := []generics.KeyValuePair[int, int]{{Key: 1}, {Key: 2}, {Key: 2}}
+
+	_, err := BuildSorted(4, DefaultTestPreAlloc, 1, slices.Values(input), WithUniqueKeys())
+	require.ErrorContains(t, err, "duplicate", "Should reject duplicate keys")
+
+	tree, err := BuildSorted(4, DefaultTestPreAlloc, 1, slices.Values(input[0:2]), WithUniqueKeys())
+	require.NoError(t, err, "Should build without duplicates")
+
+	tree.Insert(2, 5)
+	require.Equal(t, 2, tree.Count(), "Should keep unique keys after loading")
+}
@@ -0,0 +1,50 @@
+package bplustree
+
+// Option configures optional behaviour of a tree when it is constructed.
+type Option func(o *options)
+
+// options holds the optional behaviours selected at construction
+type options struct {
+	uniqueKeys bool
+	keyCodec   any // Codec of the key type, checked when the tree is created
+	valueCodec any // Codec of the value type, checked when the tree is created
+	aggregates any // Aggregator of the value type, checked when the tree is created
+}
+
+// WithUniqueKeys makes the tree hold at most one record per key, like a map. Inserting
+// a key that already exists replaces the value of its record. Without this option the
+// tree is a multimap, and every insert adds a new record.
+func WithUniqueKeys() Option {
+	return func(o *options) {
+		o.uniqueKeys = true
+	}
+}
+
+// WithCodecs sets how keys and values are converted to bytes, which is needed before
+// the tree can be written out with WriteTo. The codecs must match the key and value
+// types of the tree.
+func WithCodecs[K any, V any](keys Codec[K], values Codec[V]) Option {
+	return func(o *options) {
+		o.keyCodec = keys
+		o.valueCodec = values
+	}
+}
+
+// WithAggregate keeps an aggregate of the values beneath every node, so that Aggregate
+// can reduce a range by combining whole subtrees rather than visiting every record.
+// Combining the identity with a value must give back the value, and combine must be
+// associative. It need not be commutative, as values are always combined in key order.
+//
+// Keeping the aggregates up to date means inserts take the tree to themselves rather
+// than running concurrently. Trees built with the same option can be merged whole;
+// otherwise merging falls back to inserting one record at a time.
+func WithAggregate[V any](identity V, combine func(a, b V) V) Option {
+	aggregates := &aggregator[V]{
+		identity: identity,
+		combine:  combine,
+	}
+
+	return func(o *options) {
+		o.aggregates = aggregates
+	}
+}
@@ -13,5 +13,11 @@ package bplustree
 
 		BenchmarkInsertsSequentialFixedLarge-12     38         305604530 ns/op        173427506 B/op    212506 allocs/op
 
-	This means that the tree can accommodate approximately ~10 million inserts/second.
+	This means that the tree can accommodate approximately ~8 million inserts/second.
+
+	Scanning originally pushed every record through an unbuffered channel. Moving to
+	range-over-func iterators made a full scan of 100,000 records ~30x faster:
+
+		BenchmarkScanChannel        200          37610541 ns/op
+		BenchmarkScanIterator       200           1195375 ns/op
  **/
@@ -0,0 +1,110 @@
+//go:build perfanalysis
+
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+var writerCounts = []int{1, 2, 4, 8}
+
+// BenchmarkConcurrentRandomInserts checks how random insert throughput scales as we
+// add writers, with the same total number of inserts shared between them.
+func BenchmarkConcurrentRandomInserts(b *testing.B) {
+	for _, writers := range writerCounts {
+		b.Run(fmt.Sprintf("Writers_%d", writers), func(b *testing.B) {
+			benchmarkConcurrentInsertsInternal(b, 27, 500000, writers, func(rnd *rand.Rand, i int) int {
+				return int(rnd.Int31n(1000000))
+			})
+		})
+	}
+}
+
+// BenchmarkConcurrentSequentialInserts checks insert throughput as we add writers each
+// appending to their own band of keys.
+func BenchmarkConcurrentSequentialInserts(b *testing.B) {
+	for _, writers := range writerCounts {
+		b.Run(fmt.Sprintf("Writers_%d", writers), func(b *testing.B) {
+			benchmarkConcurrentInsertsInternal(b, 27, 500000, writers, func(rnd *rand.Rand, i int) int {
+				return i
+			})
+		})
+	}
+}
+
+// BenchmarkConcurrentInsertsWithReaders checks insert throughput with as many readers
+// as writers running Get against the tree.
+func BenchmarkConcurrentInsertsWithReaders(b *testing.B) {
+	for _, writers := range writerCounts {
+		b.Run(fmt.Sprintf("Writers_%d", writers), func(b *testing.B) {
+			for b.Loop() {
+				tree, err := New[int, int](27, DefaultTestPreAlloc)
+				if err != nil {
+					b.Logf("Error: %v", err)
+					b.FailNow()
+				}
+
+				done := make(chan struct{})
+				var readers sync.WaitGroup
+				for r := 0; r < writers; r++ {
+					readers.Add(1)
+					go func(r int) {
+						defer readers.Done()
+						rnd := rand.New(rand.NewSource(int64(r)))
+						for {
+							select {
+							case <-done:
+								return
+							default:
+								tree.Get(int(rnd.Int31n(1000000)))
+							}
+						}
+					}(r)
+				}
+
+				insertConcurrently(tree.Insert, 500000, writers, func(rnd *rand.Rand, i int) int {
+					return int(rnd.Int31n(1000000))
+				})
+
+				close(done)
+				readers.Wait()
+			}
+		})
+	}
+}
+
+func benchmarkConcurrentInsertsInternal(b *testing.B, order int, dataSize int, writers int, keyFn func(rnd *rand.Rand, i int) int) {
+	for b.Loop() {
+		tree, err := New[int, int](order, DefaultTestPreAlloc)
+		if err != nil {
+			b.Logf("Error: %v", err)
+			b.FailNow()
+		}
+
+		insertConcurrently(tree.Insert, dataSize, writers, keyFn)
+	}
+}
+
+// insertConcurrently shares the inserts between the writers, with each writer taking
+// its own band of sequence numbers.
+func insertConcurrently(insert func(k int, v int) collections.RecordID, dataSize int, writers int, keyFn func(rnd *rand.Rand, i int) int) {
+	perWriter := dataSize / writers
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(int64(w)))
+			for i := w * perWriter; i < (w+1)*perWriter; i++ {
+				insert(keyFn(rnd, i), i)
+			}
+		}(w)
+	}
+	wg.Wait()
+}
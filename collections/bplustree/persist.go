@@ -0,0 +1,436 @@
+package bplustree
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// The persisted format is a fixed-size header, followed by one page per node. Pages are
+// numbered breadth-first from the root, so the children of each internal node are the
+// next unclaimed pages and need not be stored. Every page carries its own checksum, so
+// damage is caught before we act on it.
+//
+//	Header:  magic [8]byte, version uint32, flags uint32, order uint32,
+//	         record counter int64, length int64, page count int64, checksum uint32
+//	Page:    payload length uint32, payload checksum uint32, payload
+//	Payload: kind byte, count uvarint, then per entry:
+//	         leaf:     record ID uvarint, key length uvarint, key, value length uvarint, value
+//	         internal: key length uvarint, key
+//
+// All fixed-size integers are little-endian.
+const (
+	formatMagic      = "ZFBPTREE"
+	formatVersion    = 1
+	formatHeaderSize = 8 + 4 + 4 + 4 + 8 + 8 + 8 + 4
+
+	formatFlagUniqueKeys = 1 << 0
+
+	pageKindLeaf     = 1
+	pageKindInternal = 2
+)
+
+// ErrInvalidFormat indicates data being loaded is not a tree written by WriteTo, or has
+// been corrupted or truncated.
+var ErrInvalidFormat = errors.New("the data is not a valid tree, or is corrupt or truncated")
+
+// WriteTo writes the tree out in a versioned binary format that Load can read back,
+// keeping the record IDs. The tree must have been created with WithCodecs. Writes are
+// blocked until the write completes.
+func (t *tree[K, V]) WriteTo(w io.Writer) (int64, error) {
+	if t.keyCodec == nil || t.valueCodec == nil {
+		return 0, fmt.Errorf("no codecs configured: create the tree with WithCodecs")
+	}
+
+	t.lockQuiescent()
+	defer t.unlockQuiescent()
+
+	// Number the pages breadth-first
+	var pages []*treeNode[K, V]
+	if t.Root != nil {
+		pages = append(pages, t.Root)
+	}
+	for i := 0; i < len(pages); i++ {
+		if !pages[i].Leaf {
+			pages = append(pages, pages[i].Children[0:pages[i].Count]...)
+		}
+	}
+
+	var flags uint32
+	if t.UniqueKeys {
+		flags |= formatFlagUniqueKeys
+	}
+
+	header := make([]byte, 0, formatHeaderSize)
+	header = append(header, formatMagic...)
+	header = binary.LittleEndian.AppendUint32(header, formatVersion)
+	header = binary.LittleEndian.AppendUint32(header, flags)
+	header = binary.LittleEndian.AppendUint32(header, uint32(t.Order))
+	header = binary.LittleEndian.AppendUint64(header, uint64(t.RecordCount))
+	header = binary.LittleEndian.AppendUint64(header, uint64(t.length()))
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(pages)))
+	header = binary.LittleEndian.AppendUint32(header, crc32.ChecksumIEEE(header))
+
+	written, err := w.Write(header)
+	total := int64(written)
+	if err != nil {
+		return total, err
+	}
+
+	var payload []byte
+	for _, page := range pages {
+		payload, err = t.appendPage(payload[:0], page)
+		if err != nil {
+			return total, err
+		}
+
+		var pageHeader [8]byte
+		binary.LittleEndian.PutUint32(pageHeader[0:4], uint32(len(payload)))
+		binary.LittleEndian.PutUint32(pageHeader[4:8], crc32.ChecksumIEEE(payload))
+
+		written, err = w.Write(pageHeader[:])
+		total += int64(written)
+		if err != nil {
+			return total, err
+		}
+
+		written, err = w.Write(payload)
+		total += int64(written)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// appendPage encodes the payload of a node onto a buffer
+func (t *tree[K, V]) appendPage(dst []byte, node *treeNode[K, V]) ([]byte, error) {
+	kind := byte(pageKindInternal)
+	if node.Leaf {
+		kind = pageKindLeaf
+	}
+	dst = append(dst, kind)
+	dst = binary.AppendUvarint(dst, uint64(node.Count))
+
+	for i := 0; i < node.Count; i++ {
+		if node.Leaf {
+			dst = binary.AppendUvarint(dst, uint64(node.Records[i].RecordID))
+		}
+
+		key, err := t.keyCodec.Encode(node.Keys[i])
+		if err != nil {
+			return dst, fmt.Errorf("failed to encode key %v: %w", node.Keys[i], err)
+		}
+		dst = binary.AppendUvarint(dst, uint64(len(key)))
+		dst = append(dst, key...)
+
+		if node.Leaf {
+			value, err := t.valueCodec.Encode(node.Records[i].Value)
+			if err != nil {
+				return dst, fmt.Errorf("failed to encode value of key %v: %w", node.Keys[i], err)
+			}
+			dst = binary.AppendUvarint(dst, uint64(len(value)))
+			dst = append(dst, value...)
+		}
+	}
+
+	return dst, nil
+}
+
+// Load reads back a tree written by WriteTo. The order, key mode and record IDs come
+// from the data, and the codecs are kept so the tree can be written out again.
+func Load[K generics.Comparable, V any](r io.Reader, preallocateSize int, keys Codec[K], values Codec[V], opts ...Option) (collections.TreeMap[K, V], error) {
+	return LoadFunc(r, preallocateSize, cmp.Compare[K], keys, values, opts...)
+}
+
+// LoadFunc reads back a tree written by WriteTo, as per Load, with keys ordered by a
+// comparator. This must order keys the same way as the tree that was written.
+func LoadFunc[K any, V any](r io.Reader, preallocateSize int, compare func(a, b K) int, keys Codec[K], values Codec[V], opts ...Option) (collections.TreeMap[K, V], error) {
+	header := make([]byte, formatHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: failed to read header: %w", ErrInvalidFormat, err)
+	}
+
+	if string(header[0:8]) != formatMagic {
+		return nil, fmt.Errorf("%w: bad magic number", ErrInvalidFormat)
+	}
+	checksum := binary.LittleEndian.Uint32(header[formatHeaderSize-4:])
+	if crc32.ChecksumIEEE(header[0:formatHeaderSize-4]) != checksum {
+		return nil, fmt.Errorf("%w: header checksum mismatch", ErrInvalidFormat)
+	}
+
+	version := binary.LittleEndian.Uint32(header[8:12])
+	flags := binary.LittleEndian.Uint32(header[12:16])
+	order := binary.LittleEndian.Uint32(header[16:20])
+	recordCounter := int64(binary.LittleEndian.Uint64(header[20:28]))
+	length := int64(binary.LittleEndian.Uint64(header[28:36]))
+	pageCount := int64(binary.LittleEndian.Uint64(header[36:44]))
+
+	if version != formatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidFormat, version)
+	} else if recordCounter < 0 || length < 0 || pageCount < 0 || length > recordCounter {
+		return nil, fmt.Errorf("%w: bad record or page counts", ErrInvalidFormat)
+	} else if (length == 0) != (pageCount == 0) {
+		return nil, fmt.Errorf("%w: %d records in %d pages", ErrInvalidFormat, length, pageCount)
+	} else if order < MinTreeOrder || order > MaxTreeOrder {
+		// Checked before the tree is created, as every node is allocated at full order
+		return nil, fmt.Errorf("%w: order %d is outside %d to %d", ErrInvalidFormat, order, MinTreeOrder, MaxTreeOrder)
+	}
+
+	opts = append(opts, WithCodecs(keys, values))
+	if flags&formatFlagUniqueKeys != 0 {
+		opts = append(opts, WithUniqueKeys())
+	}
+
+	created, err := NewFunc[K, V](int(order), preallocateSize, compare, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFormat, err)
+	}
+	t := created.(*tree[K, V])
+
+	// Read the pages. We can't trust the page count to size anything up front, so
+	// we grow as the pages actually arrive.
+	var pages []*treeNode[K, V]
+	for i := int64(0); i < pageCount; i++ {
+		payload, err := readPage(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: page %d: %w", ErrInvalidFormat, i, err)
+		}
+
+		node, err := t.decodePage(payload, recordCounter)
+		if err != nil {
+			return nil, fmt.Errorf("%w: page %d: %w", ErrInvalidFormat, i, err)
+		}
+		pages = append(pages, node)
+	}
+
+	if err := t.linkPages(pages); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFormat, err)
+	}
+
+	t.RecordCount = collections.RecordID(recordCounter)
+	t.Length = int(length)
+	if len(pages) > 0 {
+		t.Root = pages[0]
+		if t.Root.subtreeSize() != t.Length {
+			return nil, fmt.Errorf("%w: header promises %d records, pages hold %d", ErrInvalidFormat, t.Length, t.Root.subtreeSize())
+		}
+	}
+
+	if problems := t.validate(); len(problems) > 0 {
+		return nil, fmt.Errorf("%w: consistency check failed: %w", ErrInvalidFormat, errors.Join(problems...))
+	}
+
+	return t, nil
+}
+
+// readPage reads the next page and verifies its checksum
+func readPage(r io.Reader) ([]byte, error) {
+	var pageHeader [8]byte
+	if _, err := io.ReadFull(r, pageHeader[:]); err != nil {
+		return nil, noEOF(err)
+	}
+	size := int64(binary.LittleEndian.Uint32(pageHeader[0:4]))
+	checksum := binary.LittleEndian.Uint32(pageHeader[4:8])
+
+	// Copy rather than allocating the size we were told, which may be garbage
+	var payload bytes.Buffer
+	if _, err := io.CopyN(&payload, r, size); err != nil {
+		return nil, noEOF(err)
+	}
+
+	if crc32.ChecksumIEEE(payload.Bytes()) != checksum {
+		return nil, fmt.Errorf("checksum mismatch")
+	}
+
+	return payload.Bytes(), nil
+}
+
+// noEOF converts a clean end of input, which is only expected at the end of the tree,
+// into an unexpected one.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// decodePage builds a node from the payload of a page. The children of internal nodes
+// are filled in later, by linkPages.
+func (t *tree[K, V]) decodePage(payload []byte, recordCounter int64) (*treeNode[K, V], error) {
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("empty page")
+	}
+
+	kind := payload[0]
+	if kind != pageKindLeaf && kind != pageKindInternal {
+		return nil, fmt.Errorf("unknown page kind %d", kind)
+	}
+
+	d := pageDecoder{data: payload[1:]}
+	count := d.uvarint()
+	if d.err == nil && (count < 1 || count > uint64(t.Order)) {
+		return nil, fmt.Errorf("count %d is outside the order %d", count, t.Order)
+	}
+
+	node := t.createNode(kind == pageKindLeaf)
+	for i := 0; i < int(count) && d.err == nil; i++ {
+		if node.Leaf {
+			recordID := d.uvarint()
+			if d.err == nil && (recordID == 0 || recordID > uint64(recordCounter)) {
+				return nil, fmt.Errorf("record ID %d is outside the record counter %d", recordID, recordCounter)
+			}
+			node.Records[i].RecordID = collections.RecordID(recordID)
+		}
+
+		keyData := d.bytes()
+		if d.err != nil {
+			break
+		}
+		key, err := t.keyCodec.Decode(keyData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key: %w", err)
+		}
+		node.Keys[i] = key
+
+		// Records are indexed as they arrive, so an ID seen twice is caught here
+		if node.Leaf {
+			recordID := node.Records[i].RecordID
+			if _, repeated := t.index[recordID]; repeated {
+				return nil, fmt.Errorf("record ID %d is repeated", recordID)
+			}
+			t.index[recordID] = key
+		}
+
+		if node.Leaf {
+			valueData := d.bytes()
+			if d.err != nil {
+				break
+			}
+			value, err := t.valueCodec.Decode(valueData)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode value of key %v: %w", key, err)
+			}
+			node.Records[i].Value = value
+		}
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	} else if len(d.data) > 0 {
+		return nil, fmt.Errorf("%d bytes of trailing data", len(d.data))
+	}
+
+	node.Count = int(count)
+	return node, nil
+}
+
+// linkPages wires up the pages read from the input. Children are claimed breadth-first,
+// the same order they were written in, so each level of the tree is a contiguous run
+// of pages and siblings sit next to each other.
+func (t *tree[K, V]) linkPages(pages []*treeNode[K, V]) error {
+	depths := make([]int, len(pages))
+	nextChild := 1
+	for i, node := range pages {
+		if node.Leaf {
+			continue
+		}
+
+		for c := 0; c < node.Count; c++ {
+			if nextChild >= len(pages) {
+				return fmt.Errorf("page %d has children beyond the last page", i)
+			}
+
+			child := pages[nextChild]
+			node.Children[c] = child
+			child.Parent = node
+			depths[nextChild] = depths[i] + 1
+			nextChild++
+		}
+	}
+
+	if nextChild < len(pages) {
+		return fmt.Errorf("%d pages do not belong to the tree", len(pages)-nextChild)
+	}
+
+	leafDepth := -1
+	for i, node := range pages {
+		if node.Leaf {
+			if leafDepth >= 0 && depths[i] != leafDepth {
+				return fmt.Errorf("page %d is a leaf at depth %d, expected %d", i, depths[i], leafDepth)
+			}
+			leafDepth = depths[i]
+		}
+
+		if i > 0 && depths[i] == depths[i-1] {
+			node.PreviousSibling = pages[i-1]
+			pages[i-1].NextSibling = node
+		}
+	}
+
+	// Children follow their parents, so working backwards sizes every child first
+	for i := len(pages) - 1; i >= 0; i-- {
+		pages[i].recomputeSize()
+	}
+
+	// Duplicate keys would otherwise only show up as surprising reads later
+	if t.UniqueKeys && len(pages) > 0 {
+		var previous *K
+		pageView := view[K, V]{root: pages[0], compare: t.compare}
+		for p := pageView.first(); p.valid(); p.next() {
+			key := p.key()
+			if previous != nil && t.compare(*previous, key) == 0 {
+				p.clear()
+				return fmt.Errorf("duplicate key %v in a tree with unique keys", key)
+			}
+			previous = &key
+		}
+	}
+
+	return nil
+}
+
+// pageDecoder reads the fields of a page payload, remembering the first failure
+type pageDecoder struct {
+	data []byte
+	err  error
+}
+
+// uvarint reads a variable-length unsigned integer
+func (d *pageDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	value, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = fmt.Errorf("bad variable-length integer")
+		return 0
+	}
+
+	d.data = d.data[n:]
+	return value
+}
+
+// bytes reads a length-prefixed run of bytes
+func (d *pageDecoder) bytes() []byte {
+	size := d.uvarint()
+	if d.err != nil {
+		return nil
+	} else if size > uint64(len(d.data)) {
+		d.err = fmt.Errorf("field of %d bytes overruns the page", size)
+		return nil
+	}
+
+	result := d.data[0:size]
+	d.data = d.data[size:]
+	return result
+}
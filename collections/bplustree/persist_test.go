@@ -0,0 +1,254 @@
+package bplustree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// persistedRecord is a record as seen through a cursor, including its ID
+type persistedRecord struct {
+	Key      int64
+	Value    string
+	RecordID collections.RecordID
+}
+
+// recordsOf reads every record of a tree, with its ID
+func recordsOf(tree collections.TreeMap[int64, string]) []persistedRecord {
+	var result []persistedRecord
+	c := tree.Seek(-1 << 62)
+	for c.Next() {
+		result = append(result, persistedRecord{
+			Key:      c.Key(),
+			Value:    c.Value(),
+			RecordID: c.RecordID(),
+		})
+	}
+
+	return result
+}
+
+// writeTree writes a tree out to a buffer
+func writeTree(t *testing.T, tree any) []byte {
+	var buffer bytes.Buffer
+	written, err := tree.(io.WriterTo).WriteTo(&buffer)
+	require.NoError(t, err, "Should be able to write the tree")
+	require.Equal(t, int64(buffer.Len()), written, "Should report the bytes written")
+
+	return buffer.Bytes()
+}
+
+// TestPersistRoundTrip writes trees out and reads them back, at a range of orders
+func TestPersistRoundTrip(t *testing.T) {
+	codecs := WithCodecs[int64, string](BinaryCodec[int64]{}, StringCodec{})
+
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order)))
+			tree, err := New[int64, string](order, DefaultTestPreAlloc, codecs)
+			require.NoError(t, err, "Should be able to initialize")
+
+			for i := 0; i < 1000; i++ {
+				k := rnd.Int63n(300)
+				if rnd.Float64() < 0.7 {
+					tree.Insert(k, fmt.Sprintf("value-%d-%d", k, i))
+				} else {
+					tree.Delete(k)
+				}
+			}
+
+			data := writeTree(t, tree)
+			loaded, err := Load[int64, string](bytes.NewReader(data), DefaultTestPreAlloc, BinaryCodec[int64]{}, StringCodec{})
+			require.NoError(t, err, "Should be able to load the tree")
+
+			require.Equal(t, tree.Count(), loaded.Count(), "Should have the same count")
+			require.Equal(t, recordsOf(tree), recordsOf(loaded), "Should have the same records and IDs")
+			require.Equal(t, data, writeTree(t, loaded), "Should write out the same bytes again")
+
+			// Carry on where we left off
+			id := loaded.Insert(150, "after-load")
+			require.Equal(t, tree.Insert(150, "after-load"), id, "Should carry on the record counter")
+			loaded.(collections.Diagnosable).CheckConsistency()
+		})
+	}
+}
+
+// TestPersistUniqueKeys checks the key mode survives a round trip
+func TestPersistUniqueKeys(t *testing.T) {
+	type location struct {
+		Name string
+		X, Y int
+	}
+
+	tree, err := New[string, location](4, DefaultTestPreAlloc, WithUniqueKeys(), WithCodecs[string, location](StringCodec{}, JSONCodec[location]{}))
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("site-%02d", i)
+		tree.Insert(name, location{Name: name, X: i, Y: -i})
+	}
+
+	loaded, err := Load[string, location](bytes.NewReader(writeTree(t, tree)), DefaultTestPreAlloc, StringCodec{}, JSONCodec[location]{})
+	require.NoError(t, err, "Should be able to load the tree")
+
+	value, found := loaded.Get("site-17")
+	require.True(t, found, "Should find a key")
+	require.Equal(t, location{Name: "site-17", X: 17, Y: -17}, value, "Should have the right value")
+
+	loaded.Insert("site-17", location{Name: "moved"})
+	require.Equal(t, 50, loaded.Count(), "Should still have unique keys")
+}
+
+// TestPersistEmpty checks an empty tree round trips
+func TestPersistEmpty(t *testing.T) {
+	tree, err := New[int64, string](4, DefaultTestPreAlloc, WithCodecs[int64, string](BinaryCodec[int64]{}, StringCodec{}))
+	require.NoError(t, err, "Should be able to initialize")
+
+	loaded, err := Load[int64, string](bytes.NewReader(writeTree(t, tree)), DefaultTestPreAlloc, BinaryCodec[int64]{}, StringCodec{})
+	require.NoError(t, err, "Should be able to load the tree")
+	require.Zero(t, loaded.Count(), "Should be empty")
+
+	loaded.Insert(1, "one")
+	require.Equal(t, 1, loaded.Count(), "Should take inserts")
+}
+
+// TestPersistCodecs checks trees without matching codecs are refused
+func TestPersistCodecs(t *testing.T) {
+	tree, err := New[int64, string](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	_, err = tree.(io.WriterTo).WriteTo(&bytes.Buffer{})
+	require.Error(t, err, "Should not write without codecs")
+
+	_, err = New[int64, string](4, DefaultTestPreAlloc, WithCodecs[string, string](StringCodec{}, StringCodec{}))
+	require.Error(t, err, "Should not accept a codec of the wrong key type")
+
+	_, err = New[int64, string](4, DefaultTestPreAlloc, WithCodecs[int64, int64](BinaryCodec[int64]{}, BinaryCodec[int64]{}))
+	require.Error(t, err, "Should not accept a codec of the wrong value type")
+
+	_, err = New[int, string](4, DefaultTestPreAlloc, WithCodecs[int, string](BinaryCodec[int]{}, StringCodec{}))
+	require.NoError(t, err, "Should accept the codecs")
+}
+
+// persistedSample writes out a small multi-level tree for damaging
+func persistedSample(t *testing.T) []byte {
+	tree, err := New[int64, string](3, DefaultTestPreAlloc, WithCodecs[int64, string](BinaryCodec[int64]{}, StringCodec{}))
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := int64(0); i < 40; i++ {
+		tree.Insert(i, fmt.Sprintf("value-%d", i))
+	}
+
+	return writeTree(t, tree)
+}
+
+// TestPersistTruncated checks every truncation of the data is rejected
+func TestPersistTruncated(t *testing.T) {
+	data := persistedSample(t)
+
+	for size := 0; size < len(data); size++ {
+		_, err := Load[int64, string](bytes.NewReader(data[0:size]), DefaultTestPreAlloc, BinaryCodec[int64]{}, StringCodec{})
+		require.ErrorIs(t, err, ErrInvalidFormat, "Should reject data truncated to %d bytes", size)
+	}
+}
+
+// TestPersistCorrupted checks damage to any byte of the data is rejected
+func TestPersistCorrupted(t *testing.T) {
+	data := persistedSample(t)
+
+	for offset := 0; offset < len(data); offset++ {
+		damaged := bytes.Clone(data)
+		damaged[offset] ^= 0x5a
+
+		_, err := Load[int64, string](bytes.NewReader(damaged), DefaultTestPreAlloc, BinaryCodec[int64]{}, StringCodec{})
+		require.ErrorIs(t, err, ErrInvalidFormat, "Should reject damage at offset %d", offset)
+	}
+}
+
+// TestPersistVersion checks data from an unknown version of the format is rejected,
+// even when it is otherwise intact.
+func TestPersistVersion(t *testing.T) {
+	data := persistedSample(t)
+	binary.LittleEndian.PutUint32(data[8:12], formatVersion+1)
+	binary.LittleEndian.PutUint32(data[formatHeaderSize-4:], crc32.ChecksumIEEE(data[0:formatHeaderSize-4]))
+
+	_, err := Load[int64, string](bytes.NewReader(data), DefaultTestPreAlloc, BinaryCodec[int64]{}, StringCodec{})
+	require.ErrorIs(t, err, ErrInvalidFormat, "Should reject an unknown version")
+	require.ErrorContains(t, err, "unsupported format version", "Should say why")
+}
+
+// TestPersistStructuralDamage checks intact pages that don't form a valid tree are
+// rejected, by swapping the keys of two leaves and fixing up the checksums.
+func TestPersistStructuralDamage(t *testing.T) {
+	tree, err := New[int64, string](4, DefaultTestPreAlloc, WithCodecs[int64, string](BinaryCodec[int64]{}, StringCodec{}))
+	require.NoError(t, err, "Should be able to initialize")
+	for i := int64(0); i < 4; i++ {
+		tree.Insert(i, "x")
+	}
+
+	// A single leaf, so we can rewrite its payload freely
+	data := writeTree(t, tree)
+	payload := data[formatHeaderSize+8:]
+	first := bytes.Index(payload, []byte{1, 0, 0, 0, 0, 0, 0, 0})
+	second := bytes.Index(payload, []byte{2, 0, 0, 0, 0, 0, 0, 0})
+	require.Positive(t, first, "Should find the first key")
+	require.Positive(t, second, "Should find the second key")
+	payload[first], payload[second] = 2, 1
+	binary.LittleEndian.PutUint32(data[formatHeaderSize+4:], crc32.ChecksumIEEE(payload))
+
+	_, err = Load[int64, string](bytes.NewReader(data), DefaultTestPreAlloc, BinaryCodec[int64]{}, StringCodec{})
+	require.ErrorIs(t, err, ErrInvalidFormat, "Should reject keys out of order")
+	require.ErrorContains(t, err, "consistency check failed", "Should be caught by the consistency checks")
+
+	var invariant *InvariantError
+	require.ErrorAs(t, err, &invariant, "Should say which invariant failed")
+	require.Equal(t, "ORDER", invariant.Tag, "Should report the keys out of order")
+}
+
+// TestPersistOrder checks an intact header with an order New would refuse is rejected
+// before any nodes are allocated
+func TestPersistOrder(t *testing.T) {
+	for _, order := range []uint32{0, 1, MaxTreeOrder + 1, 1<<32 - 1} {
+		data := persistedSample(t)
+		binary.LittleEndian.PutUint32(data[16:20], order)
+		binary.LittleEndian.PutUint32(data[formatHeaderSize-4:], crc32.ChecksumIEEE(data[0:formatHeaderSize-4]))
+
+		_, err := Load[int64, string](bytes.NewReader(data), DefaultTestPreAlloc, BinaryCodec[int64]{}, StringCodec{})
+		require.ErrorIs(t, err, ErrInvalidFormat, "Should reject order %d", order)
+		require.ErrorContains(t, err, "outside", "Should say why order %d was rejected", order)
+	}
+
+	_, err := New[int, int](MaxTreeOrder+1, DefaultTestPreAlloc)
+	require.Error(t, err, "Should not create a tree beyond the largest order")
+}
+
+// TestPersistRepeatedRecordID checks intact pages holding the same record ID twice are
+// rejected as corrupt
+func TestPersistRepeatedRecordID(t *testing.T) {
+	created, err := New[int64, string](3, DefaultTestPreAlloc, WithCodecs[int64, string](BinaryCodec[int64]{}, StringCodec{}))
+	require.NoError(t, err, "Should be able to initialize")
+	for i := int64(0); i < 10; i++ {
+		created.Insert(i, "x")
+	}
+
+	// Give the last record the ID of the first, so they are written to different pages
+	tree := created.(*tree[int64, string])
+	first := tree.firstLeaf()
+	last := first
+	for last.NextSibling != nil {
+		last = last.NextSibling
+	}
+	last.Records[last.Count-1].RecordID = first.Records[0].RecordID
+
+	_, err = Load[int64, string](bytes.NewReader(writeTree(t, tree)), DefaultTestPreAlloc, BinaryCodec[int64]{}, StringCodec{})
+	require.ErrorIs(t, err, ErrInvalidFormat, "Should reject a repeated record ID")
+	require.ErrorContains(t, err, "is repeated", "Should be caught as the pages are read")
+}
@@ -0,0 +1,238 @@
+package bplustree
+
+// position is a slot within the tree, recorded as the slot taken at each level on the
+// way down from the root to a leaf. Unlike the sibling links, moving a position only
+// reads the slots of the nodes, which never change once a node is shared with a
+// snapshot. An empty position is past the last record.
+//
+// Positions within the live tree are latched: every node on the path is held with a
+// shared latch, so that concurrent inserts can't restructure the path beneath us. A
+// latched position must be cleared once finished with, and a goroutine must not hold
+// two at once, or it could deadlock against a writer queued between them.
+type position[K any, V any] struct {
+	nodes   []*treeNode[K, V] // Nodes from the root down to a leaf
+	indexes []int             // Slot taken within each node
+	latched bool              // Hold shared latches on the path?
+}
+
+// valid returns true if the position is on a record
+func (p *position[K, V]) valid() bool {
+	return len(p.nodes) > 0
+}
+
+// leaf gets the leaf node and slot of the position
+func (p *position[K, V]) leaf() (*treeNode[K, V], int) {
+	if !p.valid() {
+		return nil, 0
+	}
+
+	last := len(p.nodes) - 1
+	return p.nodes[last], p.indexes[last]
+}
+
+// key of the record at the position
+func (p *position[K, V]) key() K {
+	leaf, index := p.leaf()
+	return leaf.Keys[index]
+}
+
+// record at the position
+func (p *position[K, V]) record() record[V] {
+	leaf, index := p.leaf()
+	return leaf.Records[index]
+}
+
+// rank gets the number of records before the position, by adding up the sizes of the
+// subtrees to the left of our path.
+func (p *position[K, V]) rank(length int) int {
+	if !p.valid() {
+		return length
+	}
+
+	last := len(p.nodes) - 1
+	rank := p.indexes[last]
+	for level := 0; level < last; level++ {
+		for _, child := range p.nodes[level].Children[0:p.indexes[level]] {
+			rank += child.subtreeSize()
+		}
+	}
+
+	return rank
+}
+
+// push adds a node to the bottom of the path, latching it if need be
+func (p *position[K, V]) push(node *treeNode[K, V], index int) {
+	if p.latched {
+		node.latch.RLock()
+	}
+
+	p.nodes = append(p.nodes, node)
+	p.indexes = append(p.indexes, index)
+}
+
+// truncate drops the path below a number of levels, releasing the latches
+func (p *position[K, V]) truncate(levels int) {
+	if p.latched {
+		for _, node := range p.nodes[levels:] {
+			node.latch.RUnlock()
+		}
+	}
+
+	p.nodes = p.nodes[0:levels]
+	p.indexes = p.indexes[0:levels]
+}
+
+// descend follows the first or last slots from a node down to a leaf
+func (p *position[K, V]) descend(node *treeNode[K, V], fromEnd bool) {
+	for {
+		p.push(node, 0)
+		if fromEnd {
+			p.indexes[len(p.indexes)-1] = node.Count - 1
+		}
+
+		if node.Leaf {
+			return
+		}
+		node = node.Children[p.indexes[len(p.indexes)-1]]
+	}
+}
+
+// next moves to the following record. Returns false, leaving the position past the
+// end, if there are none.
+func (p *position[K, V]) next() bool {
+	for level := len(p.nodes) - 1; level >= 0; level-- {
+		p.indexes[level]++
+		if p.indexes[level] < p.nodes[level].Count {
+			if level < len(p.nodes)-1 {
+				child := p.nodes[level].Children[p.indexes[level]]
+				p.truncate(level + 1)
+				p.descend(child, false)
+			}
+			return true
+		}
+	}
+
+	p.clear()
+	return false
+}
+
+// previous moves to the preceding record. Returns false, leaving the position empty,
+// if there are none.
+func (p *position[K, V]) previous() bool {
+	for level := len(p.nodes) - 1; level >= 0; level-- {
+		p.indexes[level]--
+		if p.indexes[level] >= 0 {
+			if level < len(p.nodes)-1 {
+				child := p.nodes[level].Children[p.indexes[level]]
+				p.truncate(level + 1)
+				p.descend(child, true)
+			}
+			return true
+		}
+	}
+
+	p.clear()
+	return false
+}
+
+// clear empties the position, releasing any latches
+func (p *position[K, V]) clear() {
+	p.truncate(0)
+}
+
+// first gets the position of the first record in the view
+func (v view[K, V]) first() position[K, V] {
+	p := position[K, V]{latched: v.latched}
+	if v.root != nil {
+		p.descend(v.root, false)
+	}
+
+	return p
+}
+
+// last gets the position of the last record in the view
+func (v view[K, V]) last() position[K, V] {
+	p := position[K, V]{latched: v.latched}
+	if v.root != nil {
+		p.descend(v.root, true)
+	}
+
+	return p
+}
+
+// search gets the position of the first record with a key greater than or equal to k,
+// or strictly greater if inclusive is false.
+func (v view[K, V]) search(k K, inclusive bool) position[K, V] {
+	p := v.searchLeaf(k, inclusive)
+
+	// If everything in the leaf was before our target, the answer starts the next leaf
+	if leaf, index := p.leaf(); leaf != nil && index == leaf.Count {
+		p.indexes[len(p.indexes)-1] = leaf.Count - 1
+		p.next()
+	}
+
+	return p
+}
+
+// searchLeaf finds the leaf that search would look in, and the slot of the first record
+// with a key at or beyond k, which is one past the end of the leaf if every record in
+// it is before k.
+func (v view[K, V]) searchLeaf(k K, inclusive bool) position[K, V] {
+	p := position[K, V]{latched: v.latched}
+	if v.root == nil {
+		return p
+	}
+
+	// precedes tells us if a key sorts ahead of what we're looking for
+	precedes := func(key K) bool {
+		if inclusive {
+			return v.compare(key, k) < 0
+		}
+		return v.compare(key, k) <= 0
+	}
+
+	// Each key of an internal node is the smallest key of its child, so we take the
+	// last child that leads with a key before our target. Anything in earlier
+	// children is also before our target.
+	current := v.root
+	for {
+		p.push(current, 0)
+		if current.Leaf {
+			break
+		}
+
+		targetIndex := 0
+		for i, currentKey := range current.Keys[1:current.Count] {
+			if !precedes(currentKey) {
+				break
+			}
+			targetIndex = i + 1
+		}
+
+		p.indexes[len(p.indexes)-1] = targetIndex
+		current = current.Children[targetIndex]
+	}
+
+	targetIndex := current.Count
+	for i, currentKey := range current.Keys[0:current.Count] {
+		if !precedes(currentKey) {
+			targetIndex = i
+			break
+		}
+	}
+	p.indexes[len(p.indexes)-1] = targetIndex
+
+	return p
+}
+
+// before gets the position of the record preceding a position. The position after the
+// last record is preceded by the last record. The position passed in is consumed, as
+// it shares storage with the result.
+func (v view[K, V]) before(p position[K, V]) position[K, V] {
+	if !p.valid() {
+		return v.last()
+	}
+
+	p.previous()
+	return p
+}
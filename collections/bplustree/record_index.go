@@ -0,0 +1,142 @@
+package bplustree
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Record index
+//
+// Records are found by ID through an index of the key each one is stored against. A
+// key never changes once stored, so the index only changes as records come and go:
+// splits, merges and rebalancing move records between nodes without touching it. To
+// find a record we search for its key, then walk the records sharing it for the ID.
+//
+// Inserts don't touch the index itself, as keeping a map up to date costs more than
+// the rest of an insert put together. They leave the records they add in a list, which
+// is settled into the index the next time it's needed. The list is kept in chunks, so
+// that it never has to be copied as it grows. The index and the list are guarded by
+// their own lock, as concurrent inserts add to the list, and lookups settle it, under
+// the shared tree lock.
+
+// indexChunkSize is the number of records held by each chunk of the list
+const indexChunkSize = 1024
+
+// indexEntry is a record waiting to be added to the index
+type indexEntry[K any] struct {
+	id  collections.RecordID
+	key K
+}
+
+// GetByID gets the key and value of the record with the specified ID
+func (t *tree[K, V]) GetByID(id collections.RecordID) (K, V, bool) {
+	v := t.lockRead()
+	defer t.unlockRead()
+
+	key, found := t.indexedKey(id)
+	if !found {
+		var blankKey K
+		var blankValue V
+		return blankKey, blankValue, false
+	}
+
+	return recordAt(v.searchID(key, id))
+}
+
+// UpdateByID replaces the value of the record with the specified ID. Returns true if
+// the record was found.
+func (t *tree[K, V]) UpdateByID(id collections.RecordID, value V) bool {
+	t.lockExclusive()
+	defer t.lock.Unlock()
+
+	leaf, index := t.findByID(id)
+	if leaf == nil {
+		return false
+	}
+
+	leaf = t.mutable(leaf)
+	leaf.Records[index].Value = value
+	t.invalidateAggregates(leaf)
+	return true
+}
+
+// findByID gets the leaf and slot holding the record with the specified ID, or nil if
+// there is no such record. The caller must hold the lock exclusively.
+func (t *tree[K, V]) findByID(id collections.RecordID) (*treeNode[K, V], int) {
+	key, found := t.indexedKey(id)
+	if !found {
+		return nil, 0
+	}
+
+	p := t.readView().searchID(key, id)
+	return p.leaf()
+}
+
+// searchID gets the position of the record with a key and ID, or an empty position if
+// there is none.
+func (v view[K, V]) searchID(key K, id collections.RecordID) position[K, V] {
+	p := v.lowerBound(key)
+	for p.valid() && v.compare(p.key(), key) == 0 {
+		if p.record().RecordID == id {
+			return p
+		}
+		p.next()
+	}
+
+	p.clear()
+	return p
+}
+
+// scanID gets the position of the record with an ID by visiting every record. Views
+// without an index, such as snapshots, have nothing better to go on.
+func (v view[K, V]) scanID(id collections.RecordID) position[K, V] {
+	p := v.first()
+	for p.valid() && p.record().RecordID != id {
+		p.next()
+	}
+
+	return p
+}
+
+// indexRecord adds a record to the index. The caller must hold the lock exclusively.
+func (t *tree[K, V]) indexRecord(id collections.RecordID, key K) {
+	last := len(t.unindexed) - 1
+	if last < 0 || len(t.unindexed[last]) == indexChunkSize {
+		t.unindexed = append(t.unindexed, make([]indexEntry[K], 0, indexChunkSize))
+		last++
+	}
+
+	t.unindexed[last] = append(t.unindexed[last], indexEntry[K]{id: id, key: key})
+}
+
+// indexRecordConcurrent adds a record to the index from under the shared lock
+func (t *tree[K, V]) indexRecordConcurrent(id collections.RecordID, key K) {
+	t.indexLock.Lock()
+	t.indexRecord(id, key)
+	t.indexLock.Unlock()
+}
+
+// unindexRecord removes a record from the index. The caller must hold the lock
+// exclusively.
+func (t *tree[K, V]) unindexRecord(id collections.RecordID) {
+	t.settleIndex()
+	delete(t.index, id)
+}
+
+// indexedKey gets the key a record is stored against
+func (t *tree[K, V]) indexedKey(id collections.RecordID) (K, bool) {
+	t.indexLock.Lock()
+	defer t.indexLock.Unlock()
+
+	t.settleIndex()
+	key, found := t.index[id]
+	return key, found
+}
+
+// settleIndex adds the records waiting in the list to the index. The caller must hold
+// the lock exclusively, or the index lock.
+func (t *tree[K, V]) settleIndex() {
+	for _, chunk := range t.unindexed {
+		for _, entry := range chunk {
+			t.index[entry.id] = entry.key
+		}
+	}
+	t.unindexed = nil
+}
@@ -0,0 +1,131 @@
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// requireIndexed checks every record of a model can be found by ID, and that records
+// removed from it can't be.
+func requireIndexed(t *testing.T, tree collections.TreeMap[int, int], model map[collections.RecordID][2]int, removed []collections.RecordID) {
+	for id, kv := range model {
+		k, v, found := tree.GetByID(id)
+		require.True(t, found, "Should find record %d", id)
+		require.Equal(t, kv[0], k, "Record %d should have the right key", id)
+		require.Equal(t, kv[1], v, "Record %d should have the right value", id)
+	}
+
+	for _, id := range removed {
+		_, _, found := tree.GetByID(id)
+		require.False(t, found, "Should not find removed record %d", id)
+	}
+}
+
+// TestGetByID finds records amongst many sharing the same key
+func TestGetByID(t *testing.T) {
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			tree, err := New[int, int](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			model := map[collections.RecordID][2]int{}
+			for i := 0; i < 500; i++ {
+				k := i % 7
+				model[tree.Insert(k, i)] = [2]int{k, i}
+			}
+
+			tree.(collections.Diagnosable).CheckConsistency()
+			requireIndexed(t, tree, model, []collections.RecordID{0, 501})
+		})
+	}
+}
+
+// TestUpdateByID replaces the values of individual records, leaving snapshots as they
+// were.
+func TestUpdateByID(t *testing.T) {
+	tree, err := New[int, int](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	var ids []collections.RecordID
+	for i := 0; i < 100; i++ {
+		ids = append(ids, tree.Insert(i%10, i))
+	}
+	snap := tree.Snapshot()
+
+	require.True(t, tree.UpdateByID(ids[42], -42), "Should update a known record")
+	require.False(t, tree.UpdateByID(collections.RecordID(1000), 0), "Should not update an unknown record")
+
+	k, v, found := tree.GetByID(ids[42])
+	require.True(t, found, "Should find the updated record")
+	require.Equal(t, 2, k, "Key should be unchanged")
+	require.Equal(t, -42, v, "Value should be replaced")
+	require.Equal(t, []int{2, 12, 22, 32, -42, 52, 62, 72, 82, 92}, tree.GetAll(2), "Other records of the key should be unchanged")
+
+	k, v, found = snap.GetByID(ids[42])
+	require.True(t, found, "Snapshot should find the record")
+	require.Equal(t, 2, k, "Snapshot key should be unchanged")
+	require.Equal(t, 42, v, "Snapshot value should be unchanged")
+
+	tree.DeleteByID(ids[42])
+	_, _, found = tree.GetByID(ids[42])
+	require.False(t, found, "Should not find a deleted record")
+	tree.(collections.Diagnosable).CheckConsistency()
+}
+
+// TestRecordIndexThroughRestructuring runs random deletes, range deletes, splits and
+// merges, checking every record can still be found by ID.
+func TestRecordIndexThroughRestructuring(t *testing.T) {
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order)))
+			tr, err := New[int, int](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			model := map[collections.RecordID][2]int{}
+			var removed []collections.RecordID
+			remove := func(keep func(k int) bool) {
+				for id, kv := range model {
+					if !keep(kv[0]) {
+						delete(model, id)
+						removed = append(removed, id)
+					}
+				}
+			}
+
+			for round := 0; round < 20; round++ {
+				for i := 0; i < 100; i++ {
+					k := rnd.Intn(1000)
+					model[tr.Insert(k, i)] = [2]int{k, i}
+				}
+
+				for id := range model {
+					if rnd.Intn(10) == 0 {
+						require.True(t, tr.DeleteByID(id), "Should delete record %d", id)
+						delete(model, id)
+						removed = append(removed, id)
+					}
+				}
+
+				from := rnd.Intn(1000)
+				to := from + rnd.Intn(100)
+				tr.DeleteRange(from, to)
+				remove(func(k int) bool { return k < from || k > to })
+
+				// Split and merge back, with every record keeping its ID
+				left, right := tr.SplitAt(rnd.Intn(1000))
+				left.(collections.Diagnosable).CheckConsistency()
+				right.(collections.Diagnosable).CheckConsistency()
+				left.Merge(right)
+
+				tr = left
+				tr.(collections.Diagnosable).CheckConsistency()
+				requireIndexed(t, tr, model, removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package bplustree
+
+import (
+	"iter"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/internal/scan"
+)
+
+// snapshot is a read-only copy of the tree, taken by Snapshot. The nodes it can reach
+// are frozen, so it reads them without taking any lock.
+type snapshot[K any, V any] struct {
+	view[K, V]
+	uniqueKeys bool
+}
+
+// Insert is not supported by snapshots, and panics with ErrReadOnly
+func (s *snapshot[K, V]) Insert(key K, value V) collections.RecordID {
+	panic(collections.ErrReadOnly)
+}
+
+// Upsert is not supported by snapshots, and panics with ErrReadOnly
+func (s *snapshot[K, V]) Upsert(key K, value V) (collections.RecordID, bool) {
+	panic(collections.ErrReadOnly)
+}
+
+// Update is not supported by snapshots, and panics with ErrReadOnly
+func (s *snapshot[K, V]) Update(key K, fn func(old V, exists bool) V) collections.RecordID {
+	panic(collections.ErrReadOnly)
+}
+
+// UpdateByID is not supported by snapshots, and panics with ErrReadOnly
+func (s *snapshot[K, V]) UpdateByID(id collections.RecordID, value V) bool {
+	panic(collections.ErrReadOnly)
+}
+
+// Delete is not supported by snapshots, and panics with ErrReadOnly
+func (s *snapshot[K, V]) Delete(key K) bool {
+	panic(collections.ErrReadOnly)
+}
+
+// DeleteByID is not supported by snapshots, and panics with ErrReadOnly
+func (s *snapshot[K, V]) DeleteByID(id collections.RecordID) bool {
+	panic(collections.ErrReadOnly)
+}
+
+// DeleteRange is not supported by snapshots, and panics with ErrReadOnly
+func (s *snapshot[K, V]) DeleteRange(from K, to K) int {
+	panic(collections.ErrReadOnly)
+}
+
+// SplitAt is not supported by snapshots, and panics with ErrReadOnly
+func (s *snapshot[K, V]) SplitAt(key K) (collections.TreeMap[K, V], collections.TreeMap[K, V]) {
+	panic(collections.ErrReadOnly)
+}
+
+// Merge is not supported by snapshots, and panics with ErrReadOnly
+func (s *snapshot[K, V]) Merge(other collections.TreeMap[K, V]) {
+	panic(collections.ErrReadOnly)
+}
+
+// Get the value of the first record stored against a key
+func (s *snapshot[K, V]) Get(key K) (V, bool) {
+	return s.get(key)
+}
+
+// GetAll gets the values of every record stored against a key
+func (s *snapshot[K, V]) GetAll(key K) []V {
+	return s.getAll(key)
+}
+
+// GetByID gets the key and value of the record with the specified ID. Snapshots have
+// no index of their own, so every record is visited.
+func (s *snapshot[K, V]) GetByID(id collections.RecordID) (K, V, bool) {
+	return recordAt(s.scanID(id))
+}
+
+// Range gets the records with keys between from and to inclusive
+func (s *snapshot[K, V]) Range(from K, to K) []generics.KeyValuePair[K, V] {
+	return s.rangeOf(from, to)
+}
+
+// All iterates the records of the snapshot in key order
+func (s *snapshot[K, V]) All() iter.Seq2[K, V] {
+	return s.all
+}
+
+// Backward iterates the records of the snapshot in reverse key order
+func (s *snapshot[K, V]) Backward() iter.Seq2[K, V] {
+	return s.backward
+}
+
+// Rank gets the number of records with keys less than the key
+func (s *snapshot[K, V]) Rank(key K) int {
+	return s.rank(key)
+}
+
+// Select gets the record at the zero-based position i in key order
+func (s *snapshot[K, V]) Select(i int) (K, V, bool) {
+	return s.selectAt(i)
+}
+
+// CountRange counts the records with keys between from and to inclusive
+func (s *snapshot[K, V]) CountRange(from K, to K) int {
+	return s.countRange(from, to)
+}
+
+// Aggregate combines the values of the records with keys between from and to inclusive
+func (s *snapshot[K, V]) Aggregate(from K, to K) V {
+	if s.aggregates == nil {
+		panic(collections.ErrNoAggregate)
+	}
+
+	return s.aggregate(from, to)
+}
+
+// Floor gets the record with the largest key less than or equal to the key
+func (s *snapshot[K, V]) Floor(key K) (K, V, bool) {
+	return s.floor(key)
+}
+
+// Ceiling gets the record with the smallest key greater than or equal to the key
+func (s *snapshot[K, V]) Ceiling(key K) (K, V, bool) {
+	return s.ceiling(key)
+}
+
+// Lower gets the record with the largest key strictly less than the key
+func (s *snapshot[K, V]) Lower(key K) (K, V, bool) {
+	return s.lower(key)
+}
+
+// Higher gets the record with the smallest key strictly greater than the key
+func (s *snapshot[K, V]) Higher(key K) (K, V, bool) {
+	return s.higher(key)
+}
+
+// Min gets the record with the smallest key
+func (s *snapshot[K, V]) Min() (K, V, bool) {
+	return s.min()
+}
+
+// Max gets the record with the largest key
+func (s *snapshot[K, V]) Max() (K, V, bool) {
+	return s.max()
+}
+
+// Seek creates a cursor positioned just before the first record with a key greater
+// than or equal to the key. As the snapshot never changes, the cursor is never
+// invalidated.
+func (s *snapshot[K, V]) Seek(key K) collections.Cursor[K, V] {
+	return &snapshotCursor[K, V]{
+		view:     s.view,
+		state:    cursorSeeking,
+		position: s.lowerBound(key),
+	}
+}
+
+// Scan records into a channel, which holds them all.
+//
+// Deprecated: Use All, which streams the records rather than holding them all at once.
+func (s *snapshot[K, V]) Scan() chan generics.KeyValuePair[K, V] {
+	return scan.Buffered(s.all)
+}
+
+// Count the records in the snapshot
+func (s *snapshot[K, V]) Count() int {
+	return s.length
+}
+
+// Snapshot of a snapshot is itself, as it can never change
+func (s *snapshot[K, V]) Snapshot() collections.TreeMap[K, V] {
+	return s
+}
+
+// snapshotCursor is a movable position within a snapshot
+type snapshotCursor[K any, V any] struct {
+	view     view[K, V]
+	state    cursorState
+	position position[K, V] // Position of the current record or seek position
+}
+
+// Next moves to the following record
+func (c *snapshotCursor[K, V]) Next() bool {
+	switch c.state {
+	case cursorSeeking:
+	case cursorOnRecord:
+		c.position.next()
+	case cursorBeforeStart:
+		c.position = c.view.first()
+	default:
+		return false
+	}
+
+	return c.settle(cursorAfterEnd)
+}
+
+// Prev moves to the preceding record
+func (c *snapshotCursor[K, V]) Prev() bool {
+	switch c.state {
+	case cursorSeeking, cursorAfterEnd:
+		c.position = c.view.before(c.position)
+	case cursorOnRecord:
+		c.position.previous()
+	default:
+		return false
+	}
+
+	return c.settle(cursorBeforeStart)
+}
+
+// Key of the current record
+func (c *snapshotCursor[K, V]) Key() K {
+	if c.state != cursorOnRecord {
+		var blank K
+		return blank
+	}
+	return c.position.key()
+}
+
+// Value of the current record
+func (c *snapshotCursor[K, V]) Value() V {
+	if c.state != cursorOnRecord {
+		var blank V
+		return blank
+	}
+	return c.position.record().Value
+}
+
+// RecordID of the current record
+func (c *snapshotCursor[K, V]) RecordID() collections.RecordID {
+	if c.state != cursorOnRecord {
+		return 0
+	}
+	return c.position.record().RecordID
+}
+
+// Err gets the reason the cursor stopped being usable. Snapshot cursors never fail.
+func (c *snapshotCursor[K, V]) Err() error {
+	return nil
+}
+
+// Close the cursor. Any further moves will fail.
+func (c *snapshotCursor[K, V]) Close() {
+	c.state = cursorClosed
+	c.position.clear()
+}
+
+// settle updates our state after a move, parking in the overflow state if we ran off
+// an end.
+func (c *snapshotCursor[K, V]) settle(overflow cursorState) bool {
+	if !c.position.valid() {
+		c.state = overflow
+		return false
+	}
+
+	c.state = cursorOnRecord
+	return true
+}
@@ -0,0 +1,272 @@
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// snapshotExpectation is a snapshot along with the keys it should hold
+type snapshotExpectation struct {
+	Snapshot collections.TreeMap[int, int]
+	Keys     []int
+}
+
+// TestSnapshotIsolation takes snapshots while records come and go, then checks every
+// snapshot still holds exactly what the tree held when it was taken.
+func TestSnapshotIsolation(t *testing.T) {
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order)))
+			tree, err := New[int, int](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			var model []int
+			var snapshots []snapshotExpectation
+			for i := 0; i < 2000; i++ {
+				k := rnd.Intn(300)
+				switch {
+				case rnd.Float64() < 0.6:
+					tree.Insert(k, k)
+					index, _ := slices.BinarySearch(model, k+1)
+					model = slices.Insert(model, index, k)
+				case rnd.Float64() < 0.5:
+					tree.Upsert(k, k)
+					if _, found := slices.BinarySearch(model, k); !found {
+						index, _ := slices.BinarySearch(model, k+1)
+						model = slices.Insert(model, index, k)
+					}
+				default:
+					tree.Delete(k)
+					model = slices.DeleteFunc(model, func(v int) bool { return v == k })
+				}
+
+				if i%100 == 0 {
+					snapshots = append(snapshots, snapshotExpectation{
+						Snapshot: tree.Snapshot(),
+						Keys:     slices.Clone(model),
+					})
+					tree.(collections.Diagnosable).CheckConsistency()
+				}
+			}
+			tree.(collections.Diagnosable).CheckConsistency()
+
+			for i, expected := range snapshots {
+				snap := expected.Snapshot
+				require.Equal(t, len(expected.Keys), snap.Count(), "Snapshot %d should have the right count", i)
+
+				var keys []int
+				for k, v := range snap.All() {
+					require.Equal(t, k, v, "Snapshot %d should have the right value", i)
+					keys = append(keys, k)
+				}
+				require.Equal(t, expected.Keys, keys, "Snapshot %d should have the right keys", i)
+
+				var backward []int
+				for k := range snap.Backward() {
+					backward = append(backward, k)
+				}
+				slices.Reverse(backward)
+				require.Equal(t, expected.Keys, backward, "Snapshot %d should iterate backward", i)
+
+				for probe := -1; probe <= 301; probe += 7 {
+					rank, _ := slices.BinarySearch(expected.Keys, probe)
+					require.Equal(t, rank, snap.Rank(probe), "Snapshot %d should rank %d", i, probe)
+				}
+			}
+		})
+	}
+}
+
+// TestSnapshotReads checks the read methods of a snapshot against the live tree it was
+// taken from.
+func TestSnapshotReads(t *testing.T) {
+	tree, err := New[int, string](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(i, fmt.Sprintf("value-%d", i))
+	}
+	tree.Insert(50, "second-50")
+
+	snap := tree.Snapshot()
+	require.Equal(t, tree.GetAll(50), snap.GetAll(50), "GetAll should match")
+	require.Equal(t, tree.Range(11, 31), snap.Range(11, 31), "Range should match")
+	require.Equal(t, tree.CountRange(11, 31), snap.CountRange(11, 31), "CountRange should match")
+
+	for _, probe := range []int{-1, 0, 33, 50, 98, 99} {
+		expectedValue, expectedFound := tree.Get(probe)
+		value, found := snap.Get(probe)
+		require.Equal(t, expectedFound, found, "Get(%d) found should match", probe)
+		require.Equal(t, expectedValue, value, "Get(%d) should match", probe)
+
+		for name, fns := range map[string][2]func(int) (int, string, bool){
+			"Floor":   {tree.Floor, snap.Floor},
+			"Ceiling": {tree.Ceiling, snap.Ceiling},
+			"Lower":   {tree.Lower, snap.Lower},
+			"Higher":  {tree.Higher, snap.Higher},
+		} {
+			expectedKey, expectedValue, expectedFound := fns[0](probe)
+			k, v, found := fns[1](probe)
+			require.Equal(t, expectedFound, found, "%s(%d) found should match", name, probe)
+			require.Equal(t, expectedKey, k, "%s(%d) key should match", name, probe)
+			require.Equal(t, expectedValue, v, "%s(%d) value should match", name, probe)
+		}
+	}
+
+	for i := -1; i <= tree.Count(); i++ {
+		expectedKey, expectedValue, expectedFound := tree.Select(i)
+		k, v, found := snap.Select(i)
+		require.Equal(t, expectedFound, found, "Select(%d) found should match", i)
+		require.Equal(t, expectedKey, k, "Select(%d) key should match", i)
+		require.Equal(t, expectedValue, v, "Select(%d) value should match", i)
+	}
+
+	minKey, _, _ := snap.Min()
+	maxKey, _, _ := snap.Max()
+	require.Equal(t, 0, minKey, "Should have the right minimum")
+	require.Equal(t, 98, maxKey, "Should have the right maximum")
+	require.Same(t, snap, snap.Snapshot(), "A snapshot of a snapshot should be itself")
+
+	scanned := 0
+	for range snap.Scan() {
+		scanned++
+	}
+	require.Equal(t, snap.Count(), scanned, "Should scan every record")
+}
+
+// TestSnapshotReadOnly checks writes to a snapshot panic
+func TestSnapshotReadOnly(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+	tree.Insert(1, 1)
+
+	snap := tree.Snapshot()
+	require.PanicsWithError(t, collections.ErrReadOnly.Error(), func() { snap.Insert(2, 2) }, "Insert should panic")
+	require.PanicsWithError(t, collections.ErrReadOnly.Error(), func() { snap.Upsert(2, 2) }, "Upsert should panic")
+	require.PanicsWithError(t, collections.ErrReadOnly.Error(), func() {
+		snap.Update(2, func(old int, exists bool) int { return old })
+	}, "Update should panic")
+	require.PanicsWithError(t, collections.ErrReadOnly.Error(), func() { snap.Delete(1) }, "Delete should panic")
+	require.PanicsWithError(t, collections.ErrReadOnly.Error(), func() { snap.UpdateByID(1, 1) }, "UpdateByID should panic")
+	require.PanicsWithError(t, collections.ErrReadOnly.Error(), func() { snap.DeleteByID(1) }, "DeleteByID should panic")
+	require.PanicsWithError(t, collections.ErrReadOnly.Error(), func() { snap.DeleteRange(1, 2) }, "DeleteRange should panic")
+	require.PanicsWithError(t, collections.ErrReadOnly.Error(), func() { snap.SplitAt(1) }, "SplitAt should panic")
+	require.PanicsWithError(t, collections.ErrReadOnly.Error(), func() { snap.Merge(snap) }, "Merge should panic")
+}
+
+// TestSnapshotCursor walks a snapshot cursor both ways whilst the tree changes beneath
+func TestSnapshotCursor(t *testing.T) {
+	tree, err := New[int, int](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 50; i++ {
+		tree.Insert(i*2, i*20)
+	}
+
+	snap := tree.Snapshot()
+	c := snap.Seek(21)
+	for i := 0; i < 50; i++ {
+		tree.Delete(i * 2)
+	}
+
+	for expected := 22; expected < 100; expected += 2 {
+		require.True(t, c.Next(), "Should move onto %d", expected)
+		require.Equal(t, expected, c.Key(), "Should have the right key")
+		require.Equal(t, expected*10, c.Value(), "Should have the right value")
+	}
+	require.False(t, c.Next(), "Should run off the end")
+
+	for expected := 98; expected >= 0; expected -= 2 {
+		require.True(t, c.Prev(), "Should move back onto %d", expected)
+		require.Equal(t, expected, c.Key(), "Should have the right key")
+	}
+	require.False(t, c.Prev(), "Should run off the front")
+	require.True(t, c.Next(), "Should come back onto the first record")
+	require.Equal(t, 0, c.Key(), "Should be on the first record")
+	require.NoError(t, c.Err(), "Snapshot cursors should never fail")
+
+	c = snap.Seek(100)
+	require.True(t, c.Prev(), "Should move back from past the end")
+	require.Equal(t, 98, c.Key(), "Should land on the last key")
+
+	c.Close()
+	require.False(t, c.Next(), "Should not move once closed")
+	require.Zero(t, c.Key(), "Should not hold a current record")
+}
+
+// TestSnapshotInvalidatesLiveCursor checks that copying a leaf for a write is seen by
+// cursors on the live tree.
+func TestSnapshotInvalidatesLiveCursor(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, i)
+	}
+
+	c := tree.Seek(50)
+	require.True(t, c.Next(), "Should move onto a record")
+
+	tree.Snapshot()
+	tree.Upsert(50, 500)
+
+	require.False(t, c.Next(), "Should not move after the leaf was copied")
+	require.ErrorIs(t, c.Err(), collections.ErrCursorInvalidated, "Should report invalidation")
+}
+
+// TestSnapshotConcurrentReads reads snapshots without locks whilst a writer works on
+// the tree. Run with -race to check snapshots never see the writer.
+func TestSnapshotConcurrentReads(t *testing.T) {
+	tree, err := New[int, int](5, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 1000; i++ {
+		tree.Insert(i, i)
+	}
+
+	var wg sync.WaitGroup
+	for reader := 0; reader < 4; reader++ {
+		snap := tree.Snapshot()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pass := 0; pass < 20; pass++ {
+				expected := 0
+				for k := range snap.All() {
+					if k != expected {
+						t.Errorf("Snapshot should hold key %d, found %d", expected, k)
+						return
+					}
+					expected++
+				}
+				if expected != 1000 {
+					t.Errorf("Snapshot should hold 1000 records, found %d", expected)
+					return
+				}
+			}
+		}()
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		k := rnd.Intn(2000)
+		if rnd.Intn(2) == 0 {
+			tree.Insert(k, k)
+		} else {
+			tree.Delete(k)
+		}
+		if i%500 == 0 {
+			tree.Snapshot()
+		}
+	}
+
+	wg.Wait()
+	tree.(collections.Diagnosable).CheckConsistency()
+}
@@ -1,9 +1,11 @@
 package bplustree
 
 import (
+	"cmp"
 	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 
 	"github.com/zeroflucs-given/generics"
 	"github.com/zeroflucs-given/generics/collections"
@@ -12,33 +14,133 @@ import (
 
 const (
 	MinTreeOrder = 2
+
+	// MaxTreeOrder is the largest order a tree can have. Every node is allocated with
+	// room for the full order up front, so anything larger is almost certainly a mistake.
+	MaxTreeOrder = 1 << 16
 )
 
 // New creates a new instance of the B+ tree with the specified order.
-func New[K generics.Comparable, V any](order int, preallocateSize int) (collections.TreeMap[K, V], error) {
-	if order < MinTreeOrder {
+func New[K generics.Comparable, V any](order int, preallocateSize int, opts ...Option) (collections.TreeMap[K, V], error) {
+	return NewFunc[K, V](order, preallocateSize, cmp.Compare[K], opts...)
+}
+
+// NewFunc creates a new instance of the B+ tree with the specified order, with keys
+// ordered by a comparator. The comparator returns a negative number when a < b, a
+// positive number when a > b and zero when they are equal, in the style of cmp.Compare.
+func NewFunc[K any, V any](order int, preallocateSize int, compare func(a, b K) int, opts ...Option) (collections.TreeMap[K, V], error) {
+	if compare == nil {
+		return nil, fmt.Errorf("a key comparator is required")
+	} else if order < MinTreeOrder {
 		return nil, fmt.Errorf("invalid tree order %d: too low", order)
+	} else if order > MaxTreeOrder {
+		return nil, fmt.Errorf("invalid tree order %d: too high", order)
 	} else if preallocateSize < 0 {
 		return nil, fmt.Errorf("invalid pre-allocate size: %d too low", preallocateSize)
 	}
 
+	var settings options
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	var keyCodec Codec[K]
+	var valueCodec Codec[V]
+	if settings.keyCodec != nil {
+		var ok bool
+		if keyCodec, ok = settings.keyCodec.(Codec[K]); !ok {
+			return nil, fmt.Errorf("invalid key codec %T: does not match the key type", settings.keyCodec)
+		}
+	}
+	if settings.valueCodec != nil {
+		var ok bool
+		if valueCodec, ok = settings.valueCodec.(Codec[V]); !ok {
+			return nil, fmt.Errorf("invalid value codec %T: does not match the value type", settings.valueCodec)
+		}
+	}
+
+	var aggregates *aggregator[V]
+	if settings.aggregates != nil {
+		var ok bool
+		if aggregates, ok = settings.aggregates.(*aggregator[V]); !ok {
+			return nil, fmt.Errorf("invalid aggregate %T: does not match the value type", settings.aggregates)
+		} else if aggregates.combine == nil {
+			return nil, fmt.Errorf("an aggregate needs a combine function")
+		}
+	}
+
+	result := newTree(order, preallocateSize, compare, settings.uniqueKeys, keyCodec, valueCodec)
+	result.aggregates = aggregates
+
+	return result, nil
+}
+
+// newTree creates an empty tree with its pre-allocation pools
+func newTree[K any, V any](order int, preallocateSize int, compare func(a, b K) int, uniqueKeys bool, keyCodec Codec[K], valueCodec Codec[V]) *tree[K, V] {
 	return &tree[K, V]{
 		Order:                  order,
+		UniqueKeys:             uniqueKeys,
+		Generation:             nextGeneration(),
+		serial:                 serials.Add(1),
+		index:                  map[collections.RecordID]K{},
+		compare:                compare,
+		keyCodec:               keyCodec,
+		valueCodec:             valueCodec,
 		preallocateSize:        preallocateSize,
 		preallocatedKeySets:    ringbuffer.New[[]K](preallocateSize),
 		preallocatedRecordSets: ringbuffer.New[[]record[V]](preallocateSize),
 		preallocatedChildSets:  ringbuffer.New[[]*treeNode[K, V]](preallocateSize),
 		preallocatedNodes:      ringbuffer.New[*treeNode[K, V]](preallocateSize),
-	}, nil
+	}
+}
+
+// reset empties the tree once its nodes have been handed to another. A new generation
+// keeps us from writing to any we still share with a snapshot, and cursors learn that
+// they've been left behind. The record counter is kept, so IDs are never reused.
+func (t *tree[K, V]) reset() {
+	t.Root = nil
+	t.Length = 0
+	t.index = map[collections.RecordID]K{}
+	t.unindexed = nil
+	t.Generation = nextGeneration()
+	t.resets++
+}
+
+// emptyLike creates an empty tree with the same settings and record counter as this
+// one, so that record IDs carry on where ours left off.
+func (t *tree[K, V]) emptyLike() *tree[K, V] {
+	result := newTree(t.Order, t.preallocateSize, t.compare, t.UniqueKeys, t.keyCodec, t.valueCodec)
+	result.RecordCount = t.RecordCount
+	result.aggregates = t.aggregates
+
+	return result
 }
 
-type tree[K generics.Comparable, V any] struct {
+type tree[K any, V any] struct {
 	NodeCount              int64                                `json:"node_count"`   // Sequence number for allocating node
 	RecordCount            collections.RecordID                 `json:"record_count"` // Record counter
+	Length                 int                                  `json:"length"`       // Number of records currently stored
+	Generation             uint64                               `json:"generation"`   // Replaced by each snapshot, freezing older nodes
 	Order                  int                                  `json:"order"`        // Number of values in the tree
+	UniqueKeys             bool                                 `json:"unique_keys"`  // Hold at most one record per key?
 	Root                   *treeNode[K, V]                      `json:"root"`         // Root node
 	lock                   sync.RWMutex                         `json:"-"`            // Lock to prevent concurrent modifies
+	rootLatch              sync.RWMutex                         `json:"-"`            // Latch over the root pointer
+	insertGate             sync.RWMutex                         `json:"-"`            // Held shared by concurrent inserts
+	poolLock               sync.Mutex                           `json:"-"`            // Guards allocation during concurrent inserts
+	pendingLength          atomic.Int64                         `json:"-"`            // Records added by concurrent inserts
+	serial                 uint64                               `json:"-"`            // Orders the locking of trees that are merged
+	resets                 uint64                               `json:"-"`            // Bumped when the tree hands its nodes on
+	index                  map[collections.RecordID]K           `json:"-"`            // Key of each record, by ID
+	unindexed              [][]indexEntry[K]                    `json:"-"`            // Records waiting to be added to the index
+	indexLock              sync.Mutex                           `json:"-"`            // Guards the index during concurrent inserts
+	compare                func(a, b K) int                     `json:"-"`            // Key comparator
+	keyCodec               Codec[K]                             `json:"-"`            // Key codec for persistence
+	valueCodec             Codec[V]                             `json:"-"`            // Value codec for persistence
+	aggregates             *aggregator[V]                       `json:"-"`            // Aggregate kept per node, if any
 	preallocateSize        int                                  `json:"-"`            // Pre-allocation/node pool sizes
+	poolHits               int64                                `json:"-"`            // Allocations served by the pools
+	poolMisses             int64                                `json:"-"`            // Allocations the pools couldn't serve
 	preallocatedKeySets    collections.Queue[[]K]               `json:"-"`            // Pre-allocation of key slices
 	preallocatedRecordSets collections.Queue[[]record[V]]       `json:"-"`            // Pre-allocation of value slices
 	preallocatedChildSets  collections.Queue[[]*treeNode[K, V]] `json:"-"`            // Pre-allocated child sets
@@ -53,13 +155,17 @@ func (t *tree[K, V]) Dump(f io.Writer) {
 
 // createNode creates a ne wnode in the tree
 func (t *tree[K, V]) createNode(leaf bool) *treeNode[K, V] {
+	t.poolLock.Lock()
+	defer t.poolLock.Unlock()
+
 	t.NodeCount++
 	nodeID := t.NodeCount
 
 	result := &treeNode[K, V]{
-		ID:   nodeID,
-		Leaf: leaf,
-		Keys: t.allocKeySet(),
+		ID:         nodeID,
+		Leaf:       leaf,
+		Keys:       t.allocKeySet(),
+		Generation: t.Generation,
 	}
 
 	// Pre-allocate appropriate child type
@@ -75,13 +181,16 @@ func (t *tree[K, V]) createNode(leaf bool) *treeNode[K, V] {
 // allocKeySet allocates a key-set
 func (t *tree[K, V]) allocKeySet() []K {
 	if t.preallocateSize <= 1 {
+		t.poolMisses++
 		return make([]K, t.Order)
 	}
 
 	has, preAlloc := t.preallocatedKeySets.Pop()
 	if has {
+		t.poolHits++
 		return preAlloc
 	}
+	t.poolMisses++
 
 	// Pre-allocate a large set and carve it up into blocks
 	bigAlloc := make([]K, t.Order*t.preallocateSize)
@@ -99,13 +208,16 @@ func (t *tree[K, V]) allocKeySet() []K {
 
 func (t *tree[K, V]) allocRecordSet() []record[V] {
 	if t.preallocateSize <= 1 {
+		t.poolMisses++
 		return make([]record[V], t.Order)
 	}
 
 	has, preAlloc := t.preallocatedRecordSets.Pop()
 	if has {
+		t.poolHits++
 		return preAlloc
 	}
+	t.poolMisses++
 
 	// Pre-allocate a large set and carve it up into blocks
 	bigAlloc := make([]record[V], t.Order*t.preallocateSize)
@@ -124,13 +236,16 @@ func (t *tree[K, V]) allocRecordSet() []record[V] {
 
 func (t *tree[K, V]) allocChildSet() []*treeNode[K, V] {
 	if t.preallocateSize <= 1 {
+		t.poolMisses++
 		return make([]*treeNode[K, V], t.Order)
 	}
 
 	has, preAlloc := t.preallocatedChildSets.Pop()
 	if has {
+		t.poolHits++
 		return preAlloc
 	}
+	t.poolMisses++
 
 	// Pre-allocate a large set and carve it up into blocks
 	bigAlloc := make([]*treeNode[K, V], t.Order*t.preallocateSize)
@@ -146,31 +261,112 @@ func (t *tree[K, V]) allocChildSet() []*treeNode[K, V] {
 	return first
 }
 
+// releaseNode returns the storage of a node that has been removed from the tree back
+// to the pre-allocation pools, so that it can be reused by later inserts. If a pool
+// is already full, the storage is left for the garbage collector. Frozen nodes may
+// still be read by a snapshot, so we only unlink them.
+func (t *tree[K, V]) releaseNode(node *treeNode[K, V]) {
+	if t.frozen(node) {
+		node.Version++
+		node.Parent = nil
+		node.PreviousSibling = nil
+		node.NextSibling = nil
+		return
+	}
+
+	pooled := t.preallocateSize > 1
+
+	clear(node.Keys)
+	if pooled {
+		_ = t.preallocatedKeySets.Push(node.Keys)
+	}
+
+	if node.Leaf {
+		clear(node.Records)
+		if pooled {
+			_ = t.preallocatedRecordSets.Push(node.Records)
+		}
+	} else {
+		clear(node.Children)
+		if pooled {
+			_ = t.preallocatedChildSets.Push(node.Children)
+		}
+	}
+
+	node.Keys = nil
+	node.Records = nil
+	node.Children = nil
+	node.Count = 0
+	node.setSize(0)
+	node.Version++
+	node.Parent = nil
+	node.PreviousSibling = nil
+	node.NextSibling = nil
+}
+
+// adjustAncestorSizes adds delta to the size of every ancestor of a node
+func (t *tree[K, V]) adjustAncestorSizes(node *treeNode[K, V], delta int) {
+	for current := node.Parent; current != nil; current = current.Parent {
+		current.addSize(delta)
+	}
+}
+
+// minimumCount is the number of keys a non-root node must hold to stay balanced. It
+// matches the smaller half produced by a split.
+func (t *tree[K, V]) minimumCount() int {
+	return t.Order / 2
+}
+
+// firstLeaf finds the left-most leaf of the tree, or nil if the tree is empty.
+func (t *tree[K, V]) firstLeaf() *treeNode[K, V] {
+	current := t.Root
+	for current != nil && !current.Leaf {
+		current = current.Children[0]
+	}
+
+	return current
+}
+
+// lowerBound finds the position of the first record with a key greater than or equal
+// to k. If there is no such record, the node returned is nil.
+func (t *tree[K, V]) lowerBound(k K) (*treeNode[K, V], int) {
+	if t.Root == nil {
+		return nil, 0
+	}
+
+	// Duplicate keys can span several leaves and findLeaf lands on the right-most
+	// candidate, so step back whilst the previous leaf could still hold the key.
+	leaf := t.findLeaf(k)
+	for leaf.PreviousSibling != nil {
+		previous := leaf.PreviousSibling
+		if previous.Count == 0 || t.compare(previous.Keys[previous.Count-1], k) < 0 {
+			break
+		}
+		leaf = previous
+	}
+
+	for leaf != nil {
+		for i, currentKey := range leaf.Keys[0:leaf.Count] {
+			if t.compare(currentKey, k) >= 0 {
+				return leaf, i
+			}
+		}
+		leaf = leaf.NextSibling
+	}
+
+	return nil, 0
+}
+
 // findLeaf finds the insertion leaf node for a given key
 func (t *tree[K, V]) findLeaf(k K) *treeNode[K, V] {
 	current := t.Root
-	// NB: We're using a linear scan here, same reason as treenode::getInsertIndex
-	// Even when nodes have unreasonable sizes, the pay-off was non-existent versus
-	// a linear scan (calculating pivots and branching costs more than iteration)
-	// Whilst there is theoretically an inflection point, its just not worth it for
-	// real-world use.
+	// NB: A linear scan used to win here, when keys were compared in place. Now that
+	// every comparison is a call through the comparator, a binary search makes far
+	// fewer of them, so that's what childIndex does.
 
 	// Keep recursing until we find a records/leaf node
 	for current.Records == nil {
-		// Assume we're going at the end
-		targetIndex := current.Count - 1
-
-		// Using a range here avoids repeated check of array bounds
-		i := 1
-		for _, currentKey := range current.Keys[i:current.Count] {
-			if currentKey > k {
-				targetIndex = i - 1
-				break
-			}
-			i++
-		}
-
-		current = current.Children[targetIndex]
+		current = current.Children[current.childIndex(k, t.compare)]
 	}
 
 	return current
@@ -0,0 +1,81 @@
+package bplustree
+
+// Floor gets the record with the largest key less than or equal to the key. Where the
+// key is duplicated, this is the last record for it.
+func (t *tree[K, V]) Floor(key K) (K, V, bool) {
+	k, v, found := t.lockRead().floor(key)
+	t.unlockRead()
+
+	return k, v, found
+}
+
+// Ceiling gets the record with the smallest key greater than or equal to the key.
+// Where the key is duplicated, this is the first record for it.
+func (t *tree[K, V]) Ceiling(key K) (K, V, bool) {
+	k, v, found := t.lockRead().ceiling(key)
+	t.unlockRead()
+
+	return k, v, found
+}
+
+// Lower gets the record with the largest key strictly less than the key
+func (t *tree[K, V]) Lower(key K) (K, V, bool) {
+	k, v, found := t.lockRead().lower(key)
+	t.unlockRead()
+
+	return k, v, found
+}
+
+// Higher gets the record with the smallest key strictly greater than the key
+func (t *tree[K, V]) Higher(key K) (K, V, bool) {
+	k, v, found := t.lockRead().higher(key)
+	t.unlockRead()
+
+	return k, v, found
+}
+
+// Min gets the record with the smallest key
+func (t *tree[K, V]) Min() (K, V, bool) {
+	k, v, found := t.lockRead().min()
+	t.unlockRead()
+
+	return k, v, found
+}
+
+// Max gets the record with the largest key
+func (t *tree[K, V]) Max() (K, V, bool) {
+	k, v, found := t.lockRead().max()
+	t.unlockRead()
+
+	return k, v, found
+}
+
+// floor gets the last record with a key less than or equal to the key
+func (v view[K, V]) floor(key K) (K, V, bool) {
+	return recordAt(v.before(v.upperBound(key)))
+}
+
+// ceiling gets the first record with a key greater than or equal to the key
+func (v view[K, V]) ceiling(key K) (K, V, bool) {
+	return recordAt(v.lowerBound(key))
+}
+
+// lower gets the last record with a key strictly less than the key
+func (v view[K, V]) lower(key K) (K, V, bool) {
+	return recordAt(v.before(v.lowerBound(key)))
+}
+
+// higher gets the first record with a key strictly greater than the key
+func (v view[K, V]) higher(key K) (K, V, bool) {
+	return recordAt(v.upperBound(key))
+}
+
+// min gets the first record
+func (v view[K, V]) min() (K, V, bool) {
+	return recordAt(v.first())
+}
+
+// max gets the last record
+func (v view[K, V]) max() (K, V, bool) {
+	return recordAt(v.last())
+}
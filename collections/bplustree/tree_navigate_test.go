@@ -0,0 +1,120 @@
+package bplustree
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNavigation probes either side of every key, so that we cross every leaf boundary
+func TestNavigation(t *testing.T) {
+	for order := 2; order < 6; order++ {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			tree, err := New[int, string](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			// Keys 0, 10, ..., 100
+			for i := 100; i >= 0; i -= 10 {
+				tree.Insert(i, fmt.Sprintf("value-%d", i))
+			}
+
+			for probe := -5; probe <= 105; probe++ {
+				roundedDown := probe - ((probe%10)+10)%10
+				roundedUp := roundedDown
+				if roundedUp < probe {
+					roundedUp += 10
+				}
+				strictlyBelow := roundedDown
+				if strictlyBelow == probe {
+					strictlyBelow -= 10
+				}
+				strictlyAbove := roundedDown + 10
+
+				floorKey, floorValue, floorFound := tree.Floor(probe)
+				ceilingKey, _, ceilingFound := tree.Ceiling(probe)
+				lowerKey, _, lowerFound := tree.Lower(probe)
+				higherKey, _, higherFound := tree.Higher(probe)
+
+				cases := []struct {
+					Name          string
+					ExpectedKey   int
+					ExpectedFound bool
+					Key           int
+					Found         bool
+				}{
+					{"Floor", roundedDown, roundedDown >= 0 && roundedDown <= 100, floorKey, floorFound},
+					{"Ceiling", roundedUp, roundedUp >= 0 && roundedUp <= 100, ceilingKey, ceilingFound},
+					{"Lower", strictlyBelow, strictlyBelow >= 0 && strictlyBelow <= 100, lowerKey, lowerFound},
+					{"Higher", strictlyAbove, strictlyAbove >= 0 && strictlyAbove <= 100, higherKey, higherFound},
+				}
+
+				for _, c := range cases {
+					require.Equal(t, c.ExpectedFound, c.Found, "%s(%d) found should match", c.Name, probe)
+					if c.ExpectedFound {
+						require.Equal(t, c.ExpectedKey, c.Key, "%s(%d) key should match", c.Name, probe)
+					} else {
+						require.Zero(t, c.Key, "%s(%d) key should be zero", c.Name, probe)
+					}
+				}
+
+				if floorFound {
+					require.Equal(t, fmt.Sprintf("value-%d", floorKey), floorValue, "Floor(%d) should return the matching value", probe)
+				}
+			}
+
+			minKey, _, found := tree.Min()
+			require.True(t, found, "Should have a minimum")
+			require.Equal(t, 0, minKey, "Should have the right minimum")
+
+			maxKey, maxValue, found := tree.Max()
+			require.True(t, found, "Should have a maximum")
+			require.Equal(t, 100, maxKey, "Should have the right maximum")
+			require.Equal(t, "value-100", maxValue, "Should have the right value")
+		})
+	}
+}
+
+// TestNavigationDuplicates checks which record we land on when keys repeat
+func TestNavigationDuplicates(t *testing.T) {
+	tree, err := New[int, int](2, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 10; i++ {
+		tree.Insert(5, i)
+	}
+
+	_, value, _ := tree.Floor(5)
+	require.Equal(t, 9, value, "Floor should land on the last record for the key")
+
+	_, value, _ = tree.Ceiling(5)
+	require.Equal(t, 0, value, "Ceiling should land on the first record for the key")
+
+	_, _, found := tree.Lower(5)
+	require.False(t, found, "Nothing should be lower")
+
+	_, _, found = tree.Higher(5)
+	require.False(t, found, "Nothing should be higher")
+}
+
+// TestNavigationEmpty checks navigation on an empty tree
+func TestNavigationEmpty(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for name, fn := range map[string]func(int) (int, int, bool){
+		"Floor":   tree.Floor,
+		"Ceiling": tree.Ceiling,
+		"Lower":   tree.Lower,
+		"Higher":  tree.Higher,
+	} {
+		_, _, found := fn(1)
+		require.False(t, found, "%s should find nothing", name)
+	}
+
+	_, _, found := tree.Min()
+	require.False(t, found, "Should have no minimum")
+
+	_, _, found = tree.Max()
+	require.False(t, found, "Should have no maximum")
+}
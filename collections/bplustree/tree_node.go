@@ -1,20 +1,24 @@
 package bplustree
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
-
-	"github.com/zeroflucs-given/generics"
+	"sync"
+	"sync/atomic"
 )
 
 // treeNode is a node within the tree
-type treeNode[K generics.Comparable, V any] struct {
+type treeNode[K any, V any] struct {
 	ID         int64  `json:"node_id"`    // Unique node ID
 	Leaf       bool   `json:"leaf"`       // Leaf/Data node?
 	Keys       []K    `json:"key"`        // Keys
 	Count      int    `json:"count"`      // Number of children/data records
+	Version    uint64 `json:"version"`    // Bumped whenever the slots of the node change
+	Generation uint64 `json:"generation"` // Snapshot generation the node was created in
 	Annotation string `json:"annotation"` // Annotation/Informational tag
+	Aggregate  V      `json:"-"`          // Combined values of the subtree, if aggregated
 
 	// Genealogy
 	Parent          *treeNode[K, V] `json:"-"` // Parent
@@ -24,6 +28,11 @@ type treeNode[K generics.Comparable, V any] struct {
 	// Storage
 	Children []*treeNode[K, V] `json:"children"` // Child nodes
 	Records  []record[V]       `json:"records"`  // Records
+
+	size          int64        // Number of records in this subtree
+	latch         sync.RWMutex // Guards the node during concurrent inserts
+	aggregated    atomic.Bool  // Is the aggregate up to date?
+	aggregateLock sync.Mutex   // Guards bringing the aggregate up to date
 }
 
 func (tn *treeNode[K, V]) Dump(f io.Writer, depth int) {
@@ -33,7 +42,7 @@ func (tn *treeNode[K, V]) Dump(f io.Writer, depth int) {
 		return
 	}
 
-	_, _ = fmt.Fprintf(f, "%vNODE(%v) [Leaf=%v, Count=%v] %q\n", prefix, tn.ID, tn.Leaf, tn.Count, tn.Annotation)
+	_, _ = fmt.Fprintf(f, "%vNODE(%v) [Leaf=%v, Count=%v, Size=%v] %q\n", prefix, tn.ID, tn.Leaf, tn.Count, tn.subtreeSize(), tn.Annotation)
 	_, _ = fmt.Fprintf(f, "%v  Prev: %v | Parent: %v | Next: %v\n", prefix, tn.PreviousSibling.NodeID(), tn.Parent.NodeID(), tn.NextSibling.NodeID())
 
 	for i := 0; i < tn.Count; i++ {
@@ -47,6 +56,79 @@ func (tn *treeNode[K, V]) Dump(f io.Writer, depth int) {
 
 }
 
+// DumpDOT writes the node and the subtree beneath it as Graphviz DOT statements. Each
+// node is a record with a field per slot, and each slot of an internal node has an edge
+// to its child.
+func (tn *treeNode[K, V]) DumpDOT(f io.Writer) {
+	var label strings.Builder
+	label.WriteString(dotEscape(fmt.Sprintf("NODE(%v) %v", tn.ID, tn.Annotation)))
+	for i := 0; i < tn.Count; i++ {
+		if tn.Leaf {
+			fmt.Fprintf(&label, "|%v #%d", dotEscape(fmt.Sprint(tn.Keys[i])), tn.Records[i].RecordID)
+		} else {
+			fmt.Fprintf(&label, "|<s%d> %v", i, dotEscape(fmt.Sprint(tn.Keys[i])))
+		}
+	}
+	_, _ = fmt.Fprintf(f, "\tnode%d [label=\"%s\"];\n", tn.ID, label.String())
+
+	if !tn.Leaf {
+		for i, child := range tn.Children[0:tn.Count] {
+			_, _ = fmt.Fprintf(f, "\tnode%d:s%d -> node%d;\n", tn.ID, i, child.ID)
+			child.DumpDOT(f)
+		}
+	}
+}
+
+// dotEscape escapes the characters that have a meaning within a DOT record label
+func dotEscape(s string) string {
+	var result strings.Builder
+	for _, r := range strings.TrimSpace(s) {
+		if strings.ContainsRune(`\"{}|<>`, r) {
+			result.WriteRune('\\')
+		}
+		result.WriteRune(r)
+	}
+
+	return result.String()
+}
+
+// jsonNode is the form a node takes when written as JSON, holding only the occupied
+// slots.
+type jsonNode[K any, V any] struct {
+	ID         int64             `json:"node_id"`
+	Leaf       bool              `json:"leaf"`
+	Keys       []K               `json:"key"`
+	Count      int               `json:"count"`
+	Size       int               `json:"size"`
+	Version    uint64            `json:"version"`
+	Generation uint64            `json:"generation"`
+	Annotation string            `json:"annotation"`
+	Children   []*treeNode[K, V] `json:"children,omitempty"`
+	Records    []record[V]       `json:"records,omitempty"`
+}
+
+// MarshalJSON writes the node and the subtree beneath it as JSON, leaving out the links
+// to its relatives and any unused slots.
+func (tn *treeNode[K, V]) MarshalJSON() ([]byte, error) {
+	result := jsonNode[K, V]{
+		ID:         tn.ID,
+		Leaf:       tn.Leaf,
+		Keys:       tn.Keys[0:tn.Count],
+		Count:      tn.Count,
+		Size:       tn.subtreeSize(),
+		Version:    tn.Version,
+		Generation: tn.Generation,
+		Annotation: tn.Annotation,
+	}
+	if tn.Leaf {
+		result.Records = tn.Records[0:tn.Count]
+	} else {
+		result.Children = tn.Children[0:tn.Count]
+	}
+
+	return json.Marshal(result)
+}
+
 func (tn *treeNode[K, V]) NodeID() string {
 	if tn == nil {
 		return "(none)"
@@ -54,6 +136,66 @@ func (tn *treeNode[K, V]) NodeID() string {
 	return fmt.Sprintf("%v", tn.ID)
 }
 
+// next gets the position of the record after the specified slot of a leaf, following
+// the sibling link when we run off the end. Returns a nil node past the last record.
+func (tn *treeNode[K, V]) next(index int) (*treeNode[K, V], int) {
+	if index+1 < tn.Count {
+		return tn, index + 1
+	}
+
+	return tn.NextSibling, 0
+}
+
+// subtreeSize gets the number of records beneath the node. Sizes are read atomically,
+// as concurrent inserts count themselves in the sizes of the nodes they pass through
+// whilst others read them.
+func (tn *treeNode[K, V]) subtreeSize() int {
+	return int(atomic.LoadInt64(&tn.size))
+}
+
+// addSize adds to the number of records beneath the node. The caller must hold the tree
+// lock exclusively; concurrent inserts use addSizeConcurrent.
+func (tn *treeNode[K, V]) addSize(delta int) {
+	tn.size += int64(delta)
+}
+
+// addSizeConcurrent adds to the number of records beneath the node from under the
+// shared lock
+func (tn *treeNode[K, V]) addSizeConcurrent(delta int) {
+	atomic.AddInt64(&tn.size, int64(delta))
+}
+
+// setSize sets the number of records beneath the node. The caller must hold the tree
+// lock exclusively.
+func (tn *treeNode[K, V]) setSize(size int) {
+	tn.size = int64(size)
+}
+
+// setSizeConcurrent sets the number of records beneath the node from under the shared
+// lock
+func (tn *treeNode[K, V]) setSizeConcurrent(size int) {
+	atomic.StoreInt64(&tn.size, int64(size))
+}
+
+// recomputeSize sets the size of the node from its records or children
+func (tn *treeNode[K, V]) recomputeSize() {
+	tn.setSize(tn.contentSize())
+}
+
+// contentSize adds up the records held by the node, or the sizes of its children
+func (tn *treeNode[K, V]) contentSize() int {
+	if tn.Leaf {
+		return tn.Count
+	}
+
+	size := 0
+	for _, child := range tn.Children[0:tn.Count] {
+		size += child.subtreeSize()
+	}
+
+	return size
+}
+
 // indexOf gets the index of the specified child in the slice
 func (tn *treeNode[K, V]) indexOf(subject *treeNode[K, V]) int {
 	if tn != nil && subject != nil {
@@ -68,15 +210,26 @@ func (tn *treeNode[K, V]) indexOf(subject *treeNode[K, V]) int {
 }
 
 // insertChild inserts a child into the node.
-func (tn *treeNode[K, V]) insertChild(key K, child *treeNode[K, V]) {
+func (tn *treeNode[K, V]) insertChild(key K, child *treeNode[K, V], compare func(a, b K) int) {
+	tn.insertChildLinked(tn.getInsertIndex(key, compare), key, child)
+}
+
+// insertChildAfter inserts a child into the node directly after an existing child. Unlike
+// insertChild this keeps the order of the level when several children lead with the same
+// key.
+func (tn *treeNode[K, V]) insertChildAfter(existing *treeNode[K, V], key K, child *treeNode[K, V]) {
+	tn.insertChildLinked(tn.indexOf(existing)+1, key, child)
+}
+
+// insertChildLinked inserts a child into the specified slot of the node, and links it
+// into the sibling chain of its level.
+func (tn *treeNode[K, V]) insertChildLinked(targetIndex int, key K, child *treeNode[K, V]) {
 	var previousChildSibling, nextChildSibling *treeNode[K, V]
 	if tn.Count > 0 {
 		previousChildSibling = tn.Children[0].PreviousSibling
 		nextChildSibling = tn.Children[tn.Count-1].NextSibling
 	}
 
-	targetIndex := tn.getInsertIndex(key)
-
 	// Move all later values down from the read
 	for lastIndex := tn.Count - 1; lastIndex >= targetIndex; lastIndex-- {
 		tn.Keys[lastIndex+1] = tn.Keys[lastIndex]
@@ -87,6 +240,7 @@ func (tn *treeNode[K, V]) insertChild(key K, child *treeNode[K, V]) {
 	tn.Keys[targetIndex] = key
 	tn.Children[targetIndex] = child
 	tn.Count++
+	tn.addSize(child.subtreeSize())
 
 	child.Parent = tn
 
@@ -127,9 +281,27 @@ func (tn *treeNode[K, V]) insertChild(key K, child *treeNode[K, V]) {
 }
 
 // insertRecord inserts a record into the node.
-func (tn *treeNode[K, V]) insertRecord(key K, record record[V]) {
-	targetIndex := tn.getInsertIndex(key)
+func (tn *treeNode[K, V]) insertRecord(key K, record record[V], compare func(a, b K) int) {
+	targetIndex := tn.getInsertIndex(key, compare)
+	tn.insertRecordAt(targetIndex, key, record)
 
+	// If we're inserting at the lead slot, update our
+	// parents key for us
+	if targetIndex == 0 {
+		tn.updateParentReference()
+	}
+}
+
+// insertRecordAt inserts a record into a specific slot of a leaf node. The caller is
+// responsible for maintaining the parent reference and the size of our ancestors.
+func (tn *treeNode[K, V]) insertRecordAt(targetIndex int, key K, record record[V]) {
+	tn.insertRecordSlot(targetIndex, key, record)
+	tn.addSize(1)
+}
+
+// insertRecordSlot inserts a record into a specific slot of a leaf node as
+// insertRecordAt does, but leaves the size of the node to the caller.
+func (tn *treeNode[K, V]) insertRecordSlot(targetIndex int, key K, record record[V]) {
 	// Move all later values down from the read
 	for lastIndex := tn.Count - 1; lastIndex >= targetIndex; lastIndex-- {
 		tn.Keys[lastIndex+1] = tn.Keys[lastIndex]
@@ -139,12 +311,70 @@ func (tn *treeNode[K, V]) insertRecord(key K, record record[V]) {
 	tn.Keys[targetIndex] = key
 	tn.Records[targetIndex] = record
 	tn.Count++
+	tn.Version++
+}
 
-	// If we're inserting at the lead slot, update our
-	// parents key for us
-	if targetIndex == 0 {
-		tn.updateParentReference()
+// removeRecordAt removes the record at the specified slot of a leaf node, returning
+// the key and record that were removed. The caller is responsible for maintaining the
+// parent reference and the size of our ancestors.
+func (tn *treeNode[K, V]) removeRecordAt(index int) (K, record[V]) {
+	key := tn.Keys[index]
+	removed := tn.Records[index]
+
+	copy(tn.Keys[index:tn.Count], tn.Keys[index+1:tn.Count])
+	copy(tn.Records[index:tn.Count], tn.Records[index+1:tn.Count])
+	tn.Count--
+	tn.addSize(-1)
+	tn.Version++
+
+	// Clear the vacated slot so we don't hold references
+	var blankKey K
+	tn.Keys[tn.Count] = blankKey
+	tn.Records[tn.Count] = record[V]{}
+
+	return key, removed
+}
+
+// insertChildAt inserts a child into a specific slot of an internal node. Unlike
+// insertChild, sibling links are left alone, so this is only suitable for moving
+// children between adjacent nodes where the order of the level is unchanged.
+func (tn *treeNode[K, V]) insertChildAt(targetIndex int, key K, child *treeNode[K, V]) {
+	tn.insertChildSlot(targetIndex, key, child)
+	tn.addSize(child.subtreeSize())
+}
+
+// insertChildSlot inserts a child into a specific slot of an internal node as
+// insertChildAt does, but leaves the size of the node to the caller.
+func (tn *treeNode[K, V]) insertChildSlot(targetIndex int, key K, child *treeNode[K, V]) {
+	for lastIndex := tn.Count - 1; lastIndex >= targetIndex; lastIndex-- {
+		tn.Keys[lastIndex+1] = tn.Keys[lastIndex]
+		tn.Children[lastIndex+1] = tn.Children[lastIndex]
 	}
+
+	tn.Keys[targetIndex] = key
+	tn.Children[targetIndex] = child
+	tn.Count++
+
+	child.Parent = tn
+}
+
+// removeChildAt removes the child at the specified slot of an internal node, returning
+// the key and child that were removed. Sibling links are left alone.
+func (tn *treeNode[K, V]) removeChildAt(index int) (K, *treeNode[K, V]) {
+	key := tn.Keys[index]
+	removed := tn.Children[index]
+
+	copy(tn.Keys[index:tn.Count], tn.Keys[index+1:tn.Count])
+	copy(tn.Children[index:tn.Count], tn.Children[index+1:tn.Count])
+	tn.Count--
+	tn.addSize(-removed.subtreeSize())
+
+	// Clear the vacated slot so we don't hold references
+	var blankKey K
+	tn.Keys[tn.Count] = blankKey
+	tn.Children[tn.Count] = nil
+
+	return key, removed
 }
 
 func (tn *treeNode[K, V]) updateParentReference() {
@@ -162,38 +392,60 @@ func (tn *treeNode[K, V]) updateParentReference() {
 	}
 }
 
-// getInsertIndex gets the insertion index for value K
-func (tn *treeNode[K, V]) getInsertIndex(k K) int {
-	// Sequential scan
-	var targetIndex int
-	for _, nodeKey := range tn.Keys[0:tn.Count] {
-		if nodeKey > k {
-			break
+// edgeLeaf descends the first or last children of the subtree beneath the node to a
+// leaf.
+func (tn *treeNode[K, V]) edgeLeaf(last bool) *treeNode[K, V] {
+	current := tn
+	for !current.Leaf {
+		if last {
+			current = current.Children[current.Count-1]
+		} else {
+			current = current.Children[0]
 		}
-		targetIndex++
 	}
 
-	return targetIndex
+	return current
+}
+
+// height gets the number of levels in the subtree beneath the node, including itself
+func (tn *treeNode[K, V]) height() int {
+	height := 1
+	for current := tn; !current.Leaf; current = current.Children[0] {
+		height++
+	}
+
+	return height
+}
+
+// childIndex gets the slot of the child of an internal node to descend into to insert
+// a key, which is the last child that leads with a key no greater than it.
+func (tn *treeNode[K, V]) childIndex(k K, compare func(a, b K) int) int {
+	// The first child takes anything smaller than its lead key, so it's never searched
+	low, high := 1, tn.Count
+	for low < high {
+		median := int(uint(low+high) >> 1)
+		if compare(tn.Keys[median], k) > 0 {
+			high = median
+		} else {
+			low = median + 1
+		}
+	}
 
-	/*
-		NB: There was no improvement using a binary search here.
-		The code for this is below, however in practice even with 65k
-		entries per node, the extra calculations required negated any
-		improvement.
+	return low - 1
+}
 
-			keys := tn.Keys
-			low := 0
-			high := tn.Count - 1
+// getInsertIndex gets the insertion index for value K, which is after any keys equal
+// to it.
+func (tn *treeNode[K, V]) getInsertIndex(k K, compare func(a, b K) int) int {
+	low, high := 0, tn.Count
+	for low < high {
+		median := int(uint(low+high) >> 1)
+		if compare(tn.Keys[median], k) > 0 {
+			high = median
+		} else {
+			low = median + 1
+		}
+	}
 
-			for low <= high {
-				median := (low + high) / 2
-				mk := keys[median]
-				if mk < k {
-					low = median + 1 // Second half
-				} else {
-					high = median - 1 // First half
-				}
-			}
-			return low
-	*/
+	return low
 }
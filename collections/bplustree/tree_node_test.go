@@ -1,6 +1,7 @@
 package bplustree
 
 import (
+	"cmp"
 	"fmt"
 	"math/rand"
 	"testing"
@@ -33,6 +34,6 @@ func benchmarkTreeNodeGetInsertIndexInternal(b *testing.B, order int) {
 
 	for b.Loop() {
 		valueToInsert := rnd.Int31()
-		node.getInsertIndex(valueToInsert)
+		node.getInsertIndex(valueToInsert, cmp.Compare[int32])
 	}
 }
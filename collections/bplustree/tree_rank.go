@@ -0,0 +1,82 @@
+package bplustree
+
+// Rank gets the number of records with keys less than the key, which is the zero-based
+// position of its first record, or where it would be inserted.
+func (t *tree[K, V]) Rank(key K) int {
+	rank := t.lockRead().rank(key)
+	t.unlockRead()
+
+	return rank
+}
+
+// Select gets the record at the zero-based position i in key order. The boolean
+// indicates if the position was within the tree.
+func (t *tree[K, V]) Select(i int) (K, V, bool) {
+	k, v, found := t.lockRead().selectAt(i)
+	t.unlockRead()
+
+	return k, v, found
+}
+
+// CountRange counts the records with keys between from and to, with both bounds
+// inclusive.
+func (t *tree[K, V]) CountRange(from K, to K) int {
+	count := t.lockRead().countRange(from, to)
+	t.unlockRead()
+
+	return count
+}
+
+// rank gets the number of records with keys less than the key
+func (v view[K, V]) rank(key K) int {
+	p := v.lowerBound(key)
+	defer p.clear()
+
+	return p.rank(v.length)
+}
+
+// selectAt gets the record at the zero-based position i in key order
+func (v view[K, V]) selectAt(i int) (K, V, bool) {
+	if i < 0 || i >= v.length {
+		var blankKey K
+		var blankValue V
+		return blankKey, blankValue, false
+	}
+
+	// Descend through the children, skipping whole subtrees as we go. Concurrent inserts
+	// only ever add to the sizes beneath a node we hold, so the record can't slip away.
+	p := position[K, V]{latched: v.latched}
+	current := v.root
+	for {
+		p.push(current, i)
+		if current.Leaf {
+			break
+		}
+
+		for index, child := range current.Children[0:current.Count] {
+			size := child.subtreeSize()
+			if i < size {
+				p.indexes[len(p.indexes)-1] = index
+				current = child
+				break
+			}
+			i -= size
+		}
+	}
+
+	return recordAt(p)
+}
+
+// countRange counts the records with keys between from and to inclusive
+func (v view[K, V]) countRange(from K, to K) int {
+	// Only one position at a time, as they may hold latches
+	lowerPosition := v.lowerBound(from)
+	lower := lowerPosition.rank(v.length)
+	lowerPosition.clear()
+
+	upperPosition := v.upperBound(to)
+	upper := upperPosition.rank(v.length)
+	upperPosition.clear()
+
+	return max(upper-lower, 0)
+}
@@ -0,0 +1,82 @@
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestOrderStatistics compares Rank, Select and CountRange against a sorted slice as
+// records come and go.
+func TestOrderStatistics(t *testing.T) {
+	for _, order := range []int{2, 3, 5, 16} {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(order)))
+			tree, err := New[int, int](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			var model []int
+			for i := 0; i < 1000; i++ {
+				k := rnd.Intn(200)
+				if rnd.Float64() < 0.7 {
+					tree.Insert(k, k)
+					index, _ := slices.BinarySearch(model, k+1)
+					model = slices.Insert(model, index, k)
+				} else {
+					tree.Delete(k)
+					model = slices.DeleteFunc(model, func(v int) bool { return v == k })
+				}
+
+				if i%50 != 0 {
+					continue
+				}
+				tree.(collections.Diagnosable).CheckConsistency()
+
+				for probe := -1; probe <= 201; probe++ {
+					expectedRank := sort.SearchInts(model, probe)
+					require.Equal(t, expectedRank, tree.Rank(probe), "Rank of %d should match", probe)
+				}
+
+				for position := -1; position <= len(model); position++ {
+					k, v, found := tree.Select(position)
+					if position < 0 || position >= len(model) {
+						require.False(t, found, "Should not select position %d", position)
+						continue
+					}
+					require.True(t, found, "Should select position %d", position)
+					require.Equal(t, model[position], k, "Should select the right key")
+					require.Equal(t, k, v, "Should select the right value")
+				}
+
+				for probe := 0; probe < 20; probe++ {
+					from := rnd.Intn(220) - 10
+					to := from + rnd.Intn(50) - 5
+					expected := 0
+					for _, k := range model {
+						if k >= from && k <= to {
+							expected++
+						}
+					}
+					require.Equal(t, expected, tree.CountRange(from, to), "Count from %d to %d should match", from, to)
+				}
+			}
+		})
+	}
+}
+
+// TestOrderStatisticsEmpty checks queries against an empty tree
+func TestOrderStatisticsEmpty(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	require.Zero(t, tree.Rank(5), "Should rank at the start")
+	_, _, found := tree.Select(0)
+	require.False(t, found, "Should not select anything")
+	require.Zero(t, tree.CountRange(0, 10), "Should count nothing")
+}
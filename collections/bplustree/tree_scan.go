@@ -1,41 +1,62 @@
 package bplustree
 
 import (
+	"iter"
+
 	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections/internal/scan"
 )
 
+// Count the records in the tree
 func (t *tree[K, V]) Count() int {
-	counter := 0
-	for range t.Scan() {
-		counter++
+	t.lock.RLock()
+	count := t.length()
+	t.lock.RUnlock()
+	return count
+}
+
+// All iterates the records of the tree in key order. The read lock is released as
+// soon as the iteration completes or the consumer stops early.
+func (t *tree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		defer t.unlockRead()
+		t.lockRead().all(yield)
+	}
+}
+
+// Backward iterates the records of the tree in reverse key order. The read lock is
+// released as soon as the iteration completes or the consumer stops early.
+func (t *tree[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		defer t.unlockRead()
+		t.lockRead().backward(yield)
 	}
-	return counter
 }
 
+// Scan records into a channel. The records are read up front, so the channel holds them
+// all and the read lock is already released when it's returned.
+//
+// Deprecated: Use All, which streams the records rather than holding them all at once.
 func (t *tree[K, V]) Scan() chan generics.KeyValuePair[K, V] {
-	output := make(chan generics.KeyValuePair[K, V])
-	go func() {
-		t.lock.RLock()
-		defer func() {
-			t.lock.RUnlock()
-			close(output)
-		}()
-
-		current := t.Root
-		for current != nil {
-			if current.Children != nil {
-				current = current.Children[0]
-			} else {
-				for i := 0; i < current.Count; i++ {
-					output <- generics.KeyValuePair[K, V]{
-						Key:   current.Keys[i],
-						Value: current.Records[i].Value,
-					}
-				}
-				current = current.NextSibling
-			}
+	return scan.Buffered(t.All())
+}
+
+// all yields the records in key order until the consumer stops
+func (v view[K, V]) all(yield func(K, V) bool) {
+	for p := v.first(); p.valid(); p.next() {
+		if !yield(p.key(), p.record().Value) {
+			p.clear()
+			return
 		}
-	}()
+	}
+}
 
-	return output
+// backward yields the records in reverse key order until the consumer stops
+func (v view[K, V]) backward(yield func(K, V) bool) {
+	for p := v.last(); p.valid(); p.previous() {
+		if !yield(p.key(), p.record().Value) {
+			p.clear()
+			return
+		}
+	}
 }
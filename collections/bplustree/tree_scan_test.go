@@ -0,0 +1,191 @@
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestIterators checks forward and backward iteration visit every record in order
+func TestIterators(t *testing.T) {
+	for order := 2; order < 8; order++ {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			tree, err := New[int, int](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			for range tree.All() {
+				require.Fail(t, "Should not iterate an empty tree")
+			}
+			for range tree.Backward() {
+				require.Fail(t, "Should not iterate an empty tree")
+			}
+
+			for _, k := range rand.New(rand.NewSource(int64(order))).Perm(100) {
+				tree.Insert(k, k*2)
+			}
+
+			expected := 0
+			for k, v := range tree.All() {
+				require.Equal(t, expected, k, "Keys should be ascending")
+				require.Equal(t, k*2, v, "Should have the right value")
+				expected++
+			}
+			require.Equal(t, 100, expected, "Should visit every record")
+
+			expected = 99
+			for k, v := range tree.Backward() {
+				require.Equal(t, expected, k, "Keys should be descending")
+				require.Equal(t, k*2, v, "Should have the right value")
+				expected--
+			}
+			require.Equal(t, -1, expected, "Should visit every record")
+		})
+	}
+}
+
+// TestIteratorEarlyStop checks that stopping an iteration releases the lock, so that
+// writers can carry on afterwards.
+func TestIteratorEarlyStop(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, i)
+	}
+
+	for k := range tree.All() {
+		if k == 10 {
+			break
+		}
+	}
+	for k := range tree.Backward() {
+		if k == 90 {
+			break
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		tree.Insert(100, 100)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Insert should not block after an iteration stops")
+	}
+}
+
+// TestScanEarlyStop checks that a consumer can stop reading a scan part way through,
+// and writers can carry on afterwards.
+func TestScanEarlyStop(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, i)
+	}
+
+	output := tree.Scan()
+	first := <-output
+	require.Equal(t, 0, first.Key, "Should scan in key order")
+
+	done := make(chan struct{})
+	go func() {
+		tree.Insert(100, 100)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Insert should not block after a scan is abandoned")
+	}
+	require.Equal(t, 101, tree.Count(), "Should have taken the insert")
+}
+
+// TestCountTracksChanges checks the maintained counter through inserts and deletes
+func TestCountTracksChanges(t *testing.T) {
+	tree, err := New[int, int](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+	require.Zero(t, tree.Count(), "Should start empty")
+
+	var ids []collections.RecordID
+	for i := 0; i < 50; i++ {
+		ids = append(ids, tree.Insert(i%10, i))
+	}
+	require.Equal(t, 50, tree.Count(), "Should count every insert")
+
+	tree.Delete(3)
+	require.Equal(t, 45, tree.Count(), "Should count every record removed for a key")
+
+	tree.DeleteByID(ids[0])
+	require.Equal(t, 44, tree.Count(), "Should count a record removed by ID")
+
+	tree.Delete(3)
+	require.Equal(t, 44, tree.Count(), "Should not change when nothing is removed")
+}
+
+// BenchmarkScanChannel measures a full scan through the channel-based Scan
+func BenchmarkScanChannel(b *testing.B) {
+	tree := benchmarkScanTree(b)
+
+	for b.Loop() {
+		for kvp := range tree.Scan() {
+			_ = kvp
+		}
+	}
+}
+
+// BenchmarkScanIterator measures a full scan through the All iterator
+func BenchmarkScanIterator(b *testing.B) {
+	tree := benchmarkScanTree(b)
+
+	for b.Loop() {
+		for k, v := range tree.All() {
+			_, _ = k, v
+		}
+	}
+}
+
+// BenchmarkCountChannel measures counting records by draining the channel-based Scan,
+// which is how Count used to work.
+func BenchmarkCountChannel(b *testing.B) {
+	tree := benchmarkScanTree(b)
+
+	for b.Loop() {
+		count := 0
+		for range tree.Scan() {
+			count++
+		}
+	}
+}
+
+// BenchmarkCount measures the maintained record counter
+func BenchmarkCount(b *testing.B) {
+	tree := benchmarkScanTree(b)
+
+	for b.Loop() {
+		tree.Count()
+	}
+}
+
+func benchmarkScanTree(b *testing.B) collections.TreeMap[int, int] {
+	tree, err := New[int, int](27, DefaultTestPreAlloc)
+	if err != nil {
+		b.Logf("Error: %v", err)
+		b.FailNow()
+	}
+
+	for i, k := range rand.New(rand.NewSource(27)).Perm(100000) {
+		tree.Insert(k, i)
+	}
+
+	return tree
+}
@@ -0,0 +1,79 @@
+package bplustree
+
+import (
+	"github.com/zeroflucs-given/generics"
+)
+
+// Get the value of the first record stored against a key
+func (t *tree[K, V]) Get(key K) (V, bool) {
+	result, found := t.lockRead().get(key)
+	t.unlockRead()
+
+	return result, found
+}
+
+// GetAll gets the values of every record stored against a key
+func (t *tree[K, V]) GetAll(key K) []V {
+	result := t.lockRead().getAll(key)
+	t.unlockRead()
+
+	return result
+}
+
+// Range gets the records with keys between from and to inclusive
+func (t *tree[K, V]) Range(from K, to K) []generics.KeyValuePair[K, V] {
+	result := t.lockRead().rangeOf(from, to)
+	t.unlockRead()
+
+	return result
+}
+
+// get the value of the first record stored against a key
+func (v view[K, V]) get(key K) (V, bool) {
+	p := v.lowerBound(key)
+	defer p.clear()
+
+	if !p.valid() || v.compare(p.key(), key) != 0 {
+		var blank V
+		return blank, false
+	}
+
+	return p.record().Value, true
+}
+
+// getAll gets the values of every record stored against a key
+func (v view[K, V]) getAll(key K) []V {
+	var result []V
+
+	for p := v.lowerBound(key); p.valid(); p.next() {
+		if v.compare(p.key(), key) != 0 {
+			p.clear()
+			break
+		}
+
+		result = append(result, p.record().Value)
+	}
+
+	return result
+}
+
+// rangeOf gets the records with keys between from and to inclusive. We descend to the
+// first record of the range, then step along until we pass the end.
+func (v view[K, V]) rangeOf(from K, to K) []generics.KeyValuePair[K, V] {
+	var result []generics.KeyValuePair[K, V]
+
+	for p := v.lowerBound(from); p.valid(); p.next() {
+		key := p.key()
+		if v.compare(key, to) > 0 {
+			p.clear()
+			break
+		}
+
+		result = append(result, generics.KeyValuePair[K, V]{
+			Key:   key,
+			Value: p.record().Value,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,95 @@
+package bplustree
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics"
+)
+
+// TestGet checks point lookups against present and missing keys
+func TestGet(t *testing.T) {
+	for order := 2; order < 8; order++ {
+		t.Run(fmt.Sprintf("WithOrder_%d", order), func(t *testing.T) {
+			tree, err := New[int, string](order, DefaultTestPreAlloc)
+			require.NoError(t, err, "Should be able to initialize")
+
+			_, found := tree.Get(1)
+			require.False(t, found, "Should not find anything in an empty tree")
+
+			for i := 0; i < 100; i += 2 {
+				tree.Insert(i, fmt.Sprintf("value-%d", i))
+			}
+
+			for i := -1; i < 101; i++ {
+				value, found := tree.Get(i)
+				if i >= 0 && i < 100 && i%2 == 0 {
+					require.True(t, found, "Should find key %d", i)
+					require.Equal(t, fmt.Sprintf("value-%d", i), value, "Should get the right value")
+				} else {
+					require.False(t, found, "Should not find key %d", i)
+					require.Empty(t, value, "Should get the zero value")
+				}
+			}
+		})
+	}
+}
+
+// TestGetAllDuplicates checks we gather duplicates that span several leaves
+func TestGetAllDuplicates(t *testing.T) {
+	tree, err := New[int, int](3, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	require.Empty(t, tree.GetAll(5), "Should not find anything in an empty tree")
+
+	expected := []int{}
+	for i := 0; i < 30; i++ {
+		tree.Insert(i%3*5, i)
+		if i%3 == 1 {
+			expected = append(expected, i)
+		}
+	}
+
+	require.Equal(t, expected, tree.GetAll(5), "Should get every value in insertion order")
+	value, found := tree.Get(5)
+	require.True(t, found, "Should find the key")
+	require.Equal(t, 1, value, "Should get the first inserted value")
+	require.Empty(t, tree.GetAll(4), "Should not find a missing key")
+}
+
+// TestRange checks bounded range seeks, including bounds that don't exist in the tree
+func TestRange(t *testing.T) {
+	tree, err := New[int, int](4, DefaultTestPreAlloc)
+	require.NoError(t, err, "Should be able to initialize")
+
+	require.Empty(t, tree.Range(0, 100), "Should not find anything in an empty tree")
+
+	for i := 0; i < 100; i += 10 {
+		tree.Insert(i, i*2)
+	}
+
+	testCases := []struct {
+		From     int
+		To       int
+		Expected []int
+	}{
+		{From: 20, To: 40, Expected: []int{20, 30, 40}},
+		{From: 15, To: 45, Expected: []int{20, 30, 40}},
+		{From: -100, To: 0, Expected: []int{0}},
+		{From: 90, To: 1000, Expected: []int{90}},
+		{From: 91, To: 1000, Expected: nil},
+		{From: 41, To: 49, Expected: nil},
+		{From: 40, To: 20, Expected: nil},
+	}
+
+	for _, tc := range testCases {
+		var expected []generics.KeyValuePair[int, int]
+		for _, k := range tc.Expected {
+			expected = append(expected, generics.KeyValuePair[int, int]{Key: k, Value: k * 2})
+		}
+
+		require.Equal(t, expected, tree.Range(tc.From, tc.To), "Range %d to %d should match", tc.From, tc.To)
+	}
+}
@@ -0,0 +1,103 @@
+package bplustree
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// marketKey is a composite key used to test custom comparators
+type marketKey struct {
+	EventID  int
+	MarketID string
+}
+
+func compareMarketKeys(a, b marketKey) int {
+	return cmp.Or(cmp.Compare(a.EventID, b.EventID), cmp.Compare(a.MarketID, b.MarketID))
+}
+
+// TestNewFuncCompositeKeys checks a tree ordered by a composite key
+func TestNewFuncCompositeKeys(t *testing.T) {
+	tree, err := NewFunc[marketKey, int](3, DefaultTestPreAlloc, compareMarketKeys)
+	require.NoError(t, err, "Should be able to initialize")
+
+	var keys []marketKey
+	for eventID := 0; eventID < 20; eventID++ {
+		for _, marketID := range []string{"win", "place", "exacta"} {
+			keys = append(keys, marketKey{EventID: eventID, MarketID: marketID})
+		}
+	}
+
+	for i, index := range rand.New(rand.NewSource(1)).Perm(len(keys)) {
+		tree.Insert(keys[index], i)
+		tree.(collections.Diagnosable).CheckConsistency()
+	}
+
+	slices.SortFunc(keys, compareMarketKeys)
+	var visited []marketKey
+	for k := range tree.All() {
+		visited = append(visited, k)
+	}
+	require.Equal(t, keys, visited, "Should visit keys in comparator order")
+
+	_, found := tree.Get(marketKey{EventID: 7, MarketID: "place"})
+	require.True(t, found, "Should find a composite key")
+
+	inRange := tree.Range(marketKey{EventID: 3}, marketKey{EventID: 3, MarketID: "zzz"})
+	require.Len(t, inRange, 3, "Should find every market of the event")
+
+	require.True(t, tree.Delete(marketKey{EventID: 7, MarketID: "place"}), "Should delete a composite key")
+	tree.(collections.Diagnosable).CheckConsistency()
+}
+
+// TestNewFuncTimeKeys checks a tree keyed by time.Time, which can't use the ordering
+// operators.
+func TestNewFuncTimeKeys(t *testing.T) {
+	tree, err := NewFunc[time.Time, string](4, DefaultTestPreAlloc, time.Time.Compare)
+	require.NoError(t, err, "Should be able to initialize")
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 23; i >= 0; i-- {
+		tree.Insert(start.Add(time.Duration(i)*time.Hour), fmt.Sprintf("bucket-%d", i))
+	}
+	tree.(collections.Diagnosable).CheckConsistency()
+
+	// The same instant in another zone should compare equal
+	value, found := tree.Get(start.Add(5 * time.Hour).In(time.FixedZone("AEST", 10*60*60)))
+	require.True(t, found, "Should find the bucket")
+	require.Equal(t, "bucket-5", value, "Should get the right bucket")
+}
+
+// TestNewFuncByteSliceKeys checks a tree keyed by byte slices, which aren't comparable
+func TestNewFuncByteSliceKeys(t *testing.T) {
+	var input []generics.KeyValuePair[[]byte, int]
+	for i := 0; i < 100; i++ {
+		input = append(input, generics.KeyValuePair[[]byte, int]{Key: []byte(fmt.Sprintf("key-%03d", i)), Value: i})
+	}
+
+	tree, err := BuildSortedFunc(5, DefaultTestPreAlloc, 1, bytes.Compare, slices.Values(input))
+	require.NoError(t, err, "Should be able to build")
+	tree.(collections.Diagnosable).CheckConsistency()
+
+	value, found := tree.Get([]byte("key-042"))
+	require.True(t, found, "Should find the key")
+	require.Equal(t, 42, value, "Should get the right value")
+
+	_, err = BuildSortedFunc(5, DefaultTestPreAlloc, 1, func(a, b []byte) int { return bytes.Compare(b, a) }, slices.Values(input))
+	require.Error(t, err, "Input should be unsorted under a reversed comparator")
+}
+
+// TestNewFuncRequiresComparator checks we reject a missing comparator
+func TestNewFuncRequiresComparator(t *testing.T) {
+	_, err := NewFunc[int, int](4, DefaultTestPreAlloc, nil)
+	require.Error(t, err, "Should require a comparator")
+}
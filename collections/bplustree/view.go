@@ -0,0 +1,49 @@
+package bplustree
+
+// view is a read-only window onto the records beneath a root. Reads through a view
+// only move positions, never following the sibling or parent links, so the same code
+// serves the live tree and snapshots. Snapshots need no locks at all, whereas the live
+// tree latches its way down, as concurrent inserts may be under way.
+type view[K any, V any] struct {
+	root       *treeNode[K, V]
+	length     int
+	compare    func(a, b K) int
+	aggregates *aggregator[V]
+	latched    bool
+}
+
+// readView gets an unlatched view of the live tree. The caller must hold the lock
+// exclusively, or through lockQuiescent, for as long as the view is in use.
+func (t *tree[K, V]) readView() view[K, V] {
+	return view[K, V]{
+		root:       t.Root,
+		length:     t.length(),
+		compare:    t.compare,
+		aggregates: t.aggregates,
+	}
+}
+
+// lowerBound gets the position of the first record with a key greater than or equal
+// to k.
+func (v view[K, V]) lowerBound(k K) position[K, V] {
+	return v.search(k, true)
+}
+
+// upperBound gets the position of the first record with a key strictly greater than k
+func (v view[K, V]) upperBound(k K) position[K, V] {
+	return v.search(k, false)
+}
+
+// recordAt reads the record at a position, where an empty position means there is no
+// record. The position is cleared.
+func recordAt[K any, V any](p position[K, V]) (K, V, bool) {
+	defer p.clear()
+
+	if !p.valid() {
+		var blankKey K
+		var blankValue V
+		return blankKey, blankValue, false
+	}
+
+	return p.key(), p.record().Value, true
+}
@@ -0,0 +1,119 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/bplustree"
+)
+
+// A checkpoint is the tree in bplustree's format, behind a small header recording the
+// sequence number of the last operation it includes:
+//
+//	Header: magic [8]byte, sequence uint64, checksum uint32
+const (
+	checkpointMagic      = "ZFWALCKP"
+	checkpointHeaderSize = 8 + 8 + 4
+)
+
+// loadCheckpoint reads the checkpoint at a path. If there is no checkpoint yet, the
+// tree returned is nil.
+func loadCheckpoint[K any, V any](path string, compare func(a, b K) int, keys bplustree.Codec[K], values bplustree.Codec[V], settings options) (collections.TreeMap[K, V], uint64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, nil
+	} else if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	header := make([]byte, checkpointHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, fmt.Errorf("failed to read checkpoint header: %w", err)
+	}
+
+	if string(header[0:8]) != checkpointMagic {
+		return nil, 0, fmt.Errorf("checkpoint has a bad magic number")
+	}
+	checksum := binary.LittleEndian.Uint32(header[checkpointHeaderSize-4:])
+	if crc32.ChecksumIEEE(header[0:checkpointHeaderSize-4]) != checksum {
+		return nil, 0, fmt.Errorf("checkpoint header checksum mismatch")
+	}
+	sequence := binary.LittleEndian.Uint64(header[8:16])
+
+	tree, err := bplustree.LoadFunc(r, settings.preallocateSize, compare, keys, values, settings.treeOptions...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	return tree, sequence, nil
+}
+
+// writeCheckpoint writes the tree to a temporary file, then renames it over the
+// checkpoint. A crash at any point leaves either the old or new checkpoint intact.
+func writeCheckpoint[K any, V any](dir string, tree collections.TreeMap[K, V], sequence uint64) error {
+	temporaryPath := checkpointPath(dir) + ".tmp"
+	f, err := os.Create(temporaryPath)
+	if err != nil {
+		return err
+	}
+
+	err = writeCheckpointTo(f, tree, sequence)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(temporaryPath)
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(temporaryPath, checkpointPath(dir)); err != nil {
+		return fmt.Errorf("failed to replace checkpoint: %w", err)
+	}
+
+	return syncDir(dir)
+}
+
+// writeCheckpointTo writes the header and tree
+func writeCheckpointTo[K any, V any](w io.Writer, tree collections.TreeMap[K, V], sequence uint64) error {
+	buffered := bufio.NewWriter(w)
+
+	header := make([]byte, 0, checkpointHeaderSize)
+	header = append(header, checkpointMagic...)
+	header = binary.LittleEndian.AppendUint64(header, sequence)
+	header = binary.LittleEndian.AppendUint32(header, crc32.ChecksumIEEE(header))
+	if _, err := buffered.Write(header); err != nil {
+		return err
+	}
+
+	if _, err := tree.(io.WriterTo).WriteTo(buffered); err != nil {
+		return err
+	}
+
+	return buffered.Flush()
+}
+
+// syncDir flushes the entries of a directory, so that a rename survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	err = d.Sync()
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,163 @@
+package wal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/zeroflucs-given/generics/collections/bplustree"
+)
+
+// Each log record is framed with its size and a checksum, so a torn or damaged record
+// can be told apart from a good one:
+//
+//	Record:  payload length uint32, payload checksum uint32, payload
+//	Payload: sequence uint64, operation byte, key length uvarint, key,
+//	         then for inserts, value length uvarint, value
+//
+// All fixed-size integers are little-endian.
+const (
+	recordHeaderSize = 4 + 4
+
+	// maxRecordSize bounds the payload size we'll believe, so a damaged length can't
+	// make us allocate wildly.
+	maxRecordSize = 1 << 30
+)
+
+// operation is the kind of write a log record describes
+type operation byte
+
+const (
+	operationInsert operation = 1 // Insert a record
+	operationDelete operation = 2 // Delete all records for a key
+)
+
+// errTornRecord indicates the log ends part way through a record
+var errTornRecord = errors.New("torn log record")
+
+// errDamagedRecord indicates a whole log record failed its checks
+var errDamagedRecord = errors.New("damaged log record")
+
+// logRecord is a single operation in the log
+type logRecord[K any, V any] struct {
+	Sequence  uint64
+	Operation operation
+	Key       K
+	Value     V
+}
+
+// encodeRecord frames a record, ready to append to the log
+func encodeRecord[K any, V any](rec logRecord[K, V], keys bplustree.Codec[K], values bplustree.Codec[V]) ([]byte, error) {
+	result := make([]byte, recordHeaderSize, 64)
+	result = binary.LittleEndian.AppendUint64(result, rec.Sequence)
+	result = append(result, byte(rec.Operation))
+
+	key, err := keys.Encode(rec.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode key %v: %w", rec.Key, err)
+	}
+	result = binary.AppendUvarint(result, uint64(len(key)))
+	result = append(result, key...)
+
+	if rec.Operation == operationInsert {
+		value, err := values.Encode(rec.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode value of key %v: %w", rec.Key, err)
+		}
+		result = binary.AppendUvarint(result, uint64(len(value)))
+		result = append(result, value...)
+	}
+
+	payload := result[recordHeaderSize:]
+	binary.LittleEndian.PutUint32(result[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(result[4:8], crc32.ChecksumIEEE(payload))
+	return result, nil
+}
+
+// readRecord reads the next record of the log. Returns io.EOF at a clean end of the
+// log, errTornRecord if the log ends part way through the record, or errDamagedRecord
+// if the record is all there but fails its checks. The size of the record read is
+// returned with it.
+func readRecord[K any, V any](r io.Reader, keys bplustree.Codec[K], values bplustree.Codec[V]) (logRecord[K, V], int64, error) {
+	var rec logRecord[K, V]
+
+	var header [recordHeaderSize]byte
+	if n, err := io.ReadFull(r, header[:]); err != nil {
+		if n == 0 && errors.Is(err, io.EOF) {
+			return rec, 0, io.EOF
+		}
+		return rec, 0, errTornRecord
+	}
+
+	size := binary.LittleEndian.Uint32(header[0:4])
+	checksum := binary.LittleEndian.Uint32(header[4:8])
+	if size > maxRecordSize {
+		// Skip what we were told without holding it, to find out if the log has it
+		if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+			return rec, 0, errTornRecord
+		}
+		return rec, 0, errDamagedRecord
+	}
+
+	// Copy rather than allocating the size we were told, which may be garbage, so we
+	// only ever hold as much as the log actually has
+	var buffer bytes.Buffer
+	if _, err := io.CopyN(&buffer, r, int64(size)); err != nil {
+		return rec, 0, errTornRecord
+	}
+	payload := buffer.Bytes()
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return rec, 0, errDamagedRecord
+	}
+
+	// The checksum matched, so from here on problems are not a torn write but a
+	// record we can't make sense of.
+	if len(payload) < 9 {
+		return rec, 0, fmt.Errorf("log record of %d bytes is too short", len(payload))
+	}
+	rec.Sequence = binary.LittleEndian.Uint64(payload[0:8])
+	rec.Operation = operation(payload[8])
+	rest := payload[9:]
+
+	keyData, rest, err := readField(rest)
+	if err != nil {
+		return rec, 0, err
+	}
+	if rec.Key, err = keys.Decode(keyData); err != nil {
+		return rec, 0, fmt.Errorf("failed to decode key: %w", err)
+	}
+
+	switch rec.Operation {
+	case operationInsert:
+		var valueData []byte
+		if valueData, rest, err = readField(rest); err != nil {
+			return rec, 0, err
+		}
+		if rec.Value, err = values.Decode(valueData); err != nil {
+			return rec, 0, fmt.Errorf("failed to decode value of key %v: %w", rec.Key, err)
+		}
+	case operationDelete:
+	default:
+		return rec, 0, fmt.Errorf("unknown log operation %d", rec.Operation)
+	}
+
+	if len(rest) > 0 {
+		return rec, 0, fmt.Errorf("%d bytes of trailing data in log record", len(rest))
+	}
+
+	return rec, recordHeaderSize + int64(size), nil
+}
+
+// readField reads a length-prefixed run of bytes, returning it and what follows
+func readField(data []byte) ([]byte, []byte, error) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)-n) {
+		return nil, nil, fmt.Errorf("log record field overruns the record")
+	}
+
+	data = data[n:]
+	return data[0:size], data[size:], nil
+}
@@ -0,0 +1,38 @@
+package wal
+
+import "github.com/zeroflucs-given/generics/collections/bplustree"
+
+// Option configures optional behaviour of a durable tree when it is opened.
+type Option func(o *options)
+
+// options holds the optional behaviours selected when opening
+type options struct {
+	checkpointEvery int
+	preallocateSize int
+	treeOptions     []bplustree.Option
+}
+
+// WithCheckpointEvery checkpoints the tree automatically once the log holds n records,
+// which bounds both the size of the log and the time taken to replay it. Without this
+// option, checkpoints are only taken by calling Checkpoint.
+func WithCheckpointEvery(n int) Option {
+	return func(o *options) {
+		o.checkpointEvery = n
+	}
+}
+
+// WithPreallocation sets the pre-allocation pool size of the tree, as per bplustree.New
+func WithPreallocation(n int) Option {
+	return func(o *options) {
+		o.preallocateSize = n
+	}
+}
+
+// WithTreeOptions passes options through to the tree, such as bplustree.WithUniqueKeys.
+// These only apply when the directory holds no checkpoint yet, as a checkpoint records
+// the settings of the tree it came from.
+func WithTreeOptions(opts ...bplustree.Option) Option {
+	return func(o *options) {
+		o.treeOptions = append(o.treeOptions, opts...)
+	}
+}
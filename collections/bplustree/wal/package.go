@@ -0,0 +1,16 @@
+package wal
+
+// Package wal makes a bplustree durable between checkpoints, using a write-ahead log.
+//
+// Every Insert and Delete is appended to the log, with a sequence number and checksum,
+// and synced to disk before it is applied to the tree. Checkpoints write the whole
+// tree out with bplustree's persistence format, after which the log starts afresh.
+// When a directory is opened, the last checkpoint is loaded and the log replayed over
+// it. A crash part way through appending leaves a torn record at the tail of the log,
+// which is discarded. A damaged record anywhere else is reported as ErrCorrupt, and
+// the log is left as it was.
+//
+// A directory holds two files:
+//
+//	checkpoint  The tree as of a sequence number, replaced atomically by renaming
+//	wal.log     Operations since the checkpoint
@@ -0,0 +1,316 @@
+package wal
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/bplustree"
+)
+
+const (
+	checkpointFile = "checkpoint"
+	logFile        = "wal.log"
+)
+
+// ErrClosed indicates a write was attempted after the tree was closed
+var ErrClosed = errors.New("the write-ahead log has been closed")
+
+// ErrCorrupt indicates the log holds a damaged record with more of the log after it,
+// so it can't be the tail of a write that never completed.
+var ErrCorrupt = errors.New("the write-ahead log is corrupt")
+
+// Tree is a bplustree made durable by a write-ahead log. Writes go through the log, so
+// only Insert and Delete are offered. Reads can be made directly, or through a
+// Snapshot for the full TreeMap interface.
+type Tree[K any, V any] struct {
+	dir        string
+	tree       collections.TreeMap[K, V]
+	keys       bplustree.Codec[K]
+	values     bplustree.Codec[V]
+	settings   options
+	log        *os.File
+	sequence   uint64 // Sequence number of the last operation logged
+	logRecords int    // Number of records in the log
+	err        error  // Reason writes are no longer possible
+	lock       sync.Mutex
+}
+
+// Open a durable tree stored in a directory, creating it if need be. Any checkpoint is
+// loaded and the log replayed over it. The order is only used when starting a new
+// tree, as checkpoints record their own.
+func Open[K generics.Comparable, V any](dir string, order int, keys bplustree.Codec[K], values bplustree.Codec[V], opts ...Option) (*Tree[K, V], error) {
+	return OpenFunc(dir, order, cmp.Compare[K], keys, values, opts...)
+}
+
+// OpenFunc opens a durable tree, as per Open, with keys ordered by a comparator.
+func OpenFunc[K any, V any](dir string, order int, compare func(a, b K) int, keys bplustree.Codec[K], values bplustree.Codec[V], opts ...Option) (*Tree[K, V], error) {
+	var settings options
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	tree, sequence, err := loadCheckpoint(checkpointPath(dir), compare, keys, values, settings)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		treeOptions := append(settings.treeOptions, bplustree.WithCodecs(keys, values))
+		tree, err = bplustree.NewFunc[K, V](order, settings.preallocateSize, compare, treeOptions...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log, err := os.OpenFile(filepath.Join(dir, logFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Tree[K, V]{
+		dir:      dir,
+		tree:     tree,
+		keys:     keys,
+		values:   values,
+		settings: settings,
+		log:      log,
+		sequence: sequence,
+	}
+
+	if err := result.replay(); err != nil {
+		_ = log.Close()
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// replay applies the operations of the log that came after the checkpoint. Each record
+// is synced before the next is written, so only the last can be torn: if the log ends
+// part way through a record, or on a damaged one, that's a write that never completed,
+// and is cut off to leave the log ready for appending. A damaged record with more of
+// the log after it was once whole, so rather than throw away what follows, we leave the
+// log alone and return ErrCorrupt.
+func (t *Tree[K, V]) replay() error {
+	r := bufio.NewReader(t.log)
+	checkpointed := t.sequence
+
+	var offset int64
+	for {
+		rec, size, err := readRecord(r, t.keys, t.values)
+		if errors.Is(err, io.EOF) || errors.Is(err, errTornRecord) {
+			break
+		} else if errors.Is(err, errDamagedRecord) {
+			if _, err := r.Peek(1); err == nil {
+				return fmt.Errorf("%w: damaged record at offset %d is followed by more of the log", ErrCorrupt, offset)
+			} else if !errors.Is(err, io.EOF) {
+				return err
+			}
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to replay log at offset %d: %w", offset, err)
+		}
+
+		// The log may still hold operations already in the checkpoint, if we
+		// stopped between writing the checkpoint and clearing the log.
+		if rec.Sequence > checkpointed {
+			if rec.Sequence != t.sequence+1 {
+				return fmt.Errorf("log skips from sequence %d to %d at offset %d", t.sequence, rec.Sequence, offset)
+			}
+			t.apply(rec)
+			t.sequence = rec.Sequence
+		}
+
+		offset += size
+		t.logRecords++
+	}
+
+	if err := t.log.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := t.log.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	return t.log.Sync()
+}
+
+// apply performs a logged operation on the tree
+func (t *Tree[K, V]) apply(rec logRecord[K, V]) collections.RecordID {
+	switch rec.Operation {
+	case operationInsert:
+		return t.tree.Insert(rec.Key, rec.Value)
+	default:
+		t.tree.Delete(rec.Key)
+		return 0
+	}
+}
+
+// Insert a value into the tree, once it is safely in the log. If this triggers an
+// automatic checkpoint that fails, the error is returned, but the insert stands.
+func (t *Tree[K, V]) Insert(key K, value V) (collections.RecordID, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	rec := logRecord[K, V]{
+		Operation: operationInsert,
+		Key:       key,
+		Value:     value,
+	}
+	if err := t.append(rec); err != nil {
+		return 0, err
+	}
+
+	return t.apply(rec), t.checkpointIfDue()
+}
+
+// Delete all records stored against a key, once the deletion is safely in the log.
+// If this triggers an automatic checkpoint that fails, the error is returned, but the
+// deletion stands.
+func (t *Tree[K, V]) Delete(key K) (bool, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	rec := logRecord[K, V]{
+		Operation: operationDelete,
+		Key:       key,
+	}
+	if err := t.append(rec); err != nil {
+		return false, err
+	}
+
+	return t.tree.Delete(key), t.checkpointIfDue()
+}
+
+// Get the value of the first record stored against a key
+func (t *Tree[K, V]) Get(key K) (V, bool) {
+	return t.tree.Get(key)
+}
+
+// Count the records in the tree
+func (t *Tree[K, V]) Count() int {
+	return t.tree.Count()
+}
+
+// Snapshot takes a read-only copy of the tree, as per TreeMap.Snapshot
+func (t *Tree[K, V]) Snapshot() collections.TreeMap[K, V] {
+	return t.tree.Snapshot()
+}
+
+// Sequence gets the sequence number of the last operation logged
+func (t *Tree[K, V]) Sequence() uint64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.sequence
+}
+
+// Checkpoint writes the whole tree out, then clears the log. Writers are blocked
+// until it completes.
+func (t *Tree[K, V]) Checkpoint() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.checkpoint()
+}
+
+// Close the log. The tree can still be read, but no longer written.
+func (t *Tree[K, V]) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if errors.Is(t.err, ErrClosed) {
+		return ErrClosed
+	}
+	t.err = ErrClosed
+
+	err := t.log.Sync()
+	if closeErr := t.log.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// append writes a record to the end of the log and syncs it to disk. If this fails,
+// the log may hold part of the record, so we refuse any further writes rather than
+// append after it.
+func (t *Tree[K, V]) append(rec logRecord[K, V]) error {
+	if t.err != nil {
+		return t.err
+	}
+
+	rec.Sequence = t.sequence + 1
+	data, err := encodeRecord(rec, t.keys, t.values)
+	if err != nil {
+		return err
+	}
+
+	if _, err := t.log.Write(data); err != nil {
+		t.err = fmt.Errorf("failed to append to log: %w", err)
+		return t.err
+	}
+	if err := t.log.Sync(); err != nil {
+		t.err = fmt.Errorf("failed to sync log: %w", err)
+		return t.err
+	}
+
+	t.sequence = rec.Sequence
+	t.logRecords++
+	return nil
+}
+
+// checkpointIfDue checkpoints once the log has grown as long as configured
+func (t *Tree[K, V]) checkpointIfDue() error {
+	if t.settings.checkpointEvery <= 0 || t.logRecords < t.settings.checkpointEvery {
+		return nil
+	}
+
+	return t.checkpoint()
+}
+
+// checkpoint writes the tree out as of the last logged operation, then clears the log.
+// The caller must hold the lock.
+func (t *Tree[K, V]) checkpoint() error {
+	if t.err != nil {
+		return t.err
+	}
+
+	if err := writeCheckpoint(t.dir, t.tree, t.sequence); err != nil {
+		return err
+	}
+
+	// Everything in the log is now covered by the checkpoint. Should we fail to clear
+	// it, replay would skip those records anyway, but the end of the log is no longer
+	// certain, so we stop writing.
+	if err := t.log.Truncate(0); err != nil {
+		t.err = fmt.Errorf("failed to clear log: %w", err)
+		return t.err
+	}
+	if _, err := t.log.Seek(0, io.SeekStart); err != nil {
+		t.err = fmt.Errorf("failed to clear log: %w", err)
+		return t.err
+	}
+	if err := t.log.Sync(); err != nil {
+		t.err = fmt.Errorf("failed to clear log: %w", err)
+		return t.err
+	}
+
+	t.logRecords = 0
+	return nil
+}
+
+// checkpointPath gets the path of the checkpoint within a directory
+func checkpointPath(dir string) string {
+	return filepath.Join(dir, checkpointFile)
+}
@@ -0,0 +1,276 @@
+package wal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections/bplustree"
+)
+
+// openTest opens a durable tree of int64 keys and string values
+func openTest(t *testing.T, dir string, opts ...Option) *Tree[int64, string] {
+	tree, err := Open[int64, string](dir, 4, bplustree.BinaryCodec[int64]{}, bplustree.StringCodec{}, opts...)
+	require.NoError(t, err, "Should be able to open the tree")
+	return tree
+}
+
+// contentsOf reads every key and value of a tree
+func contentsOf(tree *Tree[int64, string]) map[int64]string {
+	result := map[int64]string{}
+	for k, v := range tree.Snapshot().All() {
+		result[k] = v
+	}
+	return result
+}
+
+// TestReopen checks writes survive closing and reopening, with and without checkpoints
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	tree := openTest(t, dir)
+
+	expected := map[int64]string{}
+	for i := int64(0); i < 100; i++ {
+		_, err := tree.Insert(i, fmt.Sprintf("value-%d", i))
+		require.NoError(t, err, "Should be able to insert")
+		expected[i] = fmt.Sprintf("value-%d", i)
+
+		if i == 50 {
+			require.NoError(t, tree.Checkpoint(), "Should be able to checkpoint")
+		}
+	}
+	for i := int64(0); i < 100; i += 3 {
+		deleted, err := tree.Delete(i)
+		require.NoError(t, err, "Should be able to delete")
+		require.True(t, deleted, "Should have deleted the key")
+		delete(expected, i)
+	}
+	require.NoError(t, tree.Close(), "Should be able to close")
+
+	_, err := tree.Insert(1, "closed")
+	require.ErrorIs(t, err, ErrClosed, "Should not write once closed")
+	require.ErrorIs(t, tree.Close(), ErrClosed, "Should not close twice")
+
+	reopened := openTest(t, dir)
+	require.Equal(t, expected, contentsOf(reopened), "Should have the same contents")
+	require.Equal(t, uint64(134), reopened.Sequence(), "Should carry on the sequence")
+
+	value, found := reopened.Get(2)
+	require.True(t, found, "Should find a key")
+	require.Equal(t, "value-2", value, "Should have the right value")
+	require.NoError(t, reopened.Close(), "Should be able to close")
+}
+
+// TestCheckpointEvery checks automatic checkpoints keep the log short
+func TestCheckpointEvery(t *testing.T) {
+	dir := t.TempDir()
+	tree := openTest(t, dir, WithCheckpointEvery(10))
+
+	for i := int64(0); i < 95; i++ {
+		_, err := tree.Insert(i, "value")
+		require.NoError(t, err, "Should be able to insert")
+	}
+	require.Equal(t, 5, tree.logRecords, "Should have checkpointed every 10 records")
+	require.NoError(t, tree.Close(), "Should be able to close")
+
+	reopened := openTest(t, dir)
+	require.Equal(t, 95, reopened.Count(), "Should have every record")
+	require.NoError(t, reopened.Close(), "Should be able to close")
+}
+
+// TestUniqueKeys checks tree options apply to a new tree, and survive checkpoints
+func TestUniqueKeys(t *testing.T) {
+	dir := t.TempDir()
+	tree := openTest(t, dir, WithTreeOptions(bplustree.WithUniqueKeys()))
+
+	for i := 0; i < 10; i++ {
+		_, err := tree.Insert(1, fmt.Sprintf("value-%d", i))
+		require.NoError(t, err, "Should be able to insert")
+	}
+	require.NoError(t, tree.Checkpoint(), "Should be able to checkpoint")
+	require.NoError(t, tree.Close(), "Should be able to close")
+
+	reopened := openTest(t, dir)
+	_, err := reopened.Insert(1, "again")
+	require.NoError(t, err, "Should be able to insert")
+	require.Equal(t, map[int64]string{1: "again"}, contentsOf(reopened), "Should hold one record for the key")
+	require.NoError(t, reopened.Close(), "Should be able to close")
+}
+
+// TestCheckpointNotCleared covers stopping after a checkpoint is written, but before
+// the log is cleared. Replaying the log must not apply its operations twice.
+func TestCheckpointNotCleared(t *testing.T) {
+	dir := t.TempDir()
+	tree := openTest(t, dir)
+
+	for i := int64(0); i < 20; i++ {
+		_, err := tree.Insert(i%5, "value")
+		require.NoError(t, err, "Should be able to insert")
+	}
+
+	logPath := filepath.Join(dir, logFile)
+	stale, err := os.ReadFile(logPath)
+	require.NoError(t, err, "Should be able to read the log")
+
+	require.NoError(t, tree.Checkpoint(), "Should be able to checkpoint")
+	require.NoError(t, tree.Close(), "Should be able to close")
+	require.NoError(t, os.WriteFile(logPath, stale, 0o644), "Should be able to restore the log")
+
+	reopened := openTest(t, dir)
+	require.Equal(t, 20, reopened.Count(), "Should not replay checkpointed operations")
+
+	_, err = reopened.Insert(100, "after")
+	require.NoError(t, err, "Should be able to insert")
+	require.NoError(t, reopened.Close(), "Should be able to close")
+
+	again := openTest(t, dir)
+	require.Equal(t, 21, again.Count(), "Should replay the operation after the checkpoint")
+	require.NoError(t, again.Close(), "Should be able to close")
+}
+
+// TestTornLog truncates the log at every byte offset, then checks recovery keeps every
+// whole record, discards the torn one and carries on appending after it.
+func TestTornLog(t *testing.T) {
+	dir := t.TempDir()
+	tree := openTest(t, dir, WithTreeOptions(bplustree.WithUniqueKeys()))
+
+	// A checkpoint part way through means replay starts from a non-empty tree
+	expected := map[int64]string{}
+	for i := int64(0); i < 10; i++ {
+		_, err := tree.Insert(i, fmt.Sprintf("checkpointed-%d", i))
+		require.NoError(t, err, "Should be able to insert")
+		expected[i] = fmt.Sprintf("checkpointed-%d", i)
+	}
+	require.NoError(t, tree.Checkpoint(), "Should be able to checkpoint")
+
+	// Record the log size and contents after each operation
+	logPath := filepath.Join(dir, logFile)
+	boundaries := []int64{0}
+	states := []map[int64]string{maps.Clone(expected)}
+	for i := int64(0); i < 30; i++ {
+		k := i % 13
+		var err error
+		if i%4 == 3 {
+			_, err = tree.Delete(k)
+			delete(expected, k)
+		} else {
+			_, err = tree.Insert(k, fmt.Sprintf("logged-%d", i))
+			expected[k] = fmt.Sprintf("logged-%d", i)
+		}
+		require.NoError(t, err, "Should be able to write")
+
+		info, err := os.Stat(logPath)
+		require.NoError(t, err, "Should be able to stat the log")
+		boundaries = append(boundaries, info.Size())
+		states = append(states, maps.Clone(expected))
+	}
+	require.NoError(t, tree.Close(), "Should be able to close")
+
+	checkpoint, err := os.ReadFile(filepath.Join(dir, checkpointFile))
+	require.NoError(t, err, "Should be able to read the checkpoint")
+	logData, err := os.ReadFile(logPath)
+	require.NoError(t, err, "Should be able to read the log")
+	require.Equal(t, boundaries[len(boundaries)-1], int64(len(logData)), "Should have logged everything")
+
+	for offset := int64(0); offset <= int64(len(logData)); offset++ {
+		crashDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(crashDir, checkpointFile), checkpoint, 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(crashDir, logFile), logData[0:offset], 0o644))
+
+		// The operations that made it are those whose records ended within the log
+		complete, _ := slices.BinarySearch(boundaries, offset+1)
+		complete--
+
+		recovered := openTest(t, crashDir)
+		require.Equal(t, states[complete], contentsOf(recovered), "Should recover %d operations at offset %d", complete, offset)
+		require.Equal(t, uint64(10+complete), recovered.Sequence(), "Should recover the sequence at offset %d", offset)
+
+		_, err := recovered.Insert(1000, "after-recovery")
+		require.NoError(t, err, "Should be able to insert after recovery")
+		require.NoError(t, recovered.Close(), "Should be able to close")
+
+		info, err := os.Stat(filepath.Join(crashDir, logFile))
+		require.NoError(t, err, "Should be able to stat the log")
+		require.Greater(t, info.Size(), boundaries[complete], "Should append after the last whole record")
+
+		again := openTest(t, crashDir)
+		value, found := again.Get(1000)
+		require.True(t, found, "Should keep the insert made after recovery at offset %d", offset)
+		require.Equal(t, "after-recovery", value, "Should have the right value")
+		require.NoError(t, again.Close(), "Should be able to close")
+	}
+}
+
+// TestDamagedTail checks a record damaged in place is treated as the torn tail
+func TestDamagedTail(t *testing.T) {
+	dir := t.TempDir()
+	tree := openTest(t, dir)
+	for i := int64(0); i < 5; i++ {
+		_, err := tree.Insert(i, "value")
+		require.NoError(t, err, "Should be able to insert")
+	}
+	require.NoError(t, tree.Close(), "Should be able to close")
+
+	logPath := filepath.Join(dir, logFile)
+	logData, err := os.ReadFile(logPath)
+	require.NoError(t, err, "Should be able to read the log")
+	logData[len(logData)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(logPath, logData, 0o644))
+
+	recovered := openTest(t, dir)
+	require.Equal(t, 4, recovered.Count(), "Should discard the damaged record")
+	require.NoError(t, recovered.Close(), "Should be able to close")
+}
+
+// TestDamagedMiddle checks a damaged record with more of the log after it is reported
+// as corruption, leaving the log as it was
+func TestDamagedMiddle(t *testing.T) {
+	dir := t.TempDir()
+	tree := openTest(t, dir)
+	for i := int64(0); i < 5; i++ {
+		_, err := tree.Insert(i, "value")
+		require.NoError(t, err, "Should be able to insert")
+	}
+	require.NoError(t, tree.Close(), "Should be able to close")
+
+	logPath := filepath.Join(dir, logFile)
+	logData, err := os.ReadFile(logPath)
+	require.NoError(t, err, "Should be able to read the log")
+	logData[recordHeaderSize] ^= 0xff
+	require.NoError(t, os.WriteFile(logPath, logData, 0o644))
+
+	_, err = Open[int64, string](dir, 4, bplustree.BinaryCodec[int64]{}, bplustree.StringCodec{})
+	require.ErrorIs(t, err, ErrCorrupt, "Should refuse a log damaged part way through")
+
+	after, err := os.ReadFile(logPath)
+	require.NoError(t, err, "Should be able to read the log")
+	require.Equal(t, logData, after, "Should leave the log untouched")
+}
+
+// TestOversizedRecord checks a record header claiming a large payload that isn't there
+// is treated as torn, without allocating what it claims
+func TestOversizedRecord(t *testing.T) {
+	var header [recordHeaderSize]byte
+	binary.LittleEndian.PutUint32(header[0:4], maxRecordSize)
+	data := append(header[:], make([]byte, 100)...)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, _, err := readRecord[int64, string](bytes.NewReader(data), bplustree.BinaryCodec[int64]{}, bplustree.StringCodec{})
+	runtime.ReadMemStats(&after)
+
+	require.ErrorIs(t, err, errTornRecord, "Should treat the short record as torn")
+	require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20), "Should only allocate for the bytes present")
+
+	_, _, err = readRecord[int64, string](bytes.NewReader(nil), bplustree.BinaryCodec[int64]{}, bplustree.StringCodec{})
+	require.ErrorIs(t, err, io.EOF, "Should report a clean end of the log")
+}
@@ -0,0 +1,244 @@
+package collectionstest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// blockedFor is how long a waiter must go without returning to be taken as blocked
+const blockedFor = 20 * time.Millisecond
+
+// wakeWithin is how long a waiter is given to return once it should have been woken,
+// before the suite gives up on it
+const wakeWithin = 5 * time.Second
+
+// waiters is the number of goroutines each test leaves waiting at once
+const waiters = 5
+
+// RunBlockingQueueSuite checks a queue wakes waiting producers and consumers as items
+// are pushed and popped, and when their contexts are done or the queue is closed. With
+// WithBoundedCapacity the queue is built with a small capacity so pushes can be left
+// waiting for room, otherwise it is built with collections.CapacityInfinite.
+func RunBlockingQueueSuite(t *testing.T, newQueue func(capacity int) collections.BlockingQueue[int], opts ...Option) {
+	o := defaultOptions(opts)
+
+	capacity := collections.CapacityInfinite
+	if o.bounded {
+		capacity = 2
+	}
+
+	// full builds a queue and fills it, so pushes have to wait
+	full := func(t *testing.T) collections.BlockingQueue[int] {
+		q := newQueue(capacity)
+		for i := 0; i < capacity; i++ {
+			require.NoError(t, q.Push(i), "Should fill the queue")
+		}
+		return q
+	}
+
+	t.Run("PopWaitsForPush", func(t *testing.T) {
+		q := newQueue(capacity)
+		popped := popWaiters(context.Background(), q, 1)
+		requireBlocked(t, popped)
+
+		require.NoError(t, q.Push(7), "Should push")
+		result := requireWoken(t, popped)
+		require.NoError(t, result.err, "Should pop once an item is pushed")
+		require.Equal(t, 7, result.value, "Should pop the item pushed")
+	})
+
+	t.Run("PushWaitsForPop", func(t *testing.T) {
+		if !o.bounded {
+			t.Skip("Pushes to an unbounded queue never wait")
+		}
+
+		q := full(t)
+		pushed := pushWaiters(context.Background(), q, 1)
+		requireBlocked(t, pushed)
+
+		found, _ := q.Pop()
+		require.True(t, found, "Should pop")
+		require.NoError(t, requireWoken(t, pushed).err, "Should push once there is room")
+		require.Equal(t, capacity, q.Count(), "Should be full again")
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		popped := popWaiters(ctx, newQueue(capacity), waiters)
+		var pushed chan waitResult
+		if o.bounded {
+			pushed = pushWaiters(ctx, full(t), waiters)
+		}
+		requireBlocked(t, popped)
+
+		cancel()
+		for i := 0; i < waiters; i++ {
+			require.ErrorIs(t, requireWoken(t, popped).err, context.Canceled, "Should wake every consumer")
+			if o.bounded {
+				require.ErrorIs(t, requireWoken(t, pushed).err, context.Canceled, "Should wake every producer")
+			}
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), blockedFor)
+		defer cancel()
+		_, err := newQueue(capacity).PopWait(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded, "Should give up at the deadline")
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		q := newQueue(capacity)
+		popped := popWaiters(context.Background(), q, waiters)
+		requireBlocked(t, popped)
+
+		q.Close()
+		for i := 0; i < waiters; i++ {
+			require.ErrorIs(t, requireWoken(t, popped).err, collections.ErrClosed, "Should wake every consumer")
+		}
+		require.NotPanics(t, q.Close, "Should be able to close again")
+	})
+
+	t.Run("CloseWakesProducers", func(t *testing.T) {
+		if !o.bounded {
+			t.Skip("Pushes to an unbounded queue never wait")
+		}
+
+		q := full(t)
+		pushed := pushWaiters(context.Background(), q, waiters)
+		requireBlocked(t, pushed)
+
+		q.Close()
+		for i := 0; i < waiters; i++ {
+			require.ErrorIs(t, requireWoken(t, pushed).err, collections.ErrClosed, "Should wake every producer")
+		}
+	})
+
+	t.Run("DrainAfterClose", func(t *testing.T) {
+		q := newQueue(capacity)
+		count := 2
+		for i := 0; i < count; i++ {
+			require.NoError(t, q.PushWait(context.Background(), i), "Should push")
+		}
+		q.Close()
+
+		require.ErrorIs(t, q.Push(9), collections.ErrClosed, "Should refuse pushes once closed")
+		require.ErrorIs(t, q.PushWait(context.Background(), 9), collections.ErrClosed, "Should refuse pushes once closed")
+		for i := 0; i < count; i++ {
+			_, err := q.PopWait(context.Background())
+			require.NoError(t, err, "Should pop the items left once closed")
+		}
+		_, err := q.PopWait(context.Background())
+		require.ErrorIs(t, err, collections.ErrClosed, "Should report the queue closed once drained")
+	})
+
+	t.Run("ProducersAndConsumers", func(t *testing.T) {
+		q := newQueue(capacity)
+		perProducer := o.operations / waiters
+
+		var producers sync.WaitGroup
+		var expected []int
+		for p := 0; p < waiters; p++ {
+			for i := 0; i < perProducer; i++ {
+				expected = append(expected, p*perProducer+i)
+			}
+
+			producers.Add(1)
+			go func(p int) {
+				defer producers.Done()
+				for i := 0; i < perProducer; i++ {
+					if err := q.PushWait(context.Background(), p*perProducer+i); err != nil {
+						t.Errorf("Should push: %v", err)
+						return
+					}
+				}
+			}(p)
+		}
+
+		var lock sync.Mutex
+		var consumed []int
+		var consumers sync.WaitGroup
+		for c := 0; c < waiters; c++ {
+			consumers.Add(1)
+			go func() {
+				defer consumers.Done()
+				for {
+					v, err := q.PopWait(context.Background())
+					if err != nil {
+						return
+					}
+					lock.Lock()
+					consumed = append(consumed, v)
+					lock.Unlock()
+				}
+			}()
+		}
+
+		producers.Wait()
+		q.Close()
+		consumers.Wait()
+
+		slices.Sort(consumed)
+		require.Equal(t, expected, consumed, "Should consume every item exactly once")
+	})
+}
+
+// waitResult is what a waiting goroutine got back
+type waitResult struct {
+	value int
+	err   error
+}
+
+// popWaiters starts goroutines that each wait to pop an item
+func popWaiters(ctx context.Context, q collections.BlockingQueue[int], count int) chan waitResult {
+	results := make(chan waitResult, count)
+	for i := 0; i < count; i++ {
+		go func() {
+			v, err := q.PopWait(ctx)
+			results <- waitResult{value: v, err: err}
+		}()
+	}
+
+	return results
+}
+
+// pushWaiters starts goroutines that each wait to push an item
+func pushWaiters(ctx context.Context, q collections.BlockingQueue[int], count int) chan waitResult {
+	results := make(chan waitResult, count)
+	for i := 0; i < count; i++ {
+		go func(i int) {
+			results <- waitResult{value: i, err: q.PushWait(ctx, i)}
+		}(i)
+	}
+
+	return results
+}
+
+// requireBlocked checks no waiter returns for a while
+func requireBlocked(t *testing.T, results chan waitResult) {
+	t.Helper()
+
+	select {
+	case result := <-results:
+		require.Fail(t, "Should still be waiting", "Returned %v, %v", result.value, result.err)
+	case <-time.After(blockedFor):
+	}
+}
+
+// requireWoken waits for a waiter to return
+func requireWoken(t *testing.T, results chan waitResult) waitResult {
+	t.Helper()
+
+	select {
+	case result := <-results:
+		return result
+	case <-time.After(wakeWithin):
+		require.Fail(t, "Should have been woken")
+		return waitResult{}
+	}
+}
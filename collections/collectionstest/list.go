@@ -0,0 +1,84 @@
+package collectionstest
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// RunListSuite checks a list finds, indexes and removes its items as the model does.
+// The order describes how indexes follow insertion: with FIFO the oldest item is at
+// index zero, and with LIFO the newest is. The list is built once per test with
+// newList.
+func RunListSuite(t *testing.T, order Order, newList func() collections.List[int], opts ...Option) {
+	o := defaultOptions(opts)
+
+	t.Run(fmt.Sprintf("%v_Empty", order), func(t *testing.T) {
+		l := newList()
+		requireListMatches(t, l, nil)
+		require.False(t, l.Contains(0), "Should not contain anything")
+	})
+
+	t.Run(fmt.Sprintf("%v_Model", order), func(t *testing.T) {
+		l := newList()
+		rnd := rand.New(rand.NewSource(o.seed))
+
+		// Values are drawn from a small range, so most are held more than once
+		var expected []int
+		for i := 0; i < o.operations; i++ {
+			v := rnd.Intn(20)
+			switch op := rnd.Intn(10); {
+			case op < 5:
+				require.NoError(t, l.Insert(v), "Should insert %d", v)
+				if order == LIFO {
+					expected = slices.Insert(expected, 0, v)
+				} else {
+					expected = append(expected, v)
+				}
+			case op < 7:
+				l.Remove(v)
+				expected = slices.DeleteFunc(expected, func(e int) bool { return e == v })
+			case op < 9 && len(expected) > 0:
+				at := rnd.Intn(len(expected))
+				l.RemoveAt(at)
+				expected = slices.Delete(expected, at, at+1)
+			default:
+				require.Panics(t, func() { l.RemoveAt(len(expected)) }, "Should not remove beyond the end")
+			}
+
+			requireListMatches(t, l, expected)
+		}
+	})
+}
+
+// requireListMatches checks every read of a list against the model, which holds the
+// items in index order
+func requireListMatches(t *testing.T, l collections.List[int], expected []int) {
+	t.Helper()
+
+	for i, v := range expected {
+		found, value := l.Value(i)
+		require.True(t, found, "Should have a value at %d", i)
+		require.Equal(t, v, value, "Should have the right value at %d", i)
+	}
+
+	found, value := l.Value(len(expected))
+	require.False(t, found, "Should have no value beyond the end")
+	require.Zero(t, value, "Should have the zero value beyond the end")
+	found, _ = l.Value(-1)
+	require.False(t, found, "Should have no value before the start")
+
+	for v := 0; v < 20; v++ {
+		at := slices.Index(expected, v)
+		if at < 0 {
+			at = collections.IndexNotFound
+		}
+		require.Equal(t, at, l.IndexOf(v), "Should find the first index of %d", v)
+		require.Equal(t, at >= 0, l.Contains(v), "Should know if it contains %d", v)
+	}
+}
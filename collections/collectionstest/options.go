@@ -0,0 +1,69 @@
+package collectionstest
+
+// Option configures how a suite exercises an implementation
+type Option func(o *options)
+
+// options holds the behaviours selected for a suite
+type options struct {
+	seed       int64
+	operations int
+	bounded    bool
+	uniqueKeys bool
+	summed     bool
+}
+
+// defaultOptions are used for anything not configured
+func defaultOptions(opts []Option) options {
+	result := options{
+		seed:       1,
+		operations: 2000,
+	}
+	for _, opt := range opts {
+		opt(&result)
+	}
+
+	return result
+}
+
+// WithSeed sets the seed of the random source the operations are drawn from. Suites
+// run with the same seed perform the same operations.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+	}
+}
+
+// WithOperations sets the number of random operations a suite performs in each of its
+// tests.
+func WithOperations(count int) Option {
+	return func(o *options) {
+		o.operations = count
+	}
+}
+
+// WithBoundedCapacity tells RunQueueSuite the queue holds no more than the capacity it
+// is built with, and refuses pushes beyond it with collections.ErrBufferFull. Without
+// this option the queue is built with collections.CapacityInfinite and is expected to
+// take every push.
+func WithBoundedCapacity() Option {
+	return func(o *options) {
+		o.bounded = true
+	}
+}
+
+// WithUniqueKeys tells RunTreeMapSuite the map holds at most one record per key, so
+// inserting a key that already exists replaces the value of its record.
+func WithUniqueKeys() Option {
+	return func(o *options) {
+		o.uniqueKeys = true
+	}
+}
+
+// WithSummedAggregate tells RunTreeMapSuite the map was built to aggregate its values
+// by adding them up, with an identity of zero. Without this option, Aggregate is
+// expected to panic with collections.ErrNoAggregate.
+func WithSummedAggregate() Option {
+	return func(o *options) {
+		o.summed = true
+	}
+}
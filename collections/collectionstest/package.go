@@ -0,0 +1,42 @@
+// Package collectionstest checks implementations of the collections interfaces honour
+// their contracts. Each suite drives an implementation with a long, randomized sequence
+// of operations, and checks every result against a simple reference model held
+// alongside it. Implementations outside this module can run the same suites from their
+// own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		collectionstest.RunQueueSuite(t, collectionstest.FIFO, func(capacity int) collections.Queue[int] {
+//			return mypackage.NewQueue[int](capacity)
+//		}, collectionstest.WithBoundedCapacity())
+//	}
+//
+// The sequences are drawn from a seeded random source, so a failure can be replayed by
+// running the suite again with the same seed.
+package collectionstest
+
+// Order is the order an implementation hands its items back in
+type Order int
+
+const (
+	// FIFO implementations hand back the oldest item first
+	FIFO Order = iota
+
+	// LIFO implementations hand back the newest item first
+	LIFO
+
+	// MinFirst implementations hand back the smallest item first, as priority queues
+	// ordered by the natural order of their items do
+	MinFirst
+)
+
+// String gets the name of the order, as used to name the tests
+func (o Order) String() string {
+	switch o {
+	case LIFO:
+		return "LIFO"
+	case MinFirst:
+		return "MinFirst"
+	default:
+		return "FIFO"
+	}
+}
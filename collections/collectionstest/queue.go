@@ -0,0 +1,160 @@
+package collectionstest
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// boundedCapacities are the capacities bounded queues are built with. The smallest
+// are full or empty after nearly every operation, which is where mistakes tend to be.
+var boundedCapacities = []int{1, 2, 7, 32}
+
+// RunQueueSuite checks a queue hands its items back in the order given, keeps count of
+// them, and, with WithBoundedCapacity, refuses items beyond its capacity. The queue is
+// built once per test with newQueue, given the capacity to build it with.
+func RunQueueSuite(t *testing.T, order Order, newQueue func(capacity int) collections.Queue[int], opts ...Option) {
+	o := defaultOptions(opts)
+
+	capacities := []int{collections.CapacityInfinite}
+	if o.bounded {
+		capacities = boundedCapacities
+	}
+
+	for _, capacity := range capacities {
+		name := fmt.Sprintf("%v_Capacity=%d", order, capacity)
+		if capacity == collections.CapacityInfinite {
+			name = fmt.Sprintf("%v_Unbounded", order)
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("Empty", func(t *testing.T) {
+				q := newQueue(capacity)
+				require.Equal(t, capacity, q.Capacity(), "Should have the capacity it was built with")
+				requireQueueMatches(t, q, order, nil)
+
+				found, v := q.Pop()
+				require.False(t, found, "Should have nothing to pop")
+				require.Zero(t, v, "Should pop the zero value")
+			})
+
+			t.Run("FillAndDrain", func(t *testing.T) {
+				q := newQueue(capacity)
+				rnd := rand.New(rand.NewSource(o.seed))
+
+				size := capacity
+				if size == collections.CapacityInfinite {
+					size = 100
+				}
+
+				// Going round more than once moves the ends of circular buffers through
+				// every position
+				for round := 0; round < 3; round++ {
+					var expected []int
+					for i := 0; i < size; i++ {
+						v := rnd.Int()
+						require.NoError(t, q.Push(v), "Should push item %d", i)
+						expected = append(expected, v)
+					}
+					if o.bounded {
+						requireFull(t, q, order, expected)
+					}
+
+					for len(expected) > 0 {
+						expected = requirePop(t, q, order, expected)
+					}
+					requireQueueMatches(t, q, order, nil)
+				}
+			})
+
+			t.Run("Model", func(t *testing.T) {
+				q := newQueue(capacity)
+				rnd := rand.New(rand.NewSource(o.seed))
+
+				var expected []int
+				for i := 0; i < o.operations; i++ {
+					// Lean towards pushing or popping for a while at a time, so the queue
+					// keeps reaching both full and empty
+					pushing := 0.3
+					if (i/50)%2 == 0 {
+						pushing = 0.7
+					}
+
+					switch {
+					case rnd.Float64() < pushing:
+						v := rnd.Int()
+						if o.bounded && len(expected) == capacity {
+							requireFull(t, q, order, expected)
+							continue
+						}
+						require.NoError(t, q.Push(v), "Should push with %d items held", len(expected))
+						expected = append(expected, v)
+					case len(expected) > 0:
+						expected = requirePop(t, q, order, expected)
+					default:
+						found, _ := q.Pop()
+						require.False(t, found, "Should have nothing to pop")
+					}
+
+					requireQueueMatches(t, q, order, expected)
+				}
+			})
+		})
+	}
+}
+
+// requireQueueMatches checks the count and next item of a queue against the model,
+// which holds the items in the order they were pushed
+func requireQueueMatches(t *testing.T, q collections.Queue[int], order Order, expected []int) {
+	t.Helper()
+
+	require.Equal(t, len(expected), q.Count(), "Should count the items held")
+
+	found, v := q.Peek()
+	if len(expected) == 0 {
+		require.False(t, found, "Should have nothing to peek")
+		require.Zero(t, v, "Should peek the zero value")
+		return
+	}
+
+	require.True(t, found, "Should have an item to peek")
+	require.Equal(t, expected[next(order, expected)], v, "Should peek the next item")
+}
+
+// requirePop pops an item, checking it is the one the model expects, and gets the
+// model without it
+func requirePop(t *testing.T, q collections.Queue[int], order Order, expected []int) []int {
+	t.Helper()
+
+	at := next(order, expected)
+	found, v := q.Pop()
+	require.True(t, found, "Should have an item to pop")
+	require.Equal(t, expected[at], v, "Should pop the next item")
+
+	return append(expected[:at], expected[at+1:]...)
+}
+
+// requireFull checks a full queue refuses a push, and is left as it was
+func requireFull(t *testing.T, q collections.Queue[int], order Order, expected []int) {
+	t.Helper()
+
+	require.ErrorIs(t, q.Push(-1), collections.ErrBufferFull, "Should refuse to push beyond capacity")
+	requireQueueMatches(t, q, order, expected)
+}
+
+// next gets the index of the item the model hands back next
+func next(order Order, expected []int) int {
+	switch order {
+	case LIFO:
+		return len(expected) - 1
+	case MinFirst:
+		return slices.Index(expected, slices.Min(expected))
+	default:
+		return 0
+	}
+}
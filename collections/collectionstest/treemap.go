@@ -0,0 +1,434 @@
+package collectionstest
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// keySpace is the range keys are drawn from. It is small enough that most keys are
+// written more than once.
+const keySpace = 200
+
+// unknownID marks a record of the model whose ID is only learned from the map, such as
+// one given a new ID by Merge
+const unknownID = collections.RecordID(-1)
+
+// treeRecord is a record of the model a map is checked against
+type treeRecord struct {
+	key   int
+	value int
+	id    collections.RecordID
+}
+
+// treeModel holds records in the order a map should: by key, then in the order they
+// were inserted
+type treeModel []treeRecord
+
+// add inserts a record after any others with the same key
+func (m treeModel) add(key int, value int, id collections.RecordID) treeModel {
+	return slices.Insert(m, m.upper(key), treeRecord{key: key, value: value, id: id})
+}
+
+// lower gets the index of the first record with a key at or above the key
+func (m treeModel) lower(key int) int {
+	at, _ := slices.BinarySearchFunc(m, key, func(r treeRecord, k int) int {
+		return cmp.Compare(r.key, k)
+	})
+	return at
+}
+
+// upper gets the index of the first record with a key above the key
+func (m treeModel) upper(key int) int {
+	return m.lower(key + 1)
+}
+
+// first gets the index of the first record with a key, or -1 if there is none
+func (m treeModel) first(key int) int {
+	if at := m.lower(key); at < len(m) && m[at].key == key {
+		return at
+	}
+
+	return -1
+}
+
+// RunTreeMapSuite checks a map finds, orders, counts and moves its records as the model
+// does, through every method of collections.TreeMap. The map is built once per test
+// with newMap, and must order its keys ascending. Describe how it was built with
+// WithUniqueKeys and WithSummedAggregate.
+func RunTreeMapSuite(t *testing.T, newMap func() collections.TreeMap[int, int], opts ...Option) {
+	o := defaultOptions(opts)
+
+	t.Run("Empty", func(t *testing.T) {
+		m := newMap()
+		rnd := rand.New(rand.NewSource(o.seed))
+		requireTreeMatches(t, rnd, o, m, nil)
+
+		require.False(t, m.Delete(1), "Should have nothing to delete")
+		require.False(t, m.DeleteByID(1), "Should have no record to delete")
+		require.False(t, m.UpdateByID(1, 1), "Should have no record to update")
+		require.Zero(t, m.DeleteRange(0, keySpace), "Should have no records to delete")
+	})
+
+	t.Run("Model", func(t *testing.T) {
+		m := newMap()
+		rnd := rand.New(rand.NewSource(o.seed))
+
+		var expected treeModel
+		for i := 0; i < o.operations; i++ {
+			expected = randomWrite(t, rnd, o, m, expected)
+			if i%100 == 99 {
+				requireTreeMatches(t, rnd, o, m, expected)
+			}
+		}
+		requireTreeMatches(t, rnd, o, m, expected)
+
+		require.Zero(t, m.DeleteRange(10, 5), "Reversed bounds should delete nothing")
+		require.Zero(t, m.CountRange(10, 5), "Reversed bounds should count nothing")
+		require.Empty(t, m.Range(10, 5), "Reversed bounds should have no records")
+	})
+
+	t.Run("SplitAndMerge", func(t *testing.T) {
+		m := newMap()
+		rnd := rand.New(rand.NewSource(o.seed))
+		expected := fillTree(t, rnd, o, m)
+
+		for round := 0; round < 10; round++ {
+			key := rnd.Intn(keySpace+20) - 10
+			left, right := m.SplitAt(key)
+			split := expected.lower(key)
+			requireTreeMatches(t, rnd, o, left, expected[:split])
+			requireTreeMatches(t, rnd, o, right, expected[split:])
+			requireTreeMatches(t, rnd, o, m, nil)
+
+			// Merging into the emptied map gives every record a new ID
+			m.Merge(left)
+			m.Merge(right)
+			requireTreeMatches(t, rnd, o, left, nil)
+			requireTreeMatches(t, rnd, o, right, nil)
+			for i := range expected {
+				expected[i].id = unknownID
+			}
+			expected = learnIDs(t, m, expected)
+			requireTreeMatches(t, rnd, o, m, expected)
+
+			for i := 0; i < 50; i++ {
+				expected = randomWrite(t, rnd, o, m, expected)
+			}
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(o.seed))
+		for round := 0; round < 10; round++ {
+			m := newMap()
+			expected := fillTree(t, rnd, o, m)
+			other := newMap()
+			incoming := fillTree(t, rnd, o, other)
+
+			m.Merge(other)
+			requireTreeMatches(t, rnd, o, other, nil)
+			expected = learnIDs(t, m, mergeModel(o, expected, incoming))
+			requireTreeMatches(t, rnd, o, m, expected)
+		}
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		m := newMap()
+		rnd := rand.New(rand.NewSource(o.seed))
+		expected := fillTree(t, rnd, o, m)
+
+		snap := m.Snapshot()
+		held := slices.Clone(expected)
+		for i := 0; i < o.operations/4; i++ {
+			expected = randomWrite(t, rnd, o, m, expected)
+		}
+		requireTreeMatches(t, rnd, o, snap, held)
+		requireTreeMatches(t, rnd, o, m, expected)
+
+		for name, write := range map[string]func(){
+			"Insert":      func() { snap.Insert(1, 1) },
+			"Upsert":      func() { snap.Upsert(1, 1) },
+			"Update":      func() { snap.Update(1, func(old int, exists bool) int { return old }) },
+			"UpdateByID":  func() { snap.UpdateByID(1, 1) },
+			"Delete":      func() { snap.Delete(1) },
+			"DeleteByID":  func() { snap.DeleteByID(1) },
+			"DeleteRange": func() { snap.DeleteRange(0, 10) },
+			"SplitAt":     func() { snap.SplitAt(10) },
+			"Merge":       func() { snap.Merge(newMap()) },
+		} {
+			require.PanicsWithError(t, collections.ErrReadOnly.Error(), write, "%s of a snapshot should panic", name)
+		}
+
+		// Merging from a snapshot copies it
+		m.Merge(snap)
+		requireTreeMatches(t, rnd, o, snap, held)
+		expected = learnIDs(t, m, mergeModel(o, expected, held))
+		requireTreeMatches(t, rnd, o, m, expected)
+	})
+}
+
+// fillTree inserts random records into an empty map, getting the model of them
+func fillTree(t *testing.T, rnd *rand.Rand, o options, m collections.TreeMap[int, int]) treeModel {
+	t.Helper()
+
+	var expected treeModel
+	for i := rnd.Intn(300); i > 0; i-- {
+		k, v := rnd.Intn(keySpace), rnd.Intn(100)
+		id := m.Insert(k, v)
+		if at := expected.first(k); o.uniqueKeys && at >= 0 {
+			require.Equal(t, expected[at].id, id, "Should replace the record of %d", k)
+			expected[at].value = v
+			continue
+		}
+		expected = expected.add(k, v, id)
+	}
+
+	return expected
+}
+
+// mergeModel gets the model of a map once another has been merged into it. Incoming
+// records follow those already held with the same key, or replace them if keys are
+// unique. Either way, their IDs are only learned from the map.
+func mergeModel(o options, expected treeModel, incoming treeModel) treeModel {
+	for _, r := range incoming {
+		if at := expected.first(r.key); o.uniqueKeys && at >= 0 {
+			expected[at].value = r.value
+			expected[at].id = unknownID
+			continue
+		}
+		expected = expected.add(r.key, r.value, unknownID)
+	}
+
+	return expected
+}
+
+// randomWrite performs a random write against both the map and the model, checking its
+// result, and gets the updated model
+func randomWrite(t *testing.T, rnd *rand.Rand, o options, m collections.TreeMap[int, int], expected treeModel) treeModel {
+	t.Helper()
+
+	k, v := rnd.Intn(keySpace), rnd.Intn(100)
+	switch op := rnd.Intn(10); {
+	case op < 4:
+		id := m.Insert(k, v)
+		if at := expected.first(k); o.uniqueKeys && at >= 0 {
+			require.Equal(t, expected[at].id, id, "Should replace the record of %d", k)
+			expected[at].value = v
+		} else {
+			expected = expected.add(k, v, id)
+		}
+	case op == 4:
+		id, replaced := m.Upsert(k, v)
+		at := expected.first(k)
+		require.Equal(t, at >= 0, replaced, "Should replace the value of %d if it exists", k)
+		if replaced {
+			require.Equal(t, expected[at].id, id, "Should replace the first record of %d", k)
+			expected[at].value = v
+		} else {
+			expected = expected.add(k, v, id)
+		}
+	case op == 5:
+		id := m.Update(k, func(old int, exists bool) int {
+			if exists {
+				return old + 1
+			}
+			return v
+		})
+		if at := expected.first(k); at >= 0 {
+			require.Equal(t, expected[at].id, id, "Should update the first record of %d", k)
+			expected[at].value++
+		} else {
+			expected = expected.add(k, v, id)
+		}
+	case op == 6:
+		require.Equal(t, expected.first(k) >= 0, m.Delete(k), "Should delete %d if it exists", k)
+		expected = slices.DeleteFunc(expected, func(r treeRecord) bool { return r.key == k })
+	case op == 7 && len(expected) > 0:
+		at := rnd.Intn(len(expected))
+		require.True(t, m.DeleteByID(expected[at].id), "Should delete record %d", expected[at].id)
+		require.False(t, m.DeleteByID(expected[at].id), "Should only delete record %d once", expected[at].id)
+		expected = slices.Delete(expected, at, at+1)
+	case op == 8 && len(expected) > 0:
+		at := rnd.Intn(len(expected))
+		require.True(t, m.UpdateByID(expected[at].id, v), "Should update record %d", expected[at].id)
+		expected[at].value = v
+	case op == 9:
+		to := k + rnd.Intn(20)
+		before := len(expected)
+		expected = slices.DeleteFunc(expected, func(r treeRecord) bool { return r.key >= k && r.key <= to })
+		require.Equal(t, before-len(expected), m.DeleteRange(k, to), "Should delete the range %d to %d", k, to)
+	}
+
+	return expected
+}
+
+// learnIDs walks the map with a cursor to fill in the IDs the model doesn't know,
+// checking the IDs it does know were kept, and that every ID is distinct
+func learnIDs(t *testing.T, m collections.TreeMap[int, int], expected treeModel) treeModel {
+	t.Helper()
+
+	c := m.Seek(-1)
+	defer c.Close()
+
+	seen := map[collections.RecordID]bool{}
+	for i := range expected {
+		require.True(t, c.Next(), "Should have a record at %d", i)
+		require.Equal(t, expected[i].key, c.Key(), "Should have the right key at %d", i)
+		require.Equal(t, expected[i].value, c.Value(), "Should have the right value at %d", i)
+		if expected[i].id != unknownID {
+			require.Equal(t, expected[i].id, c.RecordID(), "Should keep the ID of the record at %d", i)
+		}
+		require.False(t, seen[c.RecordID()], "Should not reuse ID %d", c.RecordID())
+
+		seen[c.RecordID()] = true
+		expected[i].id = c.RecordID()
+	}
+	require.False(t, c.Next(), "Should have no more records")
+
+	return expected
+}
+
+// requireTreeMatches checks every read of a map against the model
+func requireTreeMatches(t *testing.T, rnd *rand.Rand, o options, m collections.TreeMap[int, int], expected treeModel) {
+	t.Helper()
+
+	var records []generics.KeyValuePair[int, int]
+	for _, r := range expected {
+		records = append(records, generics.KeyValuePair[int, int]{Key: r.key, Value: r.value})
+	}
+
+	var all []generics.KeyValuePair[int, int]
+	for k, v := range m.All() {
+		all = append(all, generics.KeyValuePair[int, int]{Key: k, Value: v})
+	}
+	require.Equal(t, records, all, "All should visit the records in order")
+
+	var backward []generics.KeyValuePair[int, int]
+	for k, v := range m.Backward() {
+		backward = append(backward, generics.KeyValuePair[int, int]{Key: k, Value: v})
+	}
+	slices.Reverse(backward)
+	require.Equal(t, records, backward, "Backward should visit the records in reverse")
+	require.Equal(t, len(expected), m.Count(), "Should count the records")
+
+	for _, r := range expected {
+		k, v, found := m.GetByID(r.id)
+		require.True(t, found, "Should find record %d", r.id)
+		require.Equal(t, []int{r.key, r.value}, []int{k, v}, "Should get record %d", r.id)
+	}
+
+	requireRecord := func(name string, probe int, at int, k int, v int, found bool) {
+		t.Helper()
+		if at < 0 || at >= len(expected) {
+			require.False(t, found, "%s of %d should not be found", name, probe)
+			return
+		}
+		require.True(t, found, "%s of %d should be found", name, probe)
+		require.Equal(t, expected[at].key, k, "%s of %d should have the right key", name, probe)
+		require.Equal(t, expected[at].value, v, "%s of %d should have the right value", name, probe)
+	}
+
+	k, v, found := m.Min()
+	requireRecord("Min", 0, 0, k, v, found)
+	k, v, found = m.Max()
+	requireRecord("Max", 0, len(expected)-1, k, v, found)
+
+	for i := 0; i < 20; i++ {
+		probe := rnd.Intn(keySpace+20) - 10
+		lower, upper := expected.lower(probe), expected.upper(probe)
+
+		v, found := m.Get(probe)
+		if lower < upper {
+			require.True(t, found, "Should find %d", probe)
+			require.Equal(t, expected[lower].value, v, "Should get the first value of %d", probe)
+		} else {
+			require.False(t, found, "Should not find %d", probe)
+		}
+
+		var values []int
+		for _, r := range expected[lower:upper] {
+			values = append(values, r.value)
+		}
+		require.Equal(t, values, m.GetAll(probe), "Should get every value of %d in the order inserted", probe)
+		require.Equal(t, lower, m.Rank(probe), "Should rank %d", probe)
+
+		k, v, found := m.Floor(probe)
+		requireRecord("Floor", probe, upper-1, k, v, found)
+		k, v, found = m.Lower(probe)
+		requireRecord("Lower", probe, lower-1, k, v, found)
+		k, v, found = m.Ceiling(probe)
+		requireRecord("Ceiling", probe, lower, k, v, found)
+		k, v, found = m.Higher(probe)
+		requireRecord("Higher", probe, upper, k, v, found)
+
+		selected := rnd.Intn(len(expected)+2) - 1
+		k, v, found = m.Select(selected)
+		requireRecord("Select", selected, selected, k, v, found)
+
+		to := probe + rnd.Intn(30)
+		end := expected.upper(to)
+		var within []generics.KeyValuePair[int, int]
+		within = append(within, records[lower:end]...)
+		require.Equal(t, within, m.Range(probe, to), "Should get the range %d to %d", probe, to)
+		require.Equal(t, end-lower, m.CountRange(probe, to), "Should count the range %d to %d", probe, to)
+
+		if o.summed {
+			total := 0
+			for _, r := range expected[lower:end] {
+				total += r.value
+			}
+			require.Equal(t, total, m.Aggregate(probe, to), "Should aggregate the range %d to %d", probe, to)
+		} else {
+			require.PanicsWithError(t, collections.ErrNoAggregate.Error(), func() { m.Aggregate(probe, to) }, "Should have no aggregate")
+		}
+
+		requireCursor(t, m, probe, expected)
+	}
+}
+
+// requireCursor seeks a key, then walks forward to the end and back to the start,
+// checking every record against the model
+func requireCursor(t *testing.T, m collections.TreeMap[int, int], key int, expected treeModel) {
+	t.Helper()
+
+	requireOn := func(c collections.Cursor[int, int], at int) {
+		t.Helper()
+		require.Equal(t, expected[at].key, c.Key(), "Cursor should have the key at %d", at)
+		require.Equal(t, expected[at].value, c.Value(), "Cursor should have the value at %d", at)
+		require.Equal(t, expected[at].id, c.RecordID(), "Cursor should have the ID at %d", at)
+	}
+
+	c := m.Seek(key)
+	defer c.Close()
+
+	at := expected.lower(key)
+	for ; at < len(expected); at++ {
+		require.True(t, c.Next(), "Cursor should move forward onto %d", at)
+		requireOn(c, at)
+	}
+	require.False(t, c.Next(), "Cursor should run off the end")
+
+	for at--; at >= 0; at-- {
+		require.True(t, c.Prev(), "Cursor should move back onto %d", at)
+		requireOn(c, at)
+	}
+	require.False(t, c.Prev(), "Cursor should run off the start")
+	require.NoError(t, c.Err(), "Running off either end isn't an error")
+
+	// Going back from the seek position finds the record before it
+	c = m.Seek(key)
+	if before := expected.lower(key) - 1; before >= 0 {
+		require.True(t, c.Prev(), "Cursor should move back from %d", key)
+		requireOn(c, before)
+	} else {
+		require.False(t, c.Prev(), "Cursor should have nothing before %d", key)
+	}
+	c.Close()
+}
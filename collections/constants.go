@@ -10,3 +10,19 @@ const (
 
 // ErrBufferFull indicates a buffer cannot be written to.
 var ErrBufferFull = errors.New("the buffer is full and cannot take more data")
+
+// ErrClosed indicates a queue was closed, so can't take more data, or has none left to
+// give.
+var ErrClosed = errors.New("the queue is closed")
+
+// ErrCursorInvalidated indicates a cursor can no longer move, as the structure beneath
+// it was modified.
+var ErrCursorInvalidated = errors.New("the cursor was invalidated by a concurrent modification")
+
+// ErrReadOnly indicates a write was attempted against a read-only view of a structure,
+// such as a snapshot.
+var ErrReadOnly = errors.New("the structure is read-only and cannot be modified")
+
+// ErrNoAggregate indicates an aggregate was asked of a structure that was not built to
+// keep one.
+var ErrNoAggregate = errors.New("the structure does not keep an aggregate")
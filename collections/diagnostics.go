@@ -7,6 +7,30 @@ type Diagnosable interface {
 	// Check consistency
 	CheckConsistency()
 
+	// Validate checks every invariant of the structure, returning an error for each
+	// violation found. An empty result means the structure is consistent. Unlike
+	// CheckConsistency, nothing is written out and nothing panics.
+	Validate() []error
+
+	// Stats gathers statistics about the shape of the structure
+	Stats() Stats
+
 	// Dump the content of a tree (Diagnostics)
 	Dump(file io.Writer)
+
+	// DumpDOT writes the structure out as a Graphviz DOT graph
+	DumpDOT(file io.Writer) error
+
+	// DumpJSON writes the structure out as JSON
+	DumpJSON(file io.Writer) error
+}
+
+// Stats describes the shape of a structure, for diagnostic purposes
+type Stats struct {
+	Height        int     // Number of levels, from the root down to the leaves
+	NodesPerLevel []int   // Number of nodes at each level, from the root down
+	FillHistogram [10]int // Nodes by how full they are in tenths, with the last bucket from 90% up to full
+	PoolHits      int64   // Allocations served by the pre-allocation pools
+	PoolMisses    int64   // Allocations the pre-allocation pools could not serve
+	Records       int     // Number of records held
 }
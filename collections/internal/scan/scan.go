@@ -0,0 +1,29 @@
+// Package scan backs the channel-based Scan of the collections with their iterators.
+package scan
+
+import (
+	"iter"
+
+	"github.com/zeroflucs-given/generics"
+)
+
+// Buffered reads every pair of an iteration into a channel with room for them all, then
+// closes it. Nothing is left running to feed the channel, and no lock is held once we
+// return, so a consumer can stop reading early without leaking anything.
+func Buffered[K any, V any](seq iter.Seq2[K, V]) chan generics.KeyValuePair[K, V] {
+	var pairs []generics.KeyValuePair[K, V]
+	for k, v := range seq {
+		pairs = append(pairs, generics.KeyValuePair[K, V]{
+			Key:   k,
+			Value: v,
+		})
+	}
+
+	output := make(chan generics.KeyValuePair[K, V], len(pairs))
+	for _, pair := range pairs {
+		output <- pair
+	}
+	close(output)
+
+	return output
+}
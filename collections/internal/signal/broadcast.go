@@ -0,0 +1,29 @@
+// Package signal lets goroutines wait for a structure to change alongside other events,
+// such as a context being done, which sync.Cond can't do.
+package signal
+
+// Broadcast wakes every goroutine waiting on it each time it is notified. It is guarded
+// by the lock of the structure it belongs to, which must be held to use it. The zero
+// value is ready to use.
+type Broadcast struct {
+	waiting chan struct{}
+}
+
+// Wait gets a channel that is closed the next time the broadcast is notified. Release
+// the lock before waiting on it.
+func (b *Broadcast) Wait() <-chan struct{} {
+	if b.waiting == nil {
+		b.waiting = make(chan struct{})
+	}
+
+	return b.waiting
+}
+
+// Notify wakes every goroutine waiting. It costs next to nothing when none are, so it
+// can be called on every change.
+func (b *Broadcast) Notify() {
+	if b.waiting != nil {
+		close(b.waiting)
+		b.waiting = nil
+	}
+}
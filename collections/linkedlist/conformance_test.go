@@ -0,0 +1,31 @@
+package linkedlist
+
+import (
+	"testing"
+
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/collectionstest"
+)
+
+// TestQueueConformance checks the list honours the Queue[T] contract, handing back the
+// oldest item first
+func TestQueueConformance(t *testing.T) {
+	collectionstest.RunQueueSuite(t, collectionstest.FIFO, func(capacity int) collections.Queue[int] {
+		return New[int]()
+	})
+}
+
+// TestListConformance checks the list honours the List[T] contract. Items are indexed
+// from the tail, so the newest is at index zero.
+func TestListConformance(t *testing.T) {
+	collectionstest.RunListSuite(t, collectionstest.LIFO, func() collections.List[int] {
+		return New[int]()
+	})
+}
+
+// TestBlockingQueueConformance checks the list honours the BlockingQueue[T] contract
+func TestBlockingQueueConformance(t *testing.T) {
+	collectionstest.RunBlockingQueueSuite(t, func(capacity int) collections.BlockingQueue[int] {
+		return New[int]()
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/internal/signal"
 )
 
 // New creates a new linked list.
@@ -13,9 +14,11 @@ func New[T comparable]() *LinkedList[T] {
 
 // LinkedList is our internal type for implementing the buffer pattern
 type LinkedList[T comparable] struct {
-	head *node[T]
-	tail *node[T]
-	lock sync.RWMutex
+	head    *node[T]
+	tail    *node[T]
+	lock    sync.RWMutex
+	closed  bool
+	changed signal.Broadcast // Notified as values are appended, and on close
 }
 
 // Capacity of this linked list
@@ -41,9 +44,14 @@ func (l *LinkedList[T]) Count() int {
 	return count
 }
 
-func (l *LinkedList[T]) appendInternal(value T) {
+func (l *LinkedList[T]) appendInternal(value T) error {
 	l.lock.Lock()
 
+	if l.closed {
+		l.lock.Unlock()
+		return collections.ErrClosed
+	}
+
 	// Build our new node and join to the tail
 	oldTail := l.tail
 	newTail := &node[T]{
@@ -60,7 +68,10 @@ func (l *LinkedList[T]) appendInternal(value T) {
 		l.head = newTail
 	}
 
+	l.changed.Notify()
 	l.lock.Unlock()
+
+	return nil
 }
 
 // node is a node in the linked list
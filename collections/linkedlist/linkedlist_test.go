@@ -157,3 +157,27 @@ func TestLinkedListValueAtHead(t *testing.T) {
 	require.Equal(t, 0, value, "Both values should be same")
 	require.True(t, hasValue, "Should have the value 4")
 }
+
+func TestLinkedListPopThenValue(t *testing.T) {
+	buff := New[int]()
+
+	for i := 0; i < 3; i++ {
+		err := buff.Push(i)
+		require.NoError(t, err, "Should not error")
+	}
+
+	hasValue, value := buff.Pop()
+	require.True(t, hasValue, "Should have a value to pop")
+	require.Equal(t, 0, value, "Should pop the oldest value")
+
+	require.False(t, buff.Contains(0), "Should no longer contain the popped value")
+	hasValue, _ = buff.Value(2)
+	require.False(t, hasValue, "Should not index the popped value")
+
+	buff.Pop()
+	buff.Pop()
+	require.False(t, buff.Contains(2), "Should contain nothing once emptied")
+	require.NoError(t, buff.Push(3), "Should not error")
+	require.Equal(t, collections.IndexNotFound, buff.IndexOf(2), "Should not index the popped values")
+	require.Equal(t, 1, buff.Count(), "Should have a count of one")
+}
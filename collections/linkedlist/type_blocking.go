@@ -0,0 +1,55 @@
+package linkedlist
+
+import (
+	"context"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// Ensure our LinkedList implements the generic BlockingQueue[T] interface at compile
+// time.
+var _ collections.BlockingQueue[int] = (*LinkedList[int])(nil)
+
+// PushWait pushes a value into the linked list. The list is never full, so this never
+// waits, though it still fails if the context is already done.
+func (l *LinkedList[T]) PushWait(ctx context.Context, value T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return l.appendInternal(value)
+}
+
+// PopWait pops a value from the list, waiting for one if it is empty
+func (l *LinkedList[T]) PopWait(ctx context.Context) (T, error) {
+	for {
+		l.lock.Lock()
+
+		found, result := l.popInternal()
+		if found {
+			l.lock.Unlock()
+			return result, nil
+		}
+		if l.closed {
+			l.lock.Unlock()
+			return result, collections.ErrClosed
+		}
+
+		changed := l.changed.Wait()
+		l.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+}
+
+// Close the list to further pushes and inserts, waking everything waiting on it
+func (l *LinkedList[T]) Close() {
+	l.lock.Lock()
+	l.closed = true
+	l.changed.Notify()
+	l.lock.Unlock()
+}
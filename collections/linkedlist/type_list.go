@@ -14,10 +14,9 @@ func (l *LinkedList[T]) Contains(v T) bool {
 	return l.IndexOf(v) != collections.IndexNotFound
 }
 
-// Insert appends an item to the list
+// Insert appends an item to the list. Returns an error if the list has been closed.
 func (l *LinkedList[T]) Insert(v T) error {
-	l.appendInternal(v)
-	return nil
+	return l.appendInternal(v)
 }
 
 // Remove removes all instances of the specified value from the list
@@ -47,10 +46,13 @@ func (l *LinkedList[T]) Remove(v T) {
 			if current == l.tail {
 				l.tail = current.head
 			}
+		} else {
+			// Only items we keep can be linked to, so a run of removed items is cut
+			// out whole
+			previousTail = current
 		}
 
 		// Move forward in the list
-		previousTail = current
 		current = current.head
 	}
 
@@ -63,6 +65,7 @@ func (l *LinkedList[T]) RemoveAt(index int) {
 
 	var previousTail *node[T]
 	var currentIndex int
+	var removed bool
 
 	current := l.tail
 	for current != nil {
@@ -87,6 +90,8 @@ func (l *LinkedList[T]) RemoveAt(index int) {
 			if current == l.tail {
 				l.tail = current.head
 			}
+
+			removed = true
 		}
 
 		// Done
@@ -104,7 +109,7 @@ func (l *LinkedList[T]) RemoveAt(index int) {
 
 	l.lock.Unlock()
 
-	if currentIndex < index {
+	if !removed {
 		panic(fmt.Sprintf("The index %d is beyond the bounds of the LinkedList", index))
 	}
 }
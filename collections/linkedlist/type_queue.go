@@ -26,24 +26,35 @@ func (l *LinkedList[T]) Peek() (bool, T) {
 
 // Pop a value from the list
 func (l *LinkedList[T]) Pop() (bool, T) {
+	l.lock.Lock()
+	found, result := l.popInternal()
+	l.lock.Unlock()
+
+	return found, result
+}
+
+// Push a value into the linked list. Returns an error if the list has been closed.
+func (l *LinkedList[T]) Push(value T) error {
+	return l.appendInternal(value)
+}
+
+// popInternal pops a value with the lock held
+func (l *LinkedList[T]) popInternal() (bool, T) {
 	var found bool
 	var result T
 
-	l.lock.Lock()
-
 	if l.head != nil {
 		result = l.head.value
 		l.head = l.head.tail // Move backward
 		found = true
-	}
 
-	l.lock.Unlock()
+		// Unlink the popped node, so walks from the tail stop short of it
+		if l.head != nil {
+			l.head.head = nil
+		} else {
+			l.tail = nil
+		}
+	}
 
 	return found, result
 }
-
-// Push a value into the linked list
-func (l *LinkedList[T]) Push(value T) error {
-	l.appendInternal(value)
-	return nil
-}
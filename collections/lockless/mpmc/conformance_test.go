@@ -0,0 +1,16 @@
+package mpmc
+
+import (
+	"testing"
+
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/collectionstest"
+)
+
+// TestQueueConformance checks the buffer honours the Queue[T] contract, within its
+// capacity
+func TestQueueConformance(t *testing.T) {
+	collectionstest.RunQueueSuite(t, collectionstest.FIFO, func(capacity int) collections.Queue[int] {
+		return New[int](capacity)
+	}, collectionstest.WithBoundedCapacity())
+}
@@ -0,0 +1,22 @@
+// Package mpmc contains a bounded, lock-free ring buffer that any number of
+// goroutines can push to and pop from at once. It allows for storage of N items of a
+// type T with FIFO semantics, and pushing more than the buffer can hold will generate
+// an error.
+//
+// Rather than a mutex, each slot of the buffer carries a sequence number recording
+// which lap of the buffer it is ready for, and whether it holds an item (after Dmitry
+// Vyukov's bounded MPMC queue). Producers and consumers each claim a position with a
+// single compare-and-swap, then hand the slot over to the other side by advancing its
+// sequence. Neither side ever waits on the other, so a goroutine that stalls part way
+// through a push or pop only holds up the slot it claimed.
+//
+// A few things follow from that:
+//
+//   - A push can fail as full while a pop of the slot it needs is still under way, and
+//     a pop can find nothing while a push of the slot it needs is still under way.
+//   - Count is a moment-in-time estimate whenever pushes and pops are in flight.
+//   - Peek reads the next item in place, so it must not run at the same time as a Pop
+//     from another goroutine. Pushes may carry on around it.
+//
+// If only one goroutine ever pushes and another pops, the spsc package is faster.
+package mpmc
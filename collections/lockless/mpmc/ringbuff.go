@@ -0,0 +1,71 @@
+package mpmc
+
+import (
+	"sync/atomic"
+)
+
+// cacheLine is the size of the cache lines the positions are kept apart by, so that
+// producers and consumers don't slow each other down by writing to the same line.
+const cacheLine = 64
+
+// New creates a lock-free ring buffer holding up to size items.
+func New[T any](size int) *RingBuffer[T] {
+	b := &RingBuffer[T]{
+		capacity: uint64(max(size, 0)),
+		slots:    make([]slot[T], max(size, 0)),
+	}
+
+	// Every slot starts out ready for a push on the first lap
+	for i := range b.slots {
+		b.slots[i].sequence.Store(pushReady(uint64(i)))
+	}
+
+	return b
+}
+
+// RingBuffer is a bounded queue that many goroutines can push to and pop from without
+// taking a lock. Peek is the exception: it reads the next item in place, so is only
+// safe from a goroutine that is the sole consumer, with no Pop running alongside it.
+// Where consumers race, pop the item instead.
+type RingBuffer[T any] struct {
+	_        [cacheLine]byte
+	tail     atomic.Uint64 // Position of the next push
+	_        [cacheLine - 8]byte
+	head     atomic.Uint64 // Position of the next pop
+	_        [cacheLine - 8]byte
+	capacity uint64
+	slots    []slot[T]
+}
+
+// slot holds an item, along with the sequence that says who may use it next. For the
+// push at position p, the slot is ready once its sequence reaches pushReady(p), and
+// for the pop at p once it reaches popReady(p).
+type slot[T any] struct {
+	sequence atomic.Uint64
+	value    T
+}
+
+// pushReady is the sequence of a slot that is empty, ready for the push at a position
+func pushReady(position uint64) uint64 {
+	return 2 * position
+}
+
+// popReady is the sequence of a slot holding the item pushed at a position. Keeping it
+// apart from pushReady(position+1) lets a buffer of one slot tell full from empty.
+func popReady(position uint64) uint64 {
+	return 2*position + 1
+}
+
+// Capacity of the buffer
+func (b *RingBuffer[T]) Capacity() int {
+	return int(b.capacity)
+}
+
+// Count the number of records in the buffer
+func (b *RingBuffer[T]) Count() int {
+	// The head is loaded first, so the tail is never behind it
+	head := b.head.Load()
+	tail := b.tail.Load()
+
+	return int(min(tail-head, b.capacity))
+}
@@ -0,0 +1,202 @@
+package mpmc
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	collections "github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/ringbuffer"
+)
+
+// TestRingBufferZeroCapacity checks a buffer with no room refuses every push
+func TestRingBufferZeroCapacity(t *testing.T) {
+	buff := New[int](0)
+
+	require.Equal(t, 0, buff.Capacity(), "Should have no capacity")
+	require.ErrorIs(t, buff.Push(1), collections.ErrBufferFull, "Should refuse a push")
+	hasValue, _ := buff.Pop()
+	require.False(t, hasValue, "Should have nothing to pop")
+	hasValue, _ = buff.Peek()
+	require.False(t, hasValue, "Should have nothing to peek")
+	require.Zero(t, buff.Count(), "Should hold nothing")
+}
+
+// TestRingBufferContention has producers and consumers hammer a small buffer at once,
+// checking every item comes out exactly once, and that each consumer sees the items of
+// each producer in the order they were pushed. Run with -race to check the hand-over of
+// slots.
+func TestRingBufferContention(t *testing.T) {
+	const producers = 4
+	const consumers = 4
+	const perProducer = 20000
+
+	buff := New[int](8)
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				for buff.Push(p*perProducer+i) != nil {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	var remaining atomic.Int64
+	remaining.Store(producers * perProducer)
+	seen := make([]atomic.Int32, producers*perProducer)
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := make([]int, producers)
+			for p := range last {
+				last[p] = -1
+			}
+
+			for remaining.Load() > 0 {
+				count := buff.Count()
+				if count < 0 || count > buff.Capacity() {
+					t.Errorf("Count %d should be within the capacity", count)
+					return
+				}
+
+				hasValue, v := buff.Pop()
+				if !hasValue {
+					runtime.Gosched()
+					continue
+				}
+				remaining.Add(-1)
+				seen[v].Add(1)
+
+				p, i := v/perProducer, v%perProducer
+				if i <= last[p] {
+					t.Errorf("Item %d of producer %d came after item %d", i, p, last[p])
+					return
+				}
+				last[p] = i
+			}
+		}()
+	}
+
+	wg.Wait()
+	for v := range seen {
+		require.Equal(t, int32(1), seen[v].Load(), "Should pop item %d exactly once", v)
+	}
+	require.Zero(t, buff.Count(), "Should be empty")
+	hasValue, _ := buff.Pop()
+	require.False(t, hasValue, "Should have nothing left to pop")
+}
+
+// TestRingBufferPeekAlongsidePushes peeks from the only consumer while producers push,
+// checking it always sees the item it then pops
+func TestRingBufferPeekAlongsidePushes(t *testing.T) {
+	const producers = 4
+	const perProducer = 5000
+
+	buff := New[int](4)
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				for buff.Push(p*perProducer+i) != nil {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	for popped := 0; popped < producers*perProducer; {
+		peeked, expected := buff.Peek()
+		if !peeked {
+			runtime.Gosched()
+			continue
+		}
+
+		hasValue, v := buff.Pop()
+		require.True(t, hasValue, "Should pop what was peeked")
+		require.Equal(t, expected, v, "Should pop what was peeked")
+		popped++
+	}
+	wg.Wait()
+}
+
+// BenchmarkContention cycles items through a buffer from many goroutines at once,
+// comparing this buffer with the mutex-guarded one of the ringbuffer package.
+func BenchmarkContention(b *testing.B) {
+	targets := []struct {
+		name  string
+		build func() collections.Queue[int]
+	}{
+		{name: "LockFree", build: func() collections.Queue[int] { return New[int](1024) }},
+		{name: "Mutex", build: func() collections.Queue[int] { return ringbuffer.New[int](1024) }},
+	}
+
+	for _, target := range targets {
+		for _, parallelism := range []int{1, 4, 16} {
+			b.Run(fmt.Sprintf("%s_Goroutines=%d", target.name, parallelism), func(b *testing.B) {
+				buff := target.build()
+				b.SetParallelism(parallelism)
+				b.ResetTimer()
+
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						for buff.Push(i) != nil {
+							runtime.Gosched()
+						}
+						for {
+							if hasValue, _ := buff.Pop(); hasValue {
+								break
+							}
+							runtime.Gosched()
+						}
+						i++
+					}
+				})
+			})
+		}
+	}
+}
+
+// BenchmarkRingBuffer tests how fast we can cycle data through the ring-buffer from a
+// single goroutine
+func BenchmarkRingBuffer(b *testing.B) {
+	buff := New[int](32)
+	for i := 0; i < buff.Capacity()/2; i++ {
+		err := buff.Push(i)
+		if err != nil {
+			b.Log(err)
+			b.FailNow()
+		}
+	}
+
+	var i int
+	for b.Loop() {
+		// Put one in
+		err := buff.Push(i)
+		if err != nil {
+			b.Log(err)
+			b.FailNow()
+		}
+
+		// Pop one
+		hasItem, _ := buff.Pop()
+		if !hasItem {
+			b.Log("Should have had item")
+			b.FailNow()
+		}
+		i++
+	}
+}
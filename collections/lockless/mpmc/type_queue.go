@@ -0,0 +1,96 @@
+package mpmc
+
+import (
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// Ensure we meet the Queue[T] interface at compile time
+var _ collections.Queue[int] = (*RingBuffer[int])(nil)
+
+// Peek an item from the ring buffer. Unlike the other methods, this is not safe for
+// concurrent consumers: it must not run at the same time as a Pop from another
+// goroutine, which could free the slot being read for a push to overwrite.
+func (b *RingBuffer[T]) Peek() (bool, T) {
+	var blank T
+	if b.capacity == 0 {
+		return false, blank
+	}
+
+	for {
+		position := b.head.Load()
+		s := &b.slots[position%b.capacity]
+
+		switch distance := int64(s.sequence.Load() - popReady(position)); {
+		case distance == 0:
+			return true, s.value
+		case distance < 0:
+			return false, blank // Nothing has been pushed here yet
+		}
+
+		// Otherwise the item was popped since we loaded the head, so look again
+	}
+}
+
+// Pop an item from the ring buffer
+func (b *RingBuffer[T]) Pop() (bool, T) {
+	var blank T
+	if b.capacity == 0 {
+		return false, blank
+	}
+
+	position := b.head.Load()
+	for {
+		s := &b.slots[position%b.capacity]
+
+		switch distance := int64(s.sequence.Load() - popReady(position)); {
+		case distance == 0:
+			// The slot holds our item, if we can claim the position before another
+			// consumer does
+			if !b.head.CompareAndSwap(position, position+1) {
+				position = b.head.Load()
+				continue
+			}
+
+			result := s.value
+			s.value = blank
+			s.sequence.Store(pushReady(position + b.capacity))
+
+			return true, result
+		case distance < 0:
+			return false, blank // Empty, or the push is still under way
+		default:
+			position = b.head.Load() // Another consumer got here first
+		}
+	}
+}
+
+// Push an item into the ring buffer. Returns an error if the buffer is full.
+func (b *RingBuffer[T]) Push(item T) error {
+	if b.capacity == 0 {
+		return collections.ErrBufferFull
+	}
+
+	position := b.tail.Load()
+	for {
+		s := &b.slots[position%b.capacity]
+
+		switch distance := int64(s.sequence.Load() - pushReady(position)); {
+		case distance == 0:
+			// The slot is free, if we can claim the position before another producer
+			// does
+			if !b.tail.CompareAndSwap(position, position+1) {
+				position = b.tail.Load()
+				continue
+			}
+
+			s.value = item
+			s.sequence.Store(popReady(position))
+
+			return nil
+		case distance < 0:
+			return collections.ErrBufferFull // Full, or the pop is still under way
+		default:
+			position = b.tail.Load() // Another producer got here first
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package priorityqueue
+
+import (
+	"testing"
+
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/collectionstest"
+)
+
+// TestQueueConformance checks the queue honours the Queue[T] contract, both unbounded
+// and within its capacity, for binary heaps and the default arity
+func TestQueueConformance(t *testing.T) {
+	for _, arity := range []int{2, DefaultArity} {
+		collectionstest.RunQueueSuite(t, collectionstest.MinFirst, func(capacity int) collections.Queue[int] {
+			return New[int](capacity, WithArity(arity))
+		})
+		collectionstest.RunQueueSuite(t, collectionstest.MinFirst, func(capacity int) collections.Queue[int] {
+			return New[int](capacity, WithArity(arity))
+		}, collectionstest.WithBoundedCapacity())
+	}
+}
@@ -0,0 +1,47 @@
+package priorityqueue
+
+// Handle refers to an item pushed with PushHandle, wherever it has moved to in the
+// heap. A handle stops being valid once its item is popped or removed.
+type Handle[T any] struct {
+	index int // Where the item is in the heap, or -1 once it has left
+}
+
+// Valid checks the item of a handle is still in this queue
+func (q *PriorityQueue[T]) Valid(h *Handle[T]) bool {
+	return q.owns(h)
+}
+
+// PushHandle pushes an item into the queue, returning a handle that can later be used
+// to Fix or Remove it. Returns an error if the queue is full.
+func (q *PriorityQueue[T]) PushHandle(item T) (*Handle[T], error) {
+	return q.push(item, &Handle[T]{})
+}
+
+// Fix replaces the item of a handle, moving it to its new place in the heap. Returns
+// false if the handle's item is no longer in this queue.
+func (q *PriorityQueue[T]) Fix(h *Handle[T], item T) bool {
+	if !q.owns(h) {
+		return false
+	}
+
+	q.entries[h.index].item = item
+	q.fix(h.index)
+
+	return true
+}
+
+// Remove takes the item of a handle out of the queue, wherever it is in the heap.
+// Returns false if the handle's item is no longer in this queue.
+func (q *PriorityQueue[T]) Remove(h *Handle[T]) (bool, T) {
+	if !q.owns(h) {
+		var blank T
+		return false, blank
+	}
+
+	return true, q.removeAt(h.index)
+}
+
+// owns checks a handle refers to an item in this queue
+func (q *PriorityQueue[T]) owns(h *Handle[T]) bool {
+	return h != nil && h.index >= 0 && h.index < len(q.entries) && q.entries[h.index].handle == h
+}
@@ -0,0 +1,13 @@
+// Package priorityqueue contains a non-thread safe priority queue, for scenarios that
+// do not require the weight associated with sync.Mutex. If you want a thread-safe
+// version, use the priorityqueue package.
+//
+// The queue is a d-ary heap, ordered by a comparator, that hands back the smallest item
+// first. Reverse the comparator to hand back the largest first. Each node of the heap
+// has d children rather than two, which makes the heap shallower: pushes get cheaper,
+// and pops compare more children at each level but touch fewer levels, which suits the
+// cache better. The default of four children is a good balance for most workloads.
+//
+// Items pushed with PushHandle can later be changed or removed wherever they are in
+// the heap, by passing their handle to Fix or Remove.
+package priorityqueue
@@ -0,0 +1,178 @@
+package priorityqueue
+
+import (
+	"cmp"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// New creates a priority queue that hands back the smallest item first, holding up to
+// capacity items, or collections.CapacityInfinite for no limit.
+func New[T generics.Comparable](capacity int, opts ...Option) *PriorityQueue[T] {
+	return NewFunc(capacity, cmp.Compare[T], opts...)
+}
+
+// NewFunc creates a priority queue that hands back the smallest item first according
+// to the comparator, holding up to capacity items, or collections.CapacityInfinite for
+// no limit.
+func NewFunc[T any](capacity int, compare func(a, b T) int, opts ...Option) *PriorityQueue[T] {
+	o := options{
+		arity: DefaultArity,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	q := &PriorityQueue[T]{
+		compare:  compare,
+		arity:    o.arity,
+		capacity: capacity,
+	}
+	if capacity != collections.CapacityInfinite {
+		q.entries = make([]entry[T], 0, capacity)
+	}
+
+	return q
+}
+
+// PriorityQueue is a d-ary heap of items, which hands back the smallest first
+type PriorityQueue[T any] struct {
+	compare  func(a, b T) int
+	arity    int
+	capacity int
+	entries  []entry[T]
+}
+
+// entry is an item in the heap, along with its handle if it has one
+type entry[T any] struct {
+	item   T
+	handle *Handle[T]
+}
+
+// Capacity of the queue
+func (q *PriorityQueue[T]) Capacity() int {
+	return q.capacity
+}
+
+// Count the number of items in the queue
+func (q *PriorityQueue[T]) Count() int {
+	return len(q.entries)
+}
+
+// PushAll pushes many items at once. Returns an error, pushing none of the items, if
+// they would take the queue beyond its capacity. A batch at least half the size of the
+// queue is pushed by rebuilding the whole heap in linear time, rather than sifting in
+// each item.
+func (q *PriorityQueue[T]) PushAll(items ...T) error {
+	if q.full(len(items)) {
+		return collections.ErrBufferFull
+	}
+
+	held := len(q.entries)
+	for _, item := range items {
+		q.entries = append(q.entries, entry[T]{item: item})
+	}
+
+	if len(items)*2 < held {
+		for i := held; i < len(q.entries); i++ {
+			q.up(i)
+		}
+		return nil
+	}
+
+	// Sift down every node with children, from the last up, so each subtree is a heap
+	// by the time its root is sifted
+	for i := (len(q.entries) - 2) / q.arity; i >= 0; i-- {
+		q.down(i)
+	}
+
+	return nil
+}
+
+// full checks if the queue lacks room for more items
+func (q *PriorityQueue[T]) full(more int) bool {
+	return q.capacity != collections.CapacityInfinite && len(q.entries)+more > q.capacity
+}
+
+// set places an entry at an index, keeping its handle up to date
+func (q *PriorityQueue[T]) set(i int, e entry[T]) {
+	q.entries[i] = e
+	if e.handle != nil {
+		e.handle.index = i
+	}
+}
+
+// up sifts the entry at an index towards the root, until its parent is no larger
+func (q *PriorityQueue[T]) up(i int) {
+	e := q.entries[i]
+	for i > 0 {
+		parent := (i - 1) / q.arity
+		if q.compare(e.item, q.entries[parent].item) >= 0 {
+			break
+		}
+
+		q.set(i, q.entries[parent])
+		i = parent
+	}
+
+	q.set(i, e)
+}
+
+// down sifts the entry at an index towards the leaves, until none of its children are
+// smaller
+func (q *PriorityQueue[T]) down(i int) {
+	e := q.entries[i]
+	for {
+		first := q.arity*i + 1
+		if first >= len(q.entries) {
+			break
+		}
+
+		smallest := first
+		for child := first + 1; child < min(first+q.arity, len(q.entries)); child++ {
+			if q.compare(q.entries[child].item, q.entries[smallest].item) < 0 {
+				smallest = child
+			}
+		}
+		if q.compare(q.entries[smallest].item, e.item) >= 0 {
+			break
+		}
+
+		q.set(i, q.entries[smallest])
+		i = smallest
+	}
+
+	q.set(i, e)
+}
+
+// fix restores the order of the heap after the item at an index has changed
+func (q *PriorityQueue[T]) fix(i int) {
+	if i > 0 && q.compare(q.entries[i].item, q.entries[(i-1)/q.arity].item) < 0 {
+		q.up(i)
+		return
+	}
+
+	q.down(i)
+}
+
+// removeAt removes the entry at an index, filling the gap with the last entry
+func (q *PriorityQueue[T]) removeAt(i int) T {
+	removed := q.entries[i]
+	last := len(q.entries) - 1
+	if i != last {
+		q.set(i, q.entries[last])
+	}
+
+	q.entries[last] = entry[T]{}
+	q.entries = q.entries[:last]
+	if i != last {
+		q.fix(i)
+	}
+
+	if removed.handle != nil {
+		removed.handle.index = -1
+	}
+
+	return removed.item
+}
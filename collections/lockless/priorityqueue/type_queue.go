@@ -0,0 +1,44 @@
+package priorityqueue
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Ensure we meet the Queue[T] interface at compile time
+var _ collections.Queue[int] = (*PriorityQueue[int])(nil)
+
+// Peek the smallest item in the queue
+func (q *PriorityQueue[T]) Peek() (bool, T) {
+	if len(q.entries) == 0 {
+		var blank T
+		return false, blank
+	}
+
+	return true, q.entries[0].item
+}
+
+// Pop the smallest item from the queue
+func (q *PriorityQueue[T]) Pop() (bool, T) {
+	if len(q.entries) == 0 {
+		var blank T
+		return false, blank
+	}
+
+	return true, q.removeAt(0)
+}
+
+// Push an item into the queue. Returns an error if the queue is full.
+func (q *PriorityQueue[T]) Push(item T) error {
+	_, err := q.push(item, nil)
+	return err
+}
+
+// push adds an entry, with a handle if it has one, and sifts it into place
+func (q *PriorityQueue[T]) push(item T, handle *Handle[T]) (*Handle[T], error) {
+	if q.full(1) {
+		return nil, collections.ErrBufferFull
+	}
+
+	q.entries = append(q.entries, entry[T]{item: item, handle: handle})
+	q.up(len(q.entries) - 1)
+
+	return handle, nil
+}
@@ -0,0 +1,29 @@
+package ringbuffer
+
+// Option configures optional behaviour of a buffer of T when it is constructed.
+type Option[T any] func(o *options[T])
+
+// options holds the optional behaviours selected at construction
+type options[T any] struct {
+	overwrite bool
+	onEvict   func(T) // Called with each evicted item, if set
+}
+
+// WithOverwrite makes a full buffer evict its oldest item to make room for a push,
+// rather than refusing the push with collections.ErrBufferFull. The buffer then keeps
+// a rolling window of the most recent items pushed. It is an option in itself, so is
+// passed without calling it, and takes on the item type of the buffer:
+//
+//	buff := New[int](16, WithOverwrite)
+func WithOverwrite[T any](o *options[T]) {
+	o.overwrite = true
+}
+
+// WithEviction calls a function with each item evicted by WithOverwrite. It is called
+// once the push that evicted the item is complete, so it may call back into the
+// buffer.
+func WithEviction[T any](fn func(evicted T)) Option[T] {
+	return func(o *options[T]) {
+		o.onEvict = fn
+	}
+}
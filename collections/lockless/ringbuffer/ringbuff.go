@@ -7,10 +7,17 @@ import (
 )
 
 // New is a fixed-size ring/circle buffer of values.
-func New[T any](size int) *RingBuffer[T] {
+func New[T any](size int, opts ...Option[T]) *RingBuffer[T] {
+	o := options[T]{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return &RingBuffer[T]{
-		capacity: size,
-		data:     make([]T, size+1), // We actually keep+1
+		capacity:  size,
+		data:      make([]T, size+1), // We actually keep+1
+		overwrite: o.overwrite,
+		onEvict:   o.onEvict,
 	}
 }
 
@@ -19,6 +26,9 @@ type RingBuffer[T any] struct {
 	capacity int
 	head     int
 	data     []T
+
+	overwrite bool    // Evict the oldest item when full, rather than refusing a push
+	onEvict   func(T) // Called with each evicted item, if set
 }
 
 // Capacity of the buffer
@@ -69,7 +79,8 @@ func (b *RingBuffer[T]) Pop() (bool, T) {
 }
 
 // Push an item into the ring-buffer. Returns an error if we overflow
-// the buffer
+// the buffer. Buffers built WithOverwrite evict their oldest item instead
+// of overflowing.
 func (b *RingBuffer[T]) Push(item T) error {
 	newHead := b.head + 1
 	if newHead == b.capacity+1 {
@@ -77,7 +88,19 @@ func (b *RingBuffer[T]) Push(item T) error {
 	}
 
 	if newHead == b.cursor {
-		return fmt.Errorf("cursor wrapped at index %d: data may be lost: %w", newHead, collections.ErrBufferFull)
+		if !b.overwrite {
+			return fmt.Errorf("cursor wrapped at index %d: data may be lost: %w", newHead, collections.ErrBufferFull)
+		}
+
+		// A buffer with no capacity evicts the item straight away
+		if b.cursor == b.head {
+			b.evict(item)
+			return nil
+		}
+
+		// Move the cursor past the oldest item, making room
+		_, evicted := b.Pop()
+		defer b.evict(evicted)
 	}
 
 	b.data[b.head] = item
@@ -85,3 +108,10 @@ func (b *RingBuffer[T]) Push(item T) error {
 
 	return nil
 }
+
+// evict hands an evicted item to the eviction callback, if there is one
+func (b *RingBuffer[T]) evict(item T) {
+	if b.onEvict != nil {
+		b.onEvict(item)
+	}
+}
@@ -0,0 +1,43 @@
+package ringbuffer
+
+import "iter"
+
+// At gets the item at a position in the buffer, where 0 is the oldest. The boolean
+// indicates if the position was within the buffer.
+func (b *RingBuffer[T]) At(i int) (bool, T) {
+	if i < 0 || i >= b.Count() {
+		var blank T
+		return false, blank
+	}
+
+	return true, b.data[b.indexOf(i)]
+}
+
+// All iterates the items from oldest to newest, along with their positions. The loop
+// body must not change the buffer.
+func (b *RingBuffer[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := range b.Count() {
+			if !yield(i, b.data[b.indexOf(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Snapshot copies the items from oldest to newest
+func (b *RingBuffer[T]) Snapshot() []T {
+	// The items either run straight through the data, or wrap around its end
+	result := make([]T, 0, b.Count())
+	if b.cursor <= b.head {
+		return append(result, b.data[b.cursor:b.head]...)
+	}
+
+	result = append(result, b.data[b.cursor:]...)
+	return append(result, b.data[:b.head]...)
+}
+
+// indexOf gets the index in the data of a position
+func (b *RingBuffer[T]) indexOf(i int) int {
+	return (b.cursor + i) % (b.capacity + 1)
+}
@@ -0,0 +1,27 @@
+// Package spsc contains a bounded, wait-free ring buffer for handing items from one
+// goroutine to another. It allows for storage of N items of a type T with FIFO
+// semantics, and pushing more than the buffer can hold will generate an error.
+//
+// The producer owns the tail of the buffer and the consumer owns the head. Each only
+// ever writes its own end, and reads the other to see how far it may go, so every push
+// and pop finishes in a fixed number of steps without a lock or a compare-and-swap.
+// The two ends sit on separate cache lines, and each side keeps its own copy of where
+// it last saw the other end, only looking again when that copy says it has run out of
+// room or items. PushN and PopN move a whole batch for the cost of a single item.
+//
+// # Ownership
+//
+// The buffer is only safe when it is used as a one-way hand-off:
+//
+//   - Push and PushN belong to the producer. Only one goroutine may be the producer at
+//     a time.
+//   - Pop, PopN and Peek belong to the consumer. Only one goroutine may be the consumer
+//     at a time.
+//   - Count and Capacity may be called from any goroutine, though Count is a
+//     moment-in-time estimate while the producer and consumer are running.
+//
+// The producer and consumer may be the same goroutine. Either role can be handed to a
+// different goroutine, as long as the hand-over itself synchronises the two, such as
+// by passing the buffer over a channel, or through a mutex. For many producers or
+// consumers, use the mpmc package instead.
+package spsc
@@ -0,0 +1,74 @@
+package spsc
+
+import (
+	"sync/atomic"
+)
+
+// cacheLine is the size of the cache lines the two ends are kept apart by, so that the
+// producer and consumer don't slow each other down by writing to the same line.
+const cacheLine = 64
+
+// New creates a single-producer, single-consumer ring buffer holding up to size items.
+func New[T any](size int) *RingBuffer[T] {
+	return &RingBuffer[T]{
+		capacity: uint64(max(size, 0)),
+		data:     make([]T, max(size, 0)),
+	}
+}
+
+// RingBuffer is a bounded queue for handing items from one goroutine to another
+// without taking a lock. See the package documentation for who may call what.
+type RingBuffer[T any] struct {
+	_ [cacheLine]byte
+
+	// Owned by the producer
+	tail       atomic.Uint64 // Position of the next push
+	cachedHead uint64        // Where the producer last saw the head
+	_          [cacheLine - 16]byte
+
+	// Owned by the consumer
+	head       atomic.Uint64 // Position of the next pop
+	cachedTail uint64        // Where the consumer last saw the tail
+	_          [cacheLine - 16]byte
+
+	capacity uint64
+	data     []T
+}
+
+// Capacity of the buffer
+func (b *RingBuffer[T]) Capacity() int {
+	return int(b.capacity)
+}
+
+// Count the number of records in the buffer
+func (b *RingBuffer[T]) Count() int {
+	// The head is loaded first, so the tail is never behind it
+	head := b.head.Load()
+	tail := b.tail.Load()
+
+	return int(min(tail-head, b.capacity))
+}
+
+// room gets the number of items the producer can push, looking at the head again if
+// it has run out of room, or has less than it needs
+func (b *RingBuffer[T]) room(tail uint64, needed uint64) uint64 {
+	free := b.capacity - (tail - b.cachedHead)
+	if free < needed {
+		b.cachedHead = b.head.Load()
+		free = b.capacity - (tail - b.cachedHead)
+	}
+
+	return free
+}
+
+// available gets the number of items the consumer can pop, looking at the tail again
+// if it has run out of items, or has fewer than it needs
+func (b *RingBuffer[T]) available(head uint64, needed uint64) uint64 {
+	ready := b.cachedTail - head
+	if ready < needed {
+		b.cachedTail = b.tail.Load()
+		ready = b.cachedTail - head
+	}
+
+	return ready
+}
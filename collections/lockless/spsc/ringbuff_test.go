@@ -0,0 +1,249 @@
+package spsc
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	collections "github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/lockless/mpmc"
+	lockless "github.com/zeroflucs-given/generics/collections/lockless/ringbuffer"
+	"github.com/zeroflucs-given/generics/collections/ringbuffer"
+)
+
+// TestRingBufferZeroCapacity checks a buffer with no room refuses every push
+func TestRingBufferZeroCapacity(t *testing.T) {
+	buff := New[int](0)
+
+	require.Equal(t, 0, buff.Capacity(), "Should have no capacity")
+	require.ErrorIs(t, buff.Push(1), collections.ErrBufferFull, "Should refuse a push")
+	require.Zero(t, buff.PushN([]int{1, 2}), "Should refuse a batch")
+	hasValue, _ := buff.Pop()
+	require.False(t, hasValue, "Should have nothing to pop")
+	require.Zero(t, buff.PopN(make([]int, 2)), "Should have nothing to pop")
+}
+
+// TestRingBufferBatches pushes and pops batches of random sizes, mixed with single
+// items, checking they come out in order as the batches wrap around the buffer.
+func TestRingBufferBatches(t *testing.T) {
+	rng := rand.New(rand.NewSource(133713371337))
+	testSize := 13
+
+	buff := New[int](testSize)
+	pushed, popped := 0, 0
+
+	for i := 0; i < 10000; i++ {
+		held := pushed - popped
+		switch rng.Intn(4) {
+		case 0:
+			batch := make([]int, rng.Intn(testSize+3))
+			for j := range batch {
+				batch[j] = pushed + j
+			}
+			count := buff.PushN(batch)
+			require.Equal(t, min(len(batch), testSize-held), count, "Should push as much of the batch as fits")
+			pushed += count
+		case 1:
+			if held == testSize {
+				require.ErrorIs(t, buff.Push(pushed), collections.ErrBufferFull, "Should refuse a push when full")
+				continue
+			}
+			require.NoError(t, buff.Push(pushed), "Should push when there is room")
+			pushed++
+		case 2:
+			into := make([]int, rng.Intn(testSize+3))
+			count := buff.PopN(into)
+			require.Equal(t, min(len(into), held), count, "Should pop as many as are ready")
+			for j := 0; j < count; j++ {
+				require.Equal(t, popped+j, into[j], "Should pop in order")
+			}
+			popped += count
+		case 3:
+			hasValue, v := buff.Pop()
+			require.Equal(t, held > 0, hasValue, "Should pop if anything is held")
+			if hasValue {
+				require.Equal(t, popped, v, "Should pop in order")
+				popped++
+			}
+		}
+
+		require.Equal(t, pushed-popped, buff.Count(), "Should count the items held")
+	}
+}
+
+// TestRingBufferHandOff passes items from a producer goroutine to a consumer goroutine
+// in batches and singly, checking they arrive in order. Run with -race to check the
+// hand-over of slots.
+func TestRingBufferHandOff(t *testing.T) {
+	const total = 200000
+
+	buff := New[int](64)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		rng := rand.New(rand.NewSource(1))
+		batch := make([]int, 16)
+		for next := 0; next < total; {
+			if rng.Intn(2) == 0 {
+				if buff.Push(next) == nil {
+					next++
+				} else {
+					runtime.Gosched()
+				}
+				continue
+			}
+
+			size := min(1+rng.Intn(len(batch)), total-next)
+			for i := 0; i < size; i++ {
+				batch[i] = next + i
+			}
+			pushed := buff.PushN(batch[:size])
+			if pushed == 0 {
+				runtime.Gosched()
+			}
+			next += pushed
+		}
+	}()
+
+	rng := rand.New(rand.NewSource(2))
+	into := make([]int, 16)
+	for next := 0; next < total; {
+		if rng.Intn(2) == 0 {
+			peeked, expected := buff.Peek()
+			hasValue, v := buff.Pop()
+			require.Equal(t, peeked, hasValue, "Should pop if an item was peeked")
+			if !hasValue {
+				runtime.Gosched()
+				continue
+			}
+			require.Equal(t, expected, v, "Should pop what was peeked")
+			require.Equal(t, next, v, "Should pop in order")
+			next++
+			continue
+		}
+
+		count := buff.PopN(into[:1+rng.Intn(len(into))])
+		if count == 0 {
+			runtime.Gosched()
+		}
+		for i := 0; i < count; i++ {
+			require.Equal(t, next+i, into[i], "Should pop in order")
+		}
+		next += count
+	}
+
+	<-done
+	require.Zero(t, buff.Count(), "Should be empty")
+}
+
+// BenchmarkHandOff moves items from a producer goroutine to a consumer goroutine,
+// comparing this buffer, singly and in batches, with the other thread-safe buffers.
+func BenchmarkHandOff(b *testing.B) {
+	targets := []struct {
+		name  string
+		build func() collections.Queue[int]
+	}{
+		{name: "SPSC", build: func() collections.Queue[int] { return New[int](1024) }},
+		{name: "MPMC", build: func() collections.Queue[int] { return mpmc.New[int](1024) }},
+		{name: "Mutex", build: func() collections.Queue[int] { return ringbuffer.New[int](1024) }},
+	}
+
+	for _, target := range targets {
+		b.Run(target.name, func(b *testing.B) {
+			buff := target.build()
+			done := make(chan struct{})
+			b.ResetTimer()
+
+			go func() {
+				defer close(done)
+				for i := 0; i < b.N; i++ {
+					for buff.Push(i) != nil {
+						runtime.Gosched()
+					}
+				}
+			}()
+
+			for i := 0; i < b.N; {
+				if hasValue, _ := buff.Pop(); hasValue {
+					i++
+					continue
+				}
+				runtime.Gosched()
+			}
+			<-done
+		})
+	}
+
+	for _, size := range []int{16, 256} {
+		b.Run(fmt.Sprintf("SPSC_Batch=%d", size), func(b *testing.B) {
+			buff := New[int](1024)
+			done := make(chan struct{})
+			b.ResetTimer()
+
+			go func() {
+				defer close(done)
+				batch := make([]int, size)
+				for i := 0; i < b.N; {
+					pushed := buff.PushN(batch[:min(size, b.N-i)])
+					if pushed == 0 {
+						runtime.Gosched()
+					}
+					i += pushed
+				}
+			}()
+
+			into := make([]int, size)
+			for i := 0; i < b.N; {
+				popped := buff.PopN(into)
+				if popped == 0 {
+					runtime.Gosched()
+				}
+				i += popped
+			}
+			<-done
+		})
+	}
+}
+
+// BenchmarkRingBuffer cycles items through each buffer from a single goroutine, which is
+// the only way the unsynchronised buffer may be used.
+func BenchmarkRingBuffer(b *testing.B) {
+	targets := []struct {
+		name  string
+		build func() collections.Queue[int]
+	}{
+		{name: "SPSC", build: func() collections.Queue[int] { return New[int](32) }},
+		{name: "Unsynchronised", build: func() collections.Queue[int] { return lockless.New[int](32) }},
+		{name: "MPMC", build: func() collections.Queue[int] { return mpmc.New[int](32) }},
+		{name: "Mutex", build: func() collections.Queue[int] { return ringbuffer.New[int](32) }},
+	}
+
+	for _, target := range targets {
+		b.Run(target.name, func(b *testing.B) {
+			buff := target.build()
+			for i := 0; i < buff.Capacity()/2; i++ {
+				if err := buff.Push(i); err != nil {
+					b.Log(err)
+					b.FailNow()
+				}
+			}
+
+			var i int
+			for b.Loop() {
+				if err := buff.Push(i); err != nil {
+					b.Log(err)
+					b.FailNow()
+				}
+				if hasItem, _ := buff.Pop(); !hasItem {
+					b.Log("Should have had item")
+					b.FailNow()
+				}
+				i++
+			}
+		})
+	}
+}
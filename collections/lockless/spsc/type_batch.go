@@ -0,0 +1,41 @@
+package spsc
+
+// PushN pushes as many of the items as there is room for, in order, returning how many
+// were pushed. The consumer sees the whole batch at once. Only the producer may push.
+func (b *RingBuffer[T]) PushN(items []T) int {
+	tail := b.tail.Load()
+	count := min(b.room(tail, uint64(len(items))), uint64(len(items)))
+	if count == 0 {
+		return 0
+	}
+
+	// The batch may wrap around the end of the buffer
+	at := tail % b.capacity
+	copied := copy(b.data[at:], items[:count])
+	copy(b.data, items[copied:count])
+	b.tail.Store(tail + count)
+
+	return int(count)
+}
+
+// PopN pops as many items as are ready, up to the length of the slice, into the slice,
+// returning how many were popped. Only the consumer may pop.
+func (b *RingBuffer[T]) PopN(into []T) int {
+	head := b.head.Load()
+	count := min(b.available(head, uint64(len(into))), uint64(len(into)))
+	if count == 0 {
+		return 0
+	}
+
+	// The batch may wrap around the end of the buffer. The slots are cleared as they
+	// are taken, so the buffer doesn't keep the items alive.
+	at := head % b.capacity
+	first := min(count, b.capacity-at)
+	copy(into, b.data[at:at+first])
+	copy(into[first:], b.data[:count-first])
+	clear(b.data[at : at+first])
+	clear(b.data[:count-first])
+	b.head.Store(head + count)
+
+	return int(count)
+}
@@ -0,0 +1,50 @@
+package spsc
+
+import (
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// Ensure we meet the Queue[T] interface at compile time
+var _ collections.Queue[int] = (*RingBuffer[int])(nil)
+
+// Peek an item from the ring buffer. Only the consumer may peek.
+func (b *RingBuffer[T]) Peek() (bool, T) {
+	head := b.head.Load()
+	if b.available(head, 1) == 0 {
+		var blank T
+		return false, blank
+	}
+
+	return true, b.data[head%b.capacity]
+}
+
+// Pop an item from the ring buffer. Only the consumer may pop.
+func (b *RingBuffer[T]) Pop() (bool, T) {
+	var blank T
+
+	head := b.head.Load()
+	if b.available(head, 1) == 0 {
+		return false, blank
+	}
+
+	at := head % b.capacity
+	result := b.data[at]
+	b.data[at] = blank
+	b.head.Store(head + 1)
+
+	return true, result
+}
+
+// Push an item into the ring buffer. Returns an error if the buffer is full. Only the
+// producer may push.
+func (b *RingBuffer[T]) Push(item T) error {
+	tail := b.tail.Load()
+	if b.room(tail, 1) == 0 {
+		return collections.ErrBufferFull
+	}
+
+	b.data[tail%b.capacity] = item
+	b.tail.Store(tail + 1)
+
+	return nil
+}
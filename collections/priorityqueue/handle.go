@@ -0,0 +1,55 @@
+package priorityqueue
+
+// Handle refers to an item pushed with PushHandle, wherever it has moved to in the
+// heap. A handle stops being valid once its item is popped or removed. Handles are
+// only read and changed with the lock of their queue held.
+type Handle[T any] struct {
+	index int // Where the item is in the heap, or -1 once it has left
+}
+
+// PushHandle pushes an item into the queue, returning a handle that can later be used
+// to Fix or Remove it. Returns an error if the queue is full.
+func (q *PriorityQueue[T]) PushHandle(item T) (*Handle[T], error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.pushInternal(item, &Handle[T]{})
+}
+
+// Valid checks the item of a handle is still in this queue
+func (q *PriorityQueue[T]) Valid(h *Handle[T]) bool {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	return q.heap.owns(h)
+}
+
+// Fix replaces the item of a handle, moving it to its new place in the heap. Returns
+// false if the handle's item is no longer in this queue.
+func (q *PriorityQueue[T]) Fix(h *Handle[T], item T) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if !q.heap.owns(h) {
+		return false
+	}
+
+	q.heap.entries[h.index].item = item
+	q.heap.fix(h.index)
+
+	return true
+}
+
+// Remove takes the item of a handle out of the queue, wherever it is in the heap.
+// Returns false if the handle's item is no longer in this queue.
+func (q *PriorityQueue[T]) Remove(h *Handle[T]) (bool, T) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if !q.heap.owns(h) {
+		var blank T
+		return false, blank
+	}
+
+	return true, q.heap.removeAt(h.index)
+}
@@ -0,0 +1,130 @@
+package priorityqueue
+
+// heap is a d-ary heap of entries ordered by a comparator, smallest first. It does no
+// locking of its own; the queues built on it only call it with their lock held.
+type heap[T any] struct {
+	compare func(a, b T) int
+	arity   int
+	entries []entry[T]
+}
+
+// entry is an item in the heap, along with its handle if it has one
+type entry[T any] struct {
+	item   T
+	handle *Handle[T]
+}
+
+// push adds an entry, with a handle if it has one, and sifts it into place
+func (h *heap[T]) push(item T, handle *Handle[T]) {
+	h.entries = append(h.entries, entry[T]{item: item, handle: handle})
+	h.up(len(h.entries) - 1)
+}
+
+// pushAll adds many entries. A batch at least half the size of the heap is added by
+// rebuilding the whole heap in linear time, rather than sifting in each entry.
+func (h *heap[T]) pushAll(items []T) {
+	held := len(h.entries)
+	for _, item := range items {
+		h.entries = append(h.entries, entry[T]{item: item})
+	}
+
+	if len(items)*2 < held {
+		for i := held; i < len(h.entries); i++ {
+			h.up(i)
+		}
+		return
+	}
+
+	// Sift down every node with children, from the last up, so each subtree is a heap
+	// by the time its root is sifted
+	for i := (len(h.entries) - 2) / h.arity; i >= 0; i-- {
+		h.down(i)
+	}
+}
+
+// set places an entry at an index, keeping its handle up to date
+func (h *heap[T]) set(i int, e entry[T]) {
+	h.entries[i] = e
+	if e.handle != nil {
+		e.handle.index = i
+	}
+}
+
+// up sifts the entry at an index towards the root, until its parent is no larger
+func (h *heap[T]) up(i int) {
+	e := h.entries[i]
+	for i > 0 {
+		parent := (i - 1) / h.arity
+		if h.compare(e.item, h.entries[parent].item) >= 0 {
+			break
+		}
+
+		h.set(i, h.entries[parent])
+		i = parent
+	}
+
+	h.set(i, e)
+}
+
+// down sifts the entry at an index towards the leaves, until none of its children are
+// smaller
+func (h *heap[T]) down(i int) {
+	e := h.entries[i]
+	for {
+		first := h.arity*i + 1
+		if first >= len(h.entries) {
+			break
+		}
+
+		smallest := first
+		for child := first + 1; child < min(first+h.arity, len(h.entries)); child++ {
+			if h.compare(h.entries[child].item, h.entries[smallest].item) < 0 {
+				smallest = child
+			}
+		}
+		if h.compare(h.entries[smallest].item, e.item) >= 0 {
+			break
+		}
+
+		h.set(i, h.entries[smallest])
+		i = smallest
+	}
+
+	h.set(i, e)
+}
+
+// fix restores the order of the heap after the item at an index has changed
+func (h *heap[T]) fix(i int) {
+	if i > 0 && h.compare(h.entries[i].item, h.entries[(i-1)/h.arity].item) < 0 {
+		h.up(i)
+		return
+	}
+
+	h.down(i)
+}
+
+// removeAt removes the entry at an index, filling the gap with the last entry
+func (h *heap[T]) removeAt(i int) T {
+	removed := h.entries[i]
+	last := len(h.entries) - 1
+	if i != last {
+		h.set(i, h.entries[last])
+	}
+
+	h.entries[last] = entry[T]{}
+	h.entries = h.entries[:last]
+	if i != last {
+		h.fix(i)
+	}
+
+	if removed.handle != nil {
+		removed.handle.index = -1
+	}
+
+	return removed.item
+}
+
+// owns checks a handle refers to an entry in this heap
+func (h *heap[T]) owns(handle *Handle[T]) bool {
+	return handle != nil && handle.index >= 0 && handle.index < len(h.entries) && h.entries[handle.index].handle == handle
+}
@@ -0,0 +1,168 @@
+package priorityqueue
+
+import (
+	"cmp"
+	"errors"
+	"sync"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// ErrNoKeyFunctions indicates a value was pushed to an Indexed queue built without the
+// functions to key and prioritise it.
+var ErrNoKeyFunctions = errors.New("the queue has no functions to key and prioritise the value")
+
+// NewIndexed creates a priority queue of values keyed by an ID, which hands back the
+// value with the smallest priority first. It holds up to capacity values, or
+// collections.CapacityInfinite for no limit. Values pushed through the Queue[V]
+// interface are keyed and prioritised by keyOf and priorityOf. Either may be nil for a
+// queue only filled by Upsert, in which case Push returns ErrNoKeyFunctions.
+func NewIndexed[K comparable, P generics.Comparable, V any](capacity int, keyOf func(V) K, priorityOf func(V) P, opts ...Option) *Indexed[K, P, V] {
+	return NewIndexedFunc(capacity, cmp.Compare[P], keyOf, priorityOf, opts...)
+}
+
+// NewIndexedFunc creates a priority queue of values keyed by an ID, which hands back the
+// value with the smallest priority according to the comparator first. Reverse the
+// comparator to hand back the largest priority first instead.
+func NewIndexedFunc[K comparable, P any, V any](capacity int, compare func(a, b P) int, keyOf func(V) K, priorityOf func(V) P, opts ...Option) *Indexed[K, P, V] {
+	return &Indexed[K, P, V]{
+		heap: newHeap(capacity, func(a, b IndexedItem[K, P, V]) int {
+			return compare(a.Priority, b.Priority)
+		}, opts),
+		handles:    make(map[K]*Handle[IndexedItem[K, P, V]]),
+		keyOf:      keyOf,
+		priorityOf: priorityOf,
+		capacity:   capacity,
+	}
+}
+
+// Indexed is a priority queue of values keyed by an ID. A value can be reprioritised,
+// replaced or removed by its ID without searching the queue for it.
+type Indexed[K comparable, P any, V any] struct {
+	heap       heap[IndexedItem[K, P, V]]
+	handles    map[K]*Handle[IndexedItem[K, P, V]] // Where each ID is in the heap
+	keyOf      func(V) K
+	priorityOf func(V) P
+	capacity   int
+	lock       sync.RWMutex
+}
+
+// IndexedItem is a value held by an Indexed queue, along with its ID and priority
+type IndexedItem[K comparable, P any, V any] struct {
+	ID       K
+	Priority P
+	Value    V
+}
+
+// Capacity of the queue
+func (q *Indexed[K, P, V]) Capacity() int {
+	return q.capacity
+}
+
+// Count the number of values in the queue
+func (q *Indexed[K, P, V]) Count() int {
+	q.lock.RLock()
+	count := len(q.heap.entries)
+	q.lock.RUnlock()
+
+	return count
+}
+
+// Contains checks if the queue holds a value for an ID
+func (q *Indexed[K, P, V]) Contains(id K) bool {
+	q.lock.RLock()
+	_, found := q.handles[id]
+	q.lock.RUnlock()
+
+	return found
+}
+
+// Get the priority and value held for an ID
+func (q *Indexed[K, P, V]) Get(id K) (bool, IndexedItem[K, P, V]) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	h, found := q.handles[id]
+	if !found {
+		return false, IndexedItem[K, P, V]{}
+	}
+
+	return true, q.heap.entries[h.index].item
+}
+
+// Upsert sets the priority and value held for an ID, moving it to its new place in the
+// queue if it is already held. Returns an error if the ID is not held and the queue is
+// full.
+func (q *Indexed[K, P, V]) Upsert(id K, priority P, value V) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.upsertInternal(IndexedItem[K, P, V]{ID: id, Priority: priority, Value: value})
+}
+
+// upsertInternal sets the item held for its ID with the lock held
+func (q *Indexed[K, P, V]) upsertInternal(item IndexedItem[K, P, V]) error {
+	if h, found := q.handles[item.ID]; found {
+		q.heap.entries[h.index].item = item
+		q.heap.fix(h.index)
+		return nil
+	}
+
+	if q.capacity != collections.CapacityInfinite && len(q.heap.entries) >= q.capacity {
+		return collections.ErrBufferFull
+	}
+
+	h := &Handle[IndexedItem[K, P, V]]{}
+	q.heap.push(item, h)
+	q.handles[item.ID] = h
+
+	return nil
+}
+
+// Remove the value held for an ID, wherever it is in the queue
+func (q *Indexed[K, P, V]) Remove(id K) (bool, V) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	h, found := q.handles[id]
+	if !found {
+		var blank V
+		return false, blank
+	}
+
+	return true, q.removeInternal(h.index).Value
+}
+
+// removeInternal removes the item at an index of the heap, and its ID, with the lock
+// held
+func (q *Indexed[K, P, V]) removeInternal(i int) IndexedItem[K, P, V] {
+	item := q.heap.removeAt(i)
+	delete(q.handles, item.ID)
+
+	return item
+}
+
+// PeekMin gets the item with the smallest priority, without removing it
+func (q *Indexed[K, P, V]) PeekMin() (bool, IndexedItem[K, P, V]) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	if len(q.heap.entries) == 0 {
+		return false, IndexedItem[K, P, V]{}
+	}
+
+	return true, q.heap.entries[0].item
+}
+
+// PopMin removes the item with the smallest priority
+func (q *Indexed[K, P, V]) PopMin() (bool, IndexedItem[K, P, V]) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.heap.entries) == 0 {
+		return false, IndexedItem[K, P, V]{}
+	}
+
+	return true, q.removeInternal(0)
+}
@@ -0,0 +1,207 @@
+package priorityqueue
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// job is a value of the indexed queues under test
+type job struct {
+	id  int
+	due int
+}
+
+// newJobs creates an indexed queue of jobs, due soonest first
+func newJobs(capacity int) *Indexed[int, int, job] {
+	return NewIndexed(capacity, func(j job) int { return j.id }, func(j job) int { return j.due })
+}
+
+// TestIndexedModel performs a random sequence of upserts, removes and pops, checking
+// the queue against a map of the jobs it should hold
+func TestIndexedModel(t *testing.T) {
+	rng := rand.New(rand.NewSource(133713371337))
+	q := newJobs(collections.CapacityInfinite)
+	model := map[int]job{}
+
+	for i := 0; i < 5000; i++ {
+		id := rng.Intn(50)
+		switch op := rng.Intn(10); {
+		case op < 5:
+			j := job{id: id, due: rng.Intn(1000)}
+			require.NoError(t, q.Upsert(id, j.due, j), "Should upsert %d", id)
+			model[id] = j
+		case op < 7:
+			found, j := q.Remove(id)
+			expected, held := model[id]
+			require.Equal(t, held, found, "Should remove %d only if held", id)
+			require.Equal(t, expected, j, "Should remove the job of %d", id)
+			delete(model, id)
+		default:
+			found, item := q.PopMin()
+			require.Equal(t, len(model) > 0, found, "Should pop if anything is held")
+			if found {
+				require.Equal(t, model[item.ID], item.Value, "Should pop the job held for %d", item.ID)
+				require.Equal(t, soonest(model), item.Priority, "Should pop the soonest job")
+				delete(model, item.ID)
+			}
+		}
+
+		requireIndexed(t, q, model)
+	}
+}
+
+// TestIndexedCapacity checks a full queue refuses new IDs, but still updates those it
+// holds
+func TestIndexedCapacity(t *testing.T) {
+	q := newJobs(2)
+	require.NoError(t, q.Push(job{id: 1, due: 10}))
+	require.NoError(t, q.Push(job{id: 2, due: 20}))
+
+	require.ErrorIs(t, q.Push(job{id: 3, due: 5}), collections.ErrBufferFull, "Should refuse a new ID when full")
+	require.False(t, q.Contains(3), "Should not hold a refused ID")
+
+	require.NoError(t, q.Upsert(2, 1, job{id: 2, due: 1}), "Should update a held ID when full")
+	found, j := q.Peek()
+	require.True(t, found)
+	require.Equal(t, job{id: 2, due: 1}, j, "Should move an updated job to the front")
+}
+
+// TestIndexedMaxFirst checks a reversed comparator hands back the largest priority
+// first
+func TestIndexedMaxFirst(t *testing.T) {
+	q := NewIndexedFunc(collections.CapacityInfinite, func(a, b int) int { return cmp.Compare(b, a) },
+		func(s string) string { return s }, func(s string) int { return len(s) })
+	for _, s := range []string{"ccc", "a", "dddd", "bb"} {
+		require.NoError(t, q.Push(s))
+	}
+	require.NoError(t, q.Upsert("a", 10, "a"), "Should reprioritise a held ID")
+
+	var order []string
+	for q.Count() > 0 {
+		_, s := q.Pop()
+		order = append(order, s)
+	}
+	require.Equal(t, []string{"a", "dddd", "ccc", "bb"}, order, "Should pop the largest priority first")
+}
+
+// TestIndexedNoKeyFunctions checks a queue built without key functions refuses pushes,
+// but can still be filled by ID
+func TestIndexedNoKeyFunctions(t *testing.T) {
+	q := NewIndexed[int, int, job](collections.CapacityInfinite, nil, nil)
+	require.ErrorIs(t, q.Push(job{id: 1, due: 5}), ErrNoKeyFunctions, "Should refuse a push")
+	require.Equal(t, 0, q.Count(), "Should hold nothing")
+
+	require.NoError(t, q.Upsert(1, 5, job{id: 1, due: 5}), "Should upsert by ID")
+	found, j := q.Pop()
+	require.True(t, found, "Should pop the upserted job")
+	require.Equal(t, job{id: 1, due: 5}, j, "Should pop the upserted job")
+}
+
+// TestIndexedConcurrent checks concurrent upserts, removes and pops leave the queue
+// and its index consistent
+func TestIndexedConcurrent(t *testing.T) {
+	q := newJobs(collections.CapacityInfinite)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 1000; i++ {
+				id := rng.Intn(100)
+				switch rng.Intn(4) {
+				case 0, 1:
+					j := job{id: id, due: rng.Intn(1000)}
+					if err := q.Upsert(id, j.due, j); err != nil {
+						t.Errorf("Should upsert: %v", err)
+						return
+					}
+				case 2:
+					q.Remove(id)
+				default:
+					if found, item := q.PopMin(); found && item.ID != item.Value.id {
+						t.Errorf("Should pop the job held for %d", item.ID)
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	model := map[int]job{}
+	q.lock.RLock()
+	for _, e := range q.heap.entries {
+		model[e.item.ID] = e.item.Value
+	}
+	q.lock.RUnlock()
+	requireIndexed(t, q, model)
+}
+
+// BenchmarkIndexedUpsert reprioritises jobs held in a queue of a steady size
+func BenchmarkIndexedUpsert(b *testing.B) {
+	for _, size := range []int{100, 10000} {
+		b.Run(fmt.Sprintf("Size=%d", size), func(b *testing.B) {
+			rng := rand.New(rand.NewSource(133713371337))
+			q := newJobs(collections.CapacityInfinite)
+			for i := 0; i < size; i++ {
+				_ = q.Push(job{id: i, due: rng.Int()})
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				id := rng.Intn(size)
+				due := rng.Int()
+				_ = q.Upsert(id, due, job{id: id, due: due})
+			}
+		})
+	}
+}
+
+// soonest gets the earliest due time of the model
+func soonest(model map[int]job) int {
+	due := -1
+	for _, j := range model {
+		if due < 0 || j.due < due {
+			due = j.due
+		}
+	}
+
+	return due
+}
+
+// requireIndexed checks the queue holds the jobs of the model, and its index and heap
+// agree
+func requireIndexed(t *testing.T, q *Indexed[int, int, job], model map[int]job) {
+	t.Helper()
+
+	require.Equal(t, len(model), q.Count(), "Should count the jobs held")
+	for id := 0; id < 100; id++ {
+		expected, held := model[id]
+		require.Equal(t, held, q.Contains(id), "Should know if it holds %d", id)
+
+		found, item := q.Get(id)
+		require.Equal(t, held, found, "Should get %d only if held", id)
+		if held {
+			require.Equal(t, IndexedItem[int, int, job]{ID: id, Priority: expected.due, Value: expected}, item, "Should get the job of %d", id)
+		}
+	}
+
+	found, item := q.PeekMin()
+	require.Equal(t, len(model) > 0, found, "Should peek if anything is held")
+	if found {
+		require.Equal(t, soonest(model), item.Priority, "Should peek the soonest job")
+	}
+
+	for i := 1; i < len(q.heap.entries); i++ {
+		parent := (i - 1) / q.heap.arity
+		require.GreaterOrEqual(t, q.heap.entries[i].item.Priority, q.heap.entries[parent].item.Priority, "Should hold job %d no sooner than its parent", i)
+	}
+}
@@ -0,0 +1,21 @@
+package priorityqueue
+
+// DefaultArity is the number of children each node of the heap has, unless set with
+// WithArity
+const DefaultArity = 4
+
+// Option configures optional behaviour of a queue when it is constructed.
+type Option func(o *options)
+
+// options holds the optional behaviours selected at construction
+type options struct {
+	arity int
+}
+
+// WithArity sets the number of children each node of the heap has. Values below two
+// are taken as two, which makes a binary heap.
+func WithArity(d int) Option {
+	return func(o *options) {
+		o.arity = max(d, 2)
+	}
+}
@@ -0,0 +1,88 @@
+package priorityqueue
+
+import (
+	"cmp"
+	"sync"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// New creates a priority queue that hands back the smallest item first, holding up to
+// capacity items, or collections.CapacityInfinite for no limit.
+func New[T generics.Comparable](capacity int, opts ...Option) *PriorityQueue[T] {
+	return NewFunc(capacity, cmp.Compare[T], opts...)
+}
+
+// NewFunc creates a priority queue that hands back the smallest item first according
+// to the comparator, holding up to capacity items, or collections.CapacityInfinite for
+// no limit.
+func NewFunc[T any](capacity int, compare func(a, b T) int, opts ...Option) *PriorityQueue[T] {
+	return &PriorityQueue[T]{
+		heap:     newHeap(capacity, compare, opts),
+		capacity: capacity,
+	}
+}
+
+// newHeap creates the heap for a queue of the given capacity
+func newHeap[T any](capacity int, compare func(a, b T) int, opts []Option) heap[T] {
+	o := options{
+		arity: DefaultArity,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	h := heap[T]{
+		compare: compare,
+		arity:   o.arity,
+	}
+	if capacity != collections.CapacityInfinite {
+		h.entries = make([]entry[T], 0, capacity)
+	}
+
+	return h
+}
+
+// PriorityQueue is a d-ary heap of items, which hands back the smallest first
+type PriorityQueue[T any] struct {
+	heap     heap[T]
+	capacity int
+	lock     sync.RWMutex
+}
+
+// Capacity of the queue
+func (q *PriorityQueue[T]) Capacity() int {
+	return q.capacity
+}
+
+// Count the number of items in the queue
+func (q *PriorityQueue[T]) Count() int {
+	q.lock.RLock()
+	count := len(q.heap.entries)
+	q.lock.RUnlock()
+
+	return count
+}
+
+// PushAll pushes many items at once. Returns an error, pushing none of the items, if
+// they would take the queue beyond its capacity. A batch at least half the size of the
+// queue is pushed by rebuilding the whole heap in linear time, rather than sifting in
+// each item.
+func (q *PriorityQueue[T]) PushAll(items ...T) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.full(len(items)) {
+		return collections.ErrBufferFull
+	}
+
+	q.heap.pushAll(items)
+
+	return nil
+}
+
+// full checks if the queue lacks room for more items, with the lock held
+func (q *PriorityQueue[T]) full(more int) bool {
+	return q.capacity != collections.CapacityInfinite && len(q.heap.entries)+more > q.capacity
+}
@@ -0,0 +1,240 @@
+package priorityqueue
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestPriorityQueuePushAll checks batches are heapified both into an empty queue and
+// sifted into a larger one, across arities
+func TestPriorityQueuePushAll(t *testing.T) {
+	rng := rand.New(rand.NewSource(133713371337))
+
+	for _, arity := range []int{0, 2, 3, 4, 8} {
+		q := New[int](collections.CapacityInfinite, WithArity(arity))
+		var expected []int
+		for _, size := range []int{100, 1, 10, 500, 0, 3} {
+			batch := make([]int, size)
+			for i := range batch {
+				batch[i] = rng.Intn(1000)
+			}
+
+			require.NoError(t, q.PushAll(batch...), "Should push the batch of %d", size)
+			expected = append(expected, batch...)
+			requireHeap(t, q)
+		}
+
+		slices.Sort(expected)
+		requireDrains(t, q, expected)
+	}
+}
+
+// TestPriorityQueuePushAllCapacity checks a batch too large for the queue is refused
+// whole
+func TestPriorityQueuePushAllCapacity(t *testing.T) {
+	q := New[int](5)
+	require.NoError(t, q.PushAll(3, 1, 2), "Should push within capacity")
+	require.ErrorIs(t, q.PushAll(4, 5, 6), collections.ErrBufferFull, "Should refuse a batch beyond capacity")
+	require.Equal(t, 3, q.Count(), "Should push none of a refused batch")
+
+	require.NoError(t, q.PushAll(5, 4), "Should push up to capacity")
+	requireDrains(t, q, []int{1, 2, 3, 4, 5})
+}
+
+// TestPriorityQueueHandles checks items can be fixed and removed through their handles
+// wherever they are in the heap, and handles are refused once their item has left
+func TestPriorityQueueHandles(t *testing.T) {
+	rng := rand.New(rand.NewSource(133713371337))
+	q := New[int](collections.CapacityInfinite, WithArity(3))
+
+	model := map[*Handle[int]]int{}
+	for i := 0; i < 200; i++ {
+		v := rng.Intn(1000)
+		h, err := q.PushHandle(v)
+		require.NoError(t, err, "Should push with a handle")
+		require.True(t, q.Valid(h), "Should hold the item of a new handle")
+		model[h] = v
+	}
+	require.NoError(t, q.PushAll(1, 999, 500), "Should mix in items without handles")
+
+	var removed []*Handle[int]
+	for h, v := range model {
+		switch rng.Intn(3) {
+		case 0:
+			moved := rng.Intn(1000)
+			require.True(t, q.Fix(h, moved), "Should fix an item held")
+			model[h] = moved
+		case 1:
+			found, item := q.Remove(h)
+			require.True(t, found, "Should remove an item held")
+			require.Equal(t, v, item, "Should remove the item of the handle")
+			require.False(t, q.Valid(h), "Should no longer hold a removed item")
+			removed = append(removed, h)
+			delete(model, h)
+		}
+		requireHeap(t, q)
+	}
+
+	for _, h := range removed {
+		require.False(t, q.Fix(h, 0), "Should refuse to fix a removed item")
+		found, _ := q.Remove(h)
+		require.False(t, found, "Should refuse to remove an item twice")
+	}
+
+	other := New[int](collections.CapacityInfinite)
+	for h := range model {
+		require.False(t, other.Valid(h), "Should not hold the items of another queue")
+		require.False(t, other.Fix(h, 0), "Should refuse handles of another queue")
+	}
+	require.False(t, q.Valid(nil), "Should refuse a nil handle")
+
+	expected := []int{1, 500, 999}
+	for _, v := range model {
+		expected = append(expected, v)
+	}
+	slices.Sort(expected)
+
+	handles := make([]*Handle[int], 0, len(model))
+	for h := range model {
+		handles = append(handles, h)
+	}
+	requireDrains(t, q, expected)
+	for _, h := range handles {
+		require.False(t, q.Valid(h), "Should no longer hold a popped item")
+	}
+}
+
+// TestPriorityQueueHandleCapacity checks a full queue refuses a push with a handle
+func TestPriorityQueueHandleCapacity(t *testing.T) {
+	q := New[int](1)
+	_, err := q.PushHandle(1)
+	require.NoError(t, err, "Should push within capacity")
+
+	h, err := q.PushHandle(2)
+	require.ErrorIs(t, err, collections.ErrBufferFull, "Should refuse to push beyond capacity")
+	require.Nil(t, h, "Should give no handle for a refused push")
+}
+
+// TestPriorityQueueComparator checks the queue follows a custom comparator
+func TestPriorityQueueComparator(t *testing.T) {
+	type job struct {
+		name     string
+		priority int
+	}
+
+	// Highest priority first, then by name
+	q := NewFunc(collections.CapacityInfinite, func(a, b job) int {
+		return cmp.Or(cmp.Compare(b.priority, a.priority), cmp.Compare(a.name, b.name))
+	})
+	require.NoError(t, q.PushAll(job{"b", 1}, job{"a", 5}, job{"c", 1}, job{"d", 9}))
+
+	var names []string
+	for q.Count() > 0 {
+		_, j := q.Pop()
+		names = append(names, j.name)
+	}
+	require.Equal(t, []string{"d", "a", "b", "c"}, names, "Should pop in comparator order")
+}
+
+// TestPriorityQueueConcurrent checks concurrent pushes, fixes, removes and pops each
+// item exactly once
+func TestPriorityQueueConcurrent(t *testing.T) {
+	const workers = 4
+	const perWorker = 500
+
+	q := New[int](collections.CapacityInfinite)
+	var lock sync.Mutex
+	var popped []int
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				v := w*perWorker + i
+				h, err := q.PushHandle(-v)
+				if err != nil {
+					t.Errorf("Should push: %v", err)
+					return
+				}
+				q.Fix(h, v)
+				if i%5 == 0 {
+					q.Remove(h)
+					continue
+				}
+
+				if found, item := q.Pop(); found {
+					lock.Lock()
+					popped = append(popped, item)
+					lock.Unlock()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for q.Count() > 0 {
+		_, item := q.Pop()
+		popped = append(popped, item)
+	}
+
+	// Each remove takes the item of its own handle, unless a pop got to it first
+	require.Equal(t, len(popped), len(slices.Compact(slices.Sorted(slices.Values(popped)))), "Should pop each item once")
+	require.LessOrEqual(t, len(popped), workers*perWorker, "Should pop no more than was pushed")
+	require.GreaterOrEqual(t, len(popped), workers*perWorker*4/5, "Should pop every item not removed")
+}
+
+// BenchmarkPriorityQueue pushes and pops a heap held at a steady size
+func BenchmarkPriorityQueue(b *testing.B) {
+	for _, arity := range []int{2, 4, 8} {
+		b.Run(fmt.Sprintf("Arity=%d", arity), func(b *testing.B) {
+			rng := rand.New(rand.NewSource(133713371337))
+			q := New[int](collections.CapacityInfinite, WithArity(arity))
+			for i := 0; i < 10000; i++ {
+				_ = q.Push(rng.Int())
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = q.Push(rng.Int())
+				q.Pop()
+			}
+		})
+	}
+}
+
+// requireHeap checks no item in the heap is smaller than its parent
+func requireHeap(t *testing.T, q *PriorityQueue[int]) {
+	t.Helper()
+
+	for i := 1; i < len(q.heap.entries); i++ {
+		parent := (i - 1) / q.heap.arity
+		require.GreaterOrEqual(t, q.heap.entries[i].item, q.heap.entries[parent].item, "Should hold item %d no smaller than its parent", i)
+		if h := q.heap.entries[i].handle; h != nil {
+			require.Equal(t, i, h.index, "Should keep the handle of item %d up to date", i)
+		}
+	}
+}
+
+// requireDrains pops every item, checking they come back in order
+func requireDrains(t *testing.T, q *PriorityQueue[int], expected []int) {
+	t.Helper()
+
+	for i, v := range expected {
+		found, item := q.Pop()
+		require.True(t, found, "Should pop item %d", i)
+		require.Equal(t, v, item, "Should pop item %d in order", i)
+	}
+
+	found, _ := q.Pop()
+	require.False(t, found, "Should be drained")
+}
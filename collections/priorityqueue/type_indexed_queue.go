@@ -0,0 +1,39 @@
+package priorityqueue
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Ensure we meet the Queue[T] interface at compile time
+var _ collections.Queue[int] = (*Indexed[int, int, int])(nil)
+
+// Peek the value with the smallest priority
+func (q *Indexed[K, P, V]) Peek() (bool, V) {
+	found, item := q.PeekMin()
+	return found, item.Value
+}
+
+// Pop the value with the smallest priority
+func (q *Indexed[K, P, V]) Pop() (bool, V) {
+	found, item := q.PopMin()
+	return found, item.Value
+}
+
+// Push a value into the queue, under the ID and priority it gets from the key and
+// priority functions of the queue. A value already held for the ID is replaced. Returns
+// an error if the ID is not held and the queue is full, or if the queue was built
+// without either function.
+func (q *Indexed[K, P, V]) Push(value V) error {
+	if q.keyOf == nil || q.priorityOf == nil {
+		return ErrNoKeyFunctions
+	}
+
+	item := IndexedItem[K, P, V]{
+		ID:       q.keyOf(value),
+		Priority: q.priorityOf(value),
+		Value:    value,
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.upsertInternal(item)
+}
@@ -0,0 +1,52 @@
+package priorityqueue
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Ensure we meet the Queue[T] interface at compile time
+var _ collections.Queue[int] = (*PriorityQueue[int])(nil)
+
+// Peek the smallest item in the queue
+func (q *PriorityQueue[T]) Peek() (bool, T) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	if len(q.heap.entries) == 0 {
+		var blank T
+		return false, blank
+	}
+
+	return true, q.heap.entries[0].item
+}
+
+// Pop the smallest item from the queue
+func (q *PriorityQueue[T]) Pop() (bool, T) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.heap.entries) == 0 {
+		var blank T
+		return false, blank
+	}
+
+	return true, q.heap.removeAt(0)
+}
+
+// Push an item into the queue. Returns an error if the queue is full.
+func (q *PriorityQueue[T]) Push(item T) error {
+	q.lock.Lock()
+	_, err := q.pushInternal(item, nil)
+	q.lock.Unlock()
+
+	return err
+}
+
+// pushInternal adds an item with the lock held, with a handle if it has one
+func (q *PriorityQueue[T]) pushInternal(item T, handle *Handle[T]) (*Handle[T], error) {
+	if q.full(1) {
+		return nil, collections.ErrBufferFull
+	}
+
+	q.heap.push(item, handle)
+
+	return handle, nil
+}
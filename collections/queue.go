@@ -1,5 +1,7 @@
 package collections
 
+import "context"
+
 // Queue is an interface that describes any LIFO/FIFO queue of values
 type Queue[T any] interface {
 	// Count the number of records in the buffer
@@ -20,3 +22,22 @@ type Queue[T any] interface {
 	// Push a new item into the buffer
 	Push(t T) error
 }
+
+// BlockingQueue is a Queue that producers and consumers can wait on, rather than
+// retrying pushes to a full queue or pops from an empty one.
+type BlockingQueue[T any] interface {
+	Queue[T]
+
+	// PushWait pushes an item, waiting for room if the queue is full. Returns the
+	// error of the context if it is done first, or ErrClosed if the queue is closed.
+	PushWait(ctx context.Context, t T) error
+
+	// PopWait pops an item, waiting for one if the queue is empty. Returns the error of
+	// the context if it is done first. Once the queue is closed, the items left in it
+	// can still be popped, after which ErrClosed is returned.
+	PopWait(ctx context.Context) (T, error)
+
+	// Close the queue to further pushes, waking every waiting goroutine. Closing a
+	// closed queue does nothing.
+	Close()
+}
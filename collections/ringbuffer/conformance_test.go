@@ -0,0 +1,23 @@
+package ringbuffer
+
+import (
+	"testing"
+
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/collectionstest"
+)
+
+// TestQueueConformance checks the buffer honours the Queue[T] contract, within its
+// capacity
+func TestQueueConformance(t *testing.T) {
+	collectionstest.RunQueueSuite(t, collectionstest.FIFO, func(capacity int) collections.Queue[int] {
+		return New[int](capacity)
+	}, collectionstest.WithBoundedCapacity())
+}
+
+// TestBlockingQueueConformance checks the buffer honours the BlockingQueue[T] contract
+func TestBlockingQueueConformance(t *testing.T) {
+	collectionstest.RunBlockingQueueSuite(t, func(capacity int) collections.BlockingQueue[int] {
+		return New[int](capacity)
+	}, collectionstest.WithBoundedCapacity())
+}
@@ -2,13 +2,22 @@ package ringbuffer
 
 import (
 	"sync"
+
+	"github.com/zeroflucs-given/generics/collections/internal/signal"
 )
 
 // New is a fixed-size ring/circle buffer of values.
-func New[T any](size int) *RingBuffer[T] {
+func New[T any](size int, opts ...Option[T]) *RingBuffer[T] {
+	o := options[T]{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return &RingBuffer[T]{
-		capacity: size,
-		data:     make([]T, size+1), // We actually keep+1
+		capacity:  size,
+		data:      make([]T, size+1), // We actually keep+1
+		overwrite: o.overwrite,
+		onEvict:   o.onEvict,
 	}
 }
 
@@ -18,6 +27,11 @@ type RingBuffer[T any] struct {
 	head     int
 	data     []T
 	lock     sync.RWMutex
+	closed   bool
+	changed  signal.Broadcast // Notified as items are pushed or popped, and on close
+
+	overwrite bool    // Evict the oldest item when full, rather than refusing a push
+	onEvict   func(T) // Called with each evicted item, if set
 }
 
 // Capacity of the buffer
@@ -28,13 +42,18 @@ func (b *RingBuffer[T]) Capacity() int {
 // Count the number of records in the buffer
 func (b *RingBuffer[T]) Count() int {
 	b.lock.RLock()
-	head := b.head
-	cursor := b.cursor
+	count := b.countInternal()
 	b.lock.RUnlock()
 
-	if head < cursor {
+	return count
+}
+
+// countInternal counts the records with the lock held
+func (b *RingBuffer[T]) countInternal() int {
+	head := b.head
+	if head < b.cursor {
 		head = head + b.capacity + 1
 	}
 
-	return head - cursor
+	return head - b.cursor
 }
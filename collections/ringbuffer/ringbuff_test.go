@@ -1,6 +1,7 @@
 package ringbuffer
 
 import (
+	"context"
 	"math/rand"
 	"testing"
 
@@ -124,3 +125,103 @@ func BenchmarkRingBuffer(b *testing.B) {
 	}
 
 }
+
+// TestRingBufferOverwrite performs a random sequence of pushes and pops against an
+// overwriting buffer, checking the window it holds and the items it evicts.
+func TestRingBufferOverwrite(t *testing.T) {
+	rng := rand.New(rand.NewSource(133713371337))
+	testSize := 13
+
+	var evicted []int
+	buff := New[int](testSize, WithOverwrite, WithEviction(func(v int) {
+		evicted = append(evicted, v)
+	}))
+
+	var expected, expectedEvicted []int
+	for i := 0; i < 10000; i++ {
+		if rng.Float64() >= 0.3 {
+			require.NoError(t, buff.Push(i), "Should never be full")
+			expected = append(expected, i)
+			if len(expected) > testSize {
+				expectedEvicted = append(expectedEvicted, expected[0])
+				expected = expected[1:]
+			}
+		} else {
+			hasValue, v := buff.Pop()
+			require.Equal(t, len(expected) > 0, hasValue, "Should pop if anything is held")
+			if hasValue {
+				require.Equal(t, expected[0], v, "Should pop the oldest item")
+				expected = expected[1:]
+			}
+		}
+
+		require.Equal(t, expectedEvicted, evicted, "Should evict the oldest items")
+		requireWindow(t, buff, expected)
+	}
+}
+
+// TestRingBufferWindow checks the window of a buffer that refuses pushes when full
+func TestRingBufferWindow(t *testing.T) {
+	buff := New[int](4)
+	requireWindow(t, buff, nil)
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, buff.Push(i), "Should not error")
+	}
+	require.ErrorIs(t, buff.Push(4), collections.ErrBufferFull, "Should refuse a push")
+	requireWindow(t, buff, []int{0, 1, 2, 3})
+
+	// Wrap the items around the end of the data
+	buff.Pop()
+	buff.Pop()
+	require.NoError(t, buff.Push(4), "Should not error")
+	require.NoError(t, buff.Push(5), "Should not error")
+	requireWindow(t, buff, []int{2, 3, 4, 5})
+
+	for i := range buff.All() {
+		if i == 1 {
+			break
+		}
+	}
+	buff.Pop()
+	require.NoError(t, buff.Push(6), "Should release the lock when iteration stops early")
+}
+
+// TestRingBufferOverwriteNoCapacity checks a buffer with no room evicts items as they
+// are pushed
+func TestRingBufferOverwriteNoCapacity(t *testing.T) {
+	var evicted []int
+	buff := New[int](0, WithOverwrite, WithEviction(func(v int) {
+		evicted = append(evicted, v)
+	}))
+
+	require.NoError(t, buff.Push(1), "Should never be full")
+	require.NoError(t, buff.PushWait(context.Background(), 2), "Should never wait")
+	require.Equal(t, []int{1, 2}, evicted, "Should evict each item pushed")
+	requireWindow(t, buff, nil)
+}
+
+// requireWindow checks every way of reading the window of a buffer against the model
+func requireWindow(t *testing.T, buff *RingBuffer[int], expected []int) {
+	t.Helper()
+
+	require.Equal(t, len(expected), buff.Count(), "Should count the items held")
+	require.Equal(t, append([]int{}, expected...), buff.Snapshot(), "Should copy the items oldest first")
+
+	var all []int
+	for i, v := range buff.All() {
+		require.Equal(t, len(all), i, "Should iterate the positions in order")
+		all = append(all, v)
+	}
+	require.Equal(t, expected, all, "Should iterate the items oldest first")
+
+	for i, v := range expected {
+		hasValue, value := buff.At(i)
+		require.True(t, hasValue, "Should have an item at %d", i)
+		require.Equal(t, v, value, "Should have the right item at %d", i)
+	}
+	hasValue, _ := buff.At(len(expected))
+	require.False(t, hasValue, "Should have nothing beyond the newest item")
+	hasValue, _ = buff.At(-1)
+	require.False(t, hasValue, "Should have nothing before the oldest item")
+}
@@ -0,0 +1,72 @@
+package ringbuffer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// Ensure we meet the BlockingQueue[T] interface at compile time
+var _ collections.BlockingQueue[int] = (*RingBuffer[int])(nil)
+
+// PushWait pushes an item into the ring buffer, waiting for room if it is full. Buffers
+// built WithOverwrite are never full, so never wait.
+func (b *RingBuffer[T]) PushWait(ctx context.Context, item T) error {
+	for {
+		b.lock.Lock()
+
+		evicted, old, err := b.pushInternal(item)
+		if !errors.Is(err, collections.ErrBufferFull) {
+			b.lock.Unlock()
+
+			if evicted && b.onEvict != nil {
+				b.onEvict(old)
+			}
+			return err
+		}
+
+		changed := b.changed.Wait()
+		b.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// PopWait pops an item from the ring buffer, waiting for one if it is empty
+func (b *RingBuffer[T]) PopWait(ctx context.Context) (T, error) {
+	for {
+		b.lock.Lock()
+
+		found, result := b.popInternal()
+		if found {
+			b.lock.Unlock()
+			return result, nil
+		}
+		if b.closed {
+			b.lock.Unlock()
+			return result, collections.ErrClosed
+		}
+
+		changed := b.changed.Wait()
+		b.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+}
+
+// Close the ring buffer to further pushes, waking everything waiting on it
+func (b *RingBuffer[T]) Close() {
+	b.lock.Lock()
+	b.closed = true
+	b.changed.Notify()
+	b.lock.Unlock()
+}
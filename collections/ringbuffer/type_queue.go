@@ -30,11 +30,32 @@ func (b *RingBuffer[T]) Peek() (bool, T) {
 // Pop an item from the ring buffer
 func (b *RingBuffer[T]) Pop() (bool, T) {
 	b.lock.Lock()
+	found, result := b.popInternal()
+	b.lock.Unlock()
+
+	return found, result
+}
+
+// Push an item into the ring-buffer. Returns an error if we overflow
+// the buffer, or it has been closed. Buffers built WithOverwrite evict
+// their oldest item instead of overflowing.
+func (b *RingBuffer[T]) Push(item T) error {
+	b.lock.Lock()
+	evicted, old, err := b.pushInternal(item)
+	b.lock.Unlock()
+
+	if evicted && b.onEvict != nil {
+		b.onEvict(old)
+	}
 
+	return err
+}
+
+// popInternal pops an item with the lock held
+func (b *RingBuffer[T]) popInternal() (bool, T) {
 	// Buffers is empty
 	if b.cursor == b.head {
 		var def T
-		b.lock.Unlock()
 		return false, def
 	}
 
@@ -44,30 +65,44 @@ func (b *RingBuffer[T]) Pop() (bool, T) {
 		b.cursor = 0 // Wrap
 	}
 
-	b.lock.Unlock()
+	b.changed.Notify()
 
 	return true, result
 }
 
-// Push an item into the ring-buffer. Returns an error if we overflow
-// the buffer
-func (b *RingBuffer[T]) Push(item T) error {
-	b.lock.Lock()
+// pushInternal pushes an item with the lock held. If an item was evicted to make room
+// it is returned, for the caller to hand to the eviction callback once the lock is
+// released.
+func (b *RingBuffer[T]) pushInternal(item T) (bool, T, error) {
+	var evicted T
+	if b.closed {
+		return false, evicted, collections.ErrClosed
+	}
 
 	newHead := b.head + 1
 	if newHead == b.capacity+1 {
 		newHead = 0
 	}
 
-	if newHead == b.cursor {
-		b.lock.Unlock()
-		return fmt.Errorf("cursor wrapped at index %d: data may be lost: %w", newHead, collections.ErrBufferFull)
+	full := newHead == b.cursor
+	if full {
+		if !b.overwrite {
+			return false, evicted, fmt.Errorf("cursor wrapped at index %d: data may be lost: %w", newHead, collections.ErrBufferFull)
+		}
+
+		// A buffer with no capacity evicts the item straight away
+		if b.cursor == b.head {
+			return true, item, nil
+		}
+
+		// Move the cursor past the oldest item, making room
+		_, evicted = b.popInternal()
 	}
 
 	b.data[b.head] = item
 	b.head = newHead
 
-	b.lock.Unlock()
+	b.changed.Notify()
 
-	return nil
+	return full, evicted, nil
 }
@@ -0,0 +1,53 @@
+package ringbuffer
+
+import "iter"
+
+// At gets the item at a position in the buffer, where 0 is the oldest. The boolean
+// indicates if the position was within the buffer.
+func (b *RingBuffer[T]) At(i int) (bool, T) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if i < 0 || i >= b.countInternal() {
+		var blank T
+		return false, blank
+	}
+
+	return true, b.data[b.indexOf(i)]
+}
+
+// All iterates the items from oldest to newest, along with their positions. The buffer
+// is read-locked until the iteration completes or is stopped, so the loop body must not
+// call back into the buffer.
+func (b *RingBuffer[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		b.lock.RLock()
+		defer b.lock.RUnlock()
+
+		for i := range b.countInternal() {
+			if !yield(i, b.data[b.indexOf(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Snapshot copies the items from oldest to newest
+func (b *RingBuffer[T]) Snapshot() []T {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	// The items either run straight through the data, or wrap around its end
+	result := make([]T, 0, b.countInternal())
+	if b.cursor <= b.head {
+		return append(result, b.data[b.cursor:b.head]...)
+	}
+
+	result = append(result, b.data[b.cursor:]...)
+	return append(result, b.data[:b.head]...)
+}
+
+// indexOf gets the index in the data of a position, with the lock held
+func (b *RingBuffer[T]) indexOf(i int) int {
+	return (b.cursor + i) % (b.capacity + 1)
+}
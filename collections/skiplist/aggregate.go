@@ -0,0 +1,107 @@
+package skiplist
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Aggregates
+//
+// A list built WithAggregate keeps, on every link that leads somewhere, the combined
+// values of the records it skips over: those after the node it leaves, up to and
+// including the node it leads to. A link at the bottom level skips a single record. A
+// link higher up skips exactly the records of the links below it between the same two
+// nodes, so it is recomputed by combining theirs, which takes a handful of steps.
+//
+// A write only changes the links along its path, so once it is done they are brought
+// up to date from the bottom level up.
+
+// aggregator combines values for range reductions. Combining identity with any value
+// gives back the value, and combine is associative.
+type aggregator[V any] struct {
+	identity V
+	combine  func(a, b V) V
+}
+
+// Aggregate combines the values of the records with keys between from and to inclusive,
+// in key order. From the last node before the range, we keep following the longest
+// link that stays within it, combining the aggregate of each.
+func (t *skipList[K, V]) Aggregate(from K, to K) V {
+	if t.aggregates == nil {
+		panic(collections.ErrNoAggregate)
+	}
+
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	result := t.aggregates.identity
+	if t.compare(from, to) > 0 {
+		return result
+	}
+
+	height := int(t.height.Load())
+	path, _ := t.path(t.before(from))
+	for at := path[0]; at != nil; {
+		var following *node[K, V]
+		for level := min(len(at.links), height) - 1; level >= 0; level-- {
+			next := at.links[level].next.Load()
+			if next != nil && t.compare(next.key, to) <= 0 {
+				result = t.aggregates.combine(result, at.links[level].aggregate)
+				following = next
+				break
+			}
+		}
+
+		at = following
+	}
+
+	return result
+}
+
+// refreshPath recomputes the aggregates of the links along a path, and those of a node
+// newly linked in after it, from the bottom level up. The caller must hold the write
+// lock.
+func (t *skipList[K, V]) refreshPath(path *[MaxLevel]*node[K, V], inserted *node[K, V], height int) {
+	if t.aggregates == nil {
+		return
+	}
+
+	for level := 0; level < height; level++ {
+		if inserted != nil && level < len(inserted.links) {
+			t.summarize(inserted, level)
+		}
+		t.summarize(path[level], level)
+	}
+}
+
+// refreshAll recomputes the aggregates of every link, from the bottom level up. The
+// caller must hold the write lock, or be the only one to know of the list.
+func (t *skipList[K, V]) refreshAll() {
+	if t.aggregates == nil {
+		return
+	}
+
+	for level := 0; level < int(t.height.Load()); level++ {
+		for current := t.head; current != nil; current = current.links[level].next.Load() {
+			t.summarize(current, level)
+		}
+	}
+}
+
+// summarize recomputes the aggregate of a link from the links beneath it, which must
+// already be up to date.
+func (t *skipList[K, V]) summarize(from *node[K, V], level int) {
+	target := &from.links[level]
+	to := target.next.Load()
+	if to == nil {
+		return
+	}
+
+	if level == 0 {
+		target.aggregate = to.loadValue()
+		return
+	}
+
+	result := t.aggregates.identity
+	for current := from; current != to; current = current.links[level-1].next.Load() {
+		result = t.aggregates.combine(result, current.links[level-1].aggregate)
+	}
+	target.aggregate = result
+}
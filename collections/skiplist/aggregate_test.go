@@ -0,0 +1,47 @@
+package skiplist
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAggregateKeyOrder combines values that don't commute, which only come out right
+// if they are combined in key order.
+func TestAggregateKeyOrder(t *testing.T) {
+	concat := func(a string, b string) string {
+		return a + b
+	}
+	list, err := New[int, string](WithSeed(1), WithAggregate("", concat))
+	require.NoError(t, err, "Should be able to initialize")
+
+	for _, k := range rand.New(rand.NewSource(1)).Perm(26) {
+		list.Insert(k, string(rune('a'+k)))
+	}
+	requireConsistent(t, asList(t, list))
+
+	require.Equal(t, "abcdefghijklmnopqrstuvwxyz", list.Aggregate(0, 25), "Should combine everything in key order")
+	require.Equal(t, "efghij", list.Aggregate(4, 9), "Should combine the range in key order")
+	require.Equal(t, "z", list.Aggregate(25, 100), "Should combine the last record")
+	require.Equal(t, "", list.Aggregate(9, 4), "Reversed bounds should give the identity")
+
+	list.Update(4, func(old string, exists bool) string { return "E" })
+	list.Delete(5)
+	require.Equal(t, "dEghij", list.Aggregate(3, 9), "Should follow writes")
+	requireConsistent(t, asList(t, list))
+}
+
+// TestAggregateDuplicateKeys aggregates keys with many records each
+func TestAggregateDuplicateKeys(t *testing.T) {
+	list, err := New[int, int](WithSeed(1), WithAggregate(0, sum))
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i++ {
+		list.Insert(i%4, 1)
+	}
+
+	require.Equal(t, 25, list.Aggregate(1, 1), "Should combine every record of the key")
+	require.Equal(t, 50, list.Aggregate(2, 3), "Should combine every record of the keys")
+	require.Equal(t, 100, list.Aggregate(-1, 4), "Should combine every record")
+}
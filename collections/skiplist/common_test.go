@@ -0,0 +1,82 @@
+package skiplist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// asList gets at the implementation behind a map
+func asList[K any, V any](t *testing.T, m collections.TreeMap[K, V]) *skipList[K, V] {
+	list, ok := m.(*skipList[K, V])
+	require.True(t, ok, "Should be a skip list")
+	return list
+}
+
+// requireConsistent checks every invariant of a list: the order and back links of the
+// records, the spans and aggregates of every link, and the index.
+func requireConsistent[K any, V any](t *testing.T, list *skipList[K, V]) {
+	t.Helper()
+
+	// Number the records by their position
+	positions := map[*node[K, V]]int{list.head: 0}
+	var records []*node[K, V]
+	var previous *node[K, V]
+	for current := list.head.next(); current != nil; current = current.next() {
+		require.False(t, current.removed.Load(), "Removed record %d should be unlinked", current.id)
+		require.Equal(t, previous, current.prev.Load(), "Record %d should link back", current.id)
+		if previous != nil {
+			comparison := list.compare(previous.key, current.key)
+			require.True(t, comparison < 0 || (comparison == 0 && previous.id < current.id), "Record %d should follow %d", current.id, previous.id)
+		}
+
+		records = append(records, current)
+		positions[current] = len(records)
+		previous = current
+	}
+	require.Equal(t, previous, list.tail.Load(), "Tail should be the last record")
+	require.Equal(t, len(records), list.Count(), "Count should match the records")
+
+	// Every link of the levels in use skips the right number of records
+	height := int(list.height.Load())
+	require.True(t, height == 1 || list.head.links[height-1].next.Load() != nil, "Top level should be in use")
+	for level := 0; level < MaxLevel; level++ {
+		if level >= height {
+			require.Nil(t, list.head.links[level].next.Load(), "Level %d should be unused", level)
+			continue
+		}
+
+		for current := list.head; current != nil; current = current.links[level].next.Load() {
+			require.Greater(t, len(current.links), level, "Node should have level %d", level)
+			link := &current.links[level]
+			next := link.next.Load()
+			if next == nil {
+				require.Equal(t, len(records)-positions[current], link.span, "Last link of level %d should skip the rest", level)
+				continue
+			}
+
+			position, found := positions[next]
+			require.True(t, found, "Level %d should only link records", level)
+			require.Greater(t, position, positions[current], "Level %d should move forward", level)
+			require.Equal(t, position-positions[current], link.span, "Link of level %d should skip to the next node", level)
+
+			if list.aggregates != nil {
+				expected := list.aggregates.identity
+				for _, skipped := range records[positions[current]:position] {
+					expected = list.aggregates.combine(expected, skipped.loadValue())
+				}
+				require.Equal(t, expected, link.aggregate, "Link of level %d should aggregate what it skips", level)
+			}
+		}
+	}
+
+	// The index holds exactly our records
+	require.Len(t, list.index, len(records), "Index should hold every record")
+	for _, record := range records {
+		key, found := list.index[record.id]
+		require.True(t, found, "Record %d should be indexed", record.id)
+		require.Zero(t, list.compare(key, record.key), "Record %d should be indexed by its key", record.id)
+	}
+}
@@ -0,0 +1,159 @@
+package skiplist
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestConcurrentReads runs writers of every kind against readers that take no lock,
+// checking the readers only ever see records in order, with the values written for
+// them. Run with -race to check the publication of nodes.
+func TestConcurrentReads(t *testing.T) {
+	list, err := New[int, int](WithSeed(1), WithAggregate(0, sum))
+	require.NoError(t, err, "Should be able to initialize")
+
+	var writers sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		writers.Add(1)
+		go func(w int) {
+			defer writers.Done()
+			rnd := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 2000; i++ {
+				k := rnd.Intn(1000)
+				switch {
+				case w == 0 && i%10 == 0:
+					list.Delete(k)
+				case w == 1 && i%50 == 0:
+					list.DeleteRange(k, k+20)
+				case w == 2 && i%100 == 0:
+					list.Snapshot()
+				case w == 3 && i%5 == 0:
+					list.Upsert(k, k)
+				default:
+					list.Insert(k, k)
+				}
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			rnd := rand.New(rand.NewSource(int64(100 + r)))
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				probe := rnd.Intn(1000)
+				if v, found := list.Get(probe); found {
+					assert.Equal(t, probe, v, "Should read the right value")
+				}
+				if k, _, found := list.Floor(probe); found {
+					assert.LessOrEqual(t, k, probe, "Floor should not exceed the probe")
+				}
+				if k, _, found := list.Higher(probe); found {
+					assert.Greater(t, k, probe, "Higher should exceed the probe")
+				}
+				for _, kvp := range list.Range(probe, probe+50) {
+					assert.Equal(t, kvp.Key, kvp.Value, "Range should read the right values")
+					assert.True(t, kvp.Key >= probe && kvp.Key <= probe+50, "Range should stay within its bounds")
+				}
+				list.Rank(probe)
+				list.Aggregate(probe, probe+50)
+
+				last := -1
+				for k := range list.All() {
+					assert.GreaterOrEqual(t, k, last, "Should iterate in order")
+					last = k
+					if k > probe {
+						break
+					}
+				}
+
+				c := list.Seek(probe)
+				last = probe
+				for c.Next() {
+					assert.GreaterOrEqual(t, c.Key(), last, "Cursor should move forward in order")
+					last = c.Key()
+				}
+				c = list.Seek(probe)
+				last = probe
+				for c.Prev() {
+					assert.Less(t, c.Key(), probe, "Cursor should move back from before the position")
+					assert.LessOrEqual(t, c.Key(), last, "Cursor should move backward in order")
+					last = c.Key()
+				}
+				assert.NoError(t, c.Err(), "Writes should not invalidate the cursor")
+			}
+		}(r)
+	}
+
+	writers.Wait()
+	close(done)
+	readers.Wait()
+
+	requireConsistent(t, asList(t, list))
+	count := 0
+	for range list.All() {
+		count++
+	}
+	require.Equal(t, count, list.Count(), "Count should match the records held")
+}
+
+// TestConcurrentSplitAndMerge splits and merges lists while readers walk them
+func TestConcurrentSplitAndMerge(t *testing.T) {
+	list, err := New[int, int](WithSeed(1))
+	require.NoError(t, err, "Should be able to initialize")
+	for i := 0; i < 1000; i++ {
+		list.Insert(i, i)
+	}
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			c := list.Seek(0)
+			last := -1
+			for c.Next() {
+				assert.Greater(t, c.Key(), last, "Cursor should move forward in order")
+				last = c.Key()
+			}
+			if c.Err() != nil {
+				assert.ErrorIs(t, c.Err(), collections.ErrCursorInvalidated, "Should only fail by invalidation")
+			}
+		}
+	}()
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		left, right := list.SplitAt(rnd.Intn(1000))
+		list.Merge(left)
+		list.Merge(right)
+	}
+
+	close(done)
+	readers.Wait()
+
+	require.Equal(t, 1000, list.Count(), "Should hold every record")
+	requireConsistent(t, asList(t, list))
+}
@@ -0,0 +1,38 @@
+package skiplist
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/collectionstest"
+)
+
+// TestConformance checks the list honours the TreeMap[K, V] contract, with and without
+// its optional behaviours
+func TestConformance(t *testing.T) {
+	for _, unique := range []bool{false, true} {
+		for _, summed := range []bool{false, true} {
+			t.Run(fmt.Sprintf("UniqueKeys=%v_Aggregate=%v", unique, summed), func(t *testing.T) {
+				opts := []Option{WithSeed(1)}
+				var suiteOpts []collectionstest.Option
+				if unique {
+					opts = append(opts, WithUniqueKeys())
+					suiteOpts = append(suiteOpts, collectionstest.WithUniqueKeys())
+				}
+				if summed {
+					opts = append(opts, WithAggregate(0, sum))
+					suiteOpts = append(suiteOpts, collectionstest.WithSummedAggregate())
+				}
+
+				collectionstest.RunTreeMapSuite(t, func() collections.TreeMap[int, int] {
+					list, err := New[int, int](opts...)
+					if err != nil {
+						t.Fatalf("Error: %v", err)
+					}
+					return list
+				}, suiteOpts...)
+			})
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package skiplist
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// cursorState describes where a cursor sits relative to the records of the list
+type cursorState int
+
+const (
+	cursorSeeking     cursorState = iota // Just before the seek position, no current record
+	cursorOnRecord                       // On a record
+	cursorBeforeStart                    // Moved off the front of the list
+	cursorAfterEnd                       // Moved off the back of the list
+	cursorClosed                         // Closed by the consumer
+)
+
+// cursor is a movable position within the list. Like every other reader it takes no
+// lock, and writes to the list don't invalidate it: if its record is removed, it
+// carries on from the links the record had when it went. Only handing the nodes of
+// the list on, as SplitAt and Merge do, fails the next move with ErrCursorInvalidated.
+type cursor[K any, V any] struct {
+	list   *skipList[K, V]
+	state  cursorState
+	node   *node[K, V] // Current record, or the first at or after the seek position
+	before *node[K, V] // Last record before the seek position
+	resets uint64      // Resets of the list when we were created
+	err    error
+
+	// The current record, copied when we moved onto it
+	key      K
+	value    V
+	recordID collections.RecordID
+}
+
+// Seek creates a cursor positioned just before the first record with a key greater
+// than or equal to the key. The records either side of the position are found up
+// front, so records added around it later are not mistaken for being beyond it.
+func (t *skipList[K, V]) Seek(key K) collections.Cursor[K, V] {
+	resets := t.resets.Load()
+	before, after := t.predecessor(t.before(key))
+
+	c := &cursor[K, V]{
+		list:   t,
+		state:  cursorSeeking,
+		node:   live(after),
+		resets: resets,
+	}
+	if before != t.head {
+		c.before = liveBefore(before)
+	}
+
+	return c
+}
+
+// Next moves to the following record
+func (c *cursor[K, V]) Next() bool {
+	return c.move(func() *node[K, V] {
+		switch c.state {
+		case cursorSeeking:
+			return live(c.node)
+		case cursorOnRecord:
+			return live(c.node.next())
+		case cursorBeforeStart:
+			return live(c.list.head.next())
+		default:
+			return nil
+		}
+	}, cursorAfterEnd)
+}
+
+// Prev moves to the preceding record
+func (c *cursor[K, V]) Prev() bool {
+	return c.move(func() *node[K, V] {
+		switch c.state {
+		case cursorSeeking:
+			return liveBefore(c.before)
+		case cursorOnRecord:
+			return liveBefore(c.node.prev.Load())
+		case cursorAfterEnd:
+			return liveBefore(c.list.tail.Load())
+		default:
+			return nil
+		}
+	}, cursorBeforeStart)
+}
+
+// Key of the current record
+func (c *cursor[K, V]) Key() K {
+	return c.key
+}
+
+// Value of the current record
+func (c *cursor[K, V]) Value() V {
+	return c.value
+}
+
+// RecordID of the current record
+func (c *cursor[K, V]) RecordID() collections.RecordID {
+	return c.recordID
+}
+
+// Err gets the reason the cursor stopped being usable
+func (c *cursor[K, V]) Err() error {
+	return c.err
+}
+
+// Close the cursor. Any further moves will fail.
+func (c *cursor[K, V]) Close() {
+	c.state = cursorClosed
+	c.node = nil
+	c.before = nil
+	c.clearRecord()
+}
+
+// move checks the list still has our nodes, then moves to the record picked by target.
+// If there is no such record, we park in the overflow state.
+func (c *cursor[K, V]) move(target func() *node[K, V], overflow cursorState) bool {
+	if c.state == cursorClosed || c.err != nil {
+		return false
+	}
+
+	// If the list handed its nodes on, ours may now belong to another list
+	if c.list.resets.Load() != c.resets {
+		c.err = collections.ErrCursorInvalidated
+		c.node = nil
+		c.before = nil
+		c.clearRecord()
+		return false
+	}
+
+	found := target()
+	c.before = nil
+	if found == nil {
+		c.state = overflow
+		c.node = nil
+		c.clearRecord()
+		return false
+	}
+
+	c.state = cursorOnRecord
+	c.node = found
+	c.key = found.key
+	c.value = found.loadValue()
+	c.recordID = found.id
+	return true
+}
+
+// clearRecord drops the copy of the current record
+func (c *cursor[K, V]) clearRecord() {
+	var blankKey K
+	var blankValue V
+	c.key = blankKey
+	c.value = blankValue
+	c.recordID = 0
+}
@@ -0,0 +1,119 @@
+package skiplist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// TestCursorWalk moves a cursor both ways across the list and off both ends
+func TestCursorWalk(t *testing.T) {
+	list, err := New[int, int](WithSeed(1))
+	require.NoError(t, err, "Should be able to initialize")
+
+	ids := map[int]collections.RecordID{}
+	for i := 0; i < 50; i++ {
+		ids[i*2] = list.Insert(i*2, i*20)
+	}
+
+	// Forward from a key that doesn't exist
+	c := list.Seek(21)
+	for expected := 22; expected < 100; expected += 2 {
+		require.True(t, c.Next(), "Should move onto %d", expected)
+		require.Equal(t, expected, c.Key(), "Should have the right key")
+		require.Equal(t, expected*10, c.Value(), "Should have the right value")
+		require.Equal(t, ids[expected], c.RecordID(), "Should have the right record ID")
+	}
+	require.False(t, c.Next(), "Should run off the end")
+	require.False(t, c.Next(), "Should stay off the end")
+	require.NoError(t, c.Err(), "Running off the end isn't an error")
+
+	// And back again, off the front
+	for expected := 98; expected >= 0; expected -= 2 {
+		require.True(t, c.Prev(), "Should move back onto %d", expected)
+		require.Equal(t, expected, c.Key(), "Should have the right key")
+	}
+	require.False(t, c.Prev(), "Should run off the front")
+	require.True(t, c.Next(), "Should come back onto the first record")
+	require.Equal(t, 0, c.Key(), "Should be on the first record")
+	c.Close()
+
+	require.False(t, c.Next(), "Should not move once closed")
+	require.False(t, c.Prev(), "Should not move once closed")
+}
+
+// TestCursorSeekPositions checks where Seek leaves the cursor, even once records are
+// added either side of it
+func TestCursorSeekPositions(t *testing.T) {
+	list, err := New[int, int](WithSeed(1))
+	require.NoError(t, err, "Should be able to initialize")
+
+	c := list.Seek(1)
+	require.False(t, c.Next(), "Should not move in an empty list")
+	require.False(t, c.Prev(), "Should not move in an empty list")
+
+	for i := 0; i < 10; i += 2 {
+		list.Insert(i, i)
+	}
+
+	c = list.Seek(5)
+	list.Insert(5, 5)
+	list.Insert(3, 3)
+	require.True(t, c.Prev(), "Should move before the seek position")
+	require.Equal(t, 4, c.Key(), "Should land on the key that preceded the position")
+	require.True(t, c.Next(), "Should move forward again")
+	require.Equal(t, 5, c.Key(), "Should see the record added after it")
+
+	c = list.Seek(100)
+	list.Insert(200, 200)
+	require.False(t, c.Next(), "Nothing should follow the end")
+	c = list.Seek(100)
+	require.True(t, c.Prev(), "Should move back from past the end")
+	require.Equal(t, 8, c.Key(), "Should land on the last key before the position")
+
+	c = list.Seek(-100)
+	require.False(t, c.Prev(), "Nothing should precede the start")
+	require.True(t, c.Next(), "Should move onto the first record")
+	require.Equal(t, 0, c.Key(), "Should land on the first key")
+}
+
+// TestCursorSurvivesRemoval checks a cursor carries on past its record being removed
+func TestCursorSurvivesRemoval(t *testing.T) {
+	list, err := New[int, int](WithSeed(1))
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i++ {
+		list.Insert(i, i)
+	}
+
+	c := list.Seek(50)
+	require.True(t, c.Next(), "Should move onto a record")
+	list.DeleteRange(45, 55)
+
+	require.True(t, c.Next(), "Should keep moving")
+	require.Equal(t, 56, c.Key(), "Should skip the removed records")
+	require.True(t, c.Prev(), "Should move back")
+	require.Equal(t, 44, c.Key(), "Should skip the removed records")
+	require.NoError(t, c.Err(), "Should not be invalidated")
+}
+
+// TestCursorInvalidatedBySplit checks cursors fail once the list hands its nodes on
+func TestCursorInvalidatedBySplit(t *testing.T) {
+	list, err := New[int, int](WithSeed(1))
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 100; i++ {
+		list.Insert(i, i)
+	}
+
+	c := list.Seek(50)
+	require.True(t, c.Next(), "Should move onto a record")
+	list.SplitAt(60)
+
+	require.False(t, c.Next(), "Should not move after the split")
+	require.ErrorIs(t, c.Err(), collections.ErrCursorInvalidated, "Should report invalidation")
+	require.False(t, c.Prev(), "Should stay invalidated")
+	require.Zero(t, c.Key(), "Should not hold a current record")
+}
@@ -0,0 +1,96 @@
+package skiplist
+
+import (
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// Get the value of the first record stored against a key
+func (t *skipList[K, V]) Get(key K) (V, bool) {
+	found := t.firstAfter(t.before(key))
+	if found == nil || t.compare(found.key, key) != 0 {
+		var blank V
+		return blank, false
+	}
+
+	return found.loadValue(), true
+}
+
+// GetAll gets the values of every record stored against a key, in the order they were
+// inserted
+func (t *skipList[K, V]) GetAll(key K) []V {
+	var result []V
+	for current := t.firstAfter(t.before(key)); current != nil && t.compare(current.key, key) == 0; current = live(current.next()) {
+		result = append(result, current.loadValue())
+	}
+
+	return result
+}
+
+// GetByID gets the key and value of the record with the specified ID
+func (t *skipList[K, V]) GetByID(id collections.RecordID) (K, V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	key, found := t.index[id]
+	if !found {
+		return recordOf[K, V](nil)
+	}
+
+	_, record := t.predecessor(t.recordBefore(key, id))
+	return recordOf(record)
+}
+
+// Range gets the records with keys between from and to inclusive, in key order
+func (t *skipList[K, V]) Range(from K, to K) []generics.KeyValuePair[K, V] {
+	var result []generics.KeyValuePair[K, V]
+	for current := t.firstAfter(t.before(from)); current != nil && t.compare(current.key, to) <= 0; current = live(current.next()) {
+		result = append(result, generics.KeyValuePair[K, V]{
+			Key:   current.key,
+			Value: current.loadValue(),
+		})
+	}
+
+	return result
+}
+
+// Floor gets the record with the largest key less than or equal to the key
+func (t *skipList[K, V]) Floor(key K) (K, V, bool) {
+	return recordOf(t.lastBefore(t.atOrBefore(key)))
+}
+
+// Ceiling gets the record with the smallest key greater than or equal to the key
+func (t *skipList[K, V]) Ceiling(key K) (K, V, bool) {
+	return recordOf(t.firstAfter(t.before(key)))
+}
+
+// Lower gets the record with the largest key strictly less than the key
+func (t *skipList[K, V]) Lower(key K) (K, V, bool) {
+	return recordOf(t.lastBefore(t.before(key)))
+}
+
+// Higher gets the record with the smallest key strictly greater than the key
+func (t *skipList[K, V]) Higher(key K) (K, V, bool) {
+	return recordOf(t.firstAfter(t.atOrBefore(key)))
+}
+
+// Min gets the record with the smallest key
+func (t *skipList[K, V]) Min() (K, V, bool) {
+	return recordOf(live(t.head.next()))
+}
+
+// Max gets the record with the largest key
+func (t *skipList[K, V]) Max() (K, V, bool) {
+	return recordOf(liveBefore(t.tail.Load()))
+}
+
+// recordOf reads the key and value of a node, where nil means there is no record
+func recordOf[K any, V any](n *node[K, V]) (K, V, bool) {
+	if n == nil {
+		var blankKey K
+		var blankValue V
+		return blankKey, blankValue, false
+	}
+
+	return n.key, n.loadValue(), true
+}
@@ -0,0 +1,99 @@
+package skiplist
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Delete all records stored against a key. Returns true if any records were removed.
+func (t *skipList[K, V]) Delete(key K) bool {
+	t.lockWrite()
+	defer t.lock.Unlock()
+
+	path, _ := t.path(t.before(key))
+	removed := t.removeRun(&path, func(n *node[K, V]) bool {
+		return t.compare(n.key, key) == 0
+	})
+
+	return removed > 0
+}
+
+// DeleteByID removes the record with the specified ID. Returns true if the record was
+// found and removed.
+func (t *skipList[K, V]) DeleteByID(id collections.RecordID) bool {
+	t.lockWrite()
+	defer t.lock.Unlock()
+
+	key, found := t.index[id]
+	if !found {
+		return false
+	}
+
+	path, _ := t.path(t.recordBefore(key, id))
+	removed := t.removeRun(&path, func(n *node[K, V]) bool {
+		return n.id == id
+	})
+
+	return removed > 0
+}
+
+// DeleteRange removes the records with keys between from and to inclusive, returning
+// how many were removed. The records of the range are unlinked together, so each level
+// is only relinked once.
+func (t *skipList[K, V]) DeleteRange(from K, to K) int {
+	t.lockWrite()
+	defer t.lock.Unlock()
+
+	if t.compare(from, to) > 0 {
+		return 0
+	}
+
+	path, _ := t.path(t.before(from))
+	return t.removeRun(&path, func(n *node[K, V]) bool {
+		return t.compare(n.key, to) <= 0
+	})
+}
+
+// removeRun removes the run of records that directly follow the path and match,
+// returning how many were removed. Every record of the run is marked as removed before
+// any are unlinked, so that readers already on one step over the rest. The caller must
+// hold the write lock.
+func (t *skipList[K, V]) removeRun(path *[MaxLevel]*node[K, V], matches func(n *node[K, V]) bool) int {
+	removed := 0
+	for current := path[0].next(); current != nil && matches(current); current = current.next() {
+		current.removed.Store(true)
+		delete(t.index, current.id)
+		removed++
+	}
+	if removed == 0 {
+		return 0
+	}
+
+	// Each level skips from the path over the removed nodes it links, taking on their
+	// spans, but no longer counting the removed records.
+	height := int(t.height.Load())
+	for level := 0; level < height; level++ {
+		previous := &path[level].links[level]
+		next := previous.next.Load()
+		for next != nil && next.removed.Load() {
+			previous.span += next.links[level].span
+			next = next.links[level].next.Load()
+		}
+
+		previous.span -= removed
+		previous.next.Store(next)
+	}
+
+	before := path[0]
+	if before == t.head {
+		before = nil
+	}
+	if next := path[0].next(); next != nil {
+		next.prev.Store(before)
+	} else {
+		t.tail.Store(before)
+	}
+
+	t.settleHeight(height)
+	t.length.Add(int64(-removed))
+	t.refreshPath(path, nil, height)
+
+	return removed
+}
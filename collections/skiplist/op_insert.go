@@ -0,0 +1,73 @@
+package skiplist
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Insert a record into the list, after any others with the same key. If the list has
+// unique keys and the key already exists, its value is replaced instead. Returns the
+// ID of the record.
+func (t *skipList[K, V]) Insert(key K, value V) collections.RecordID {
+	t.lockWrite()
+	defer t.lock.Unlock()
+
+	return t.insertOrReplace(key, value)
+}
+
+// insertOrReplace inserts a record as Insert does, replacing the value of an existing
+// record if the list has unique keys. The caller must hold the write lock.
+func (t *skipList[K, V]) insertOrReplace(key K, value V) collections.RecordID {
+	if t.uniqueKeys {
+		recordID, _ := t.upsertInternal(key, value)
+		return recordID
+	}
+
+	path, ranks := t.path(t.atOrBefore(key))
+	id := t.nextID()
+	t.insertAt(&path, &ranks, key, id, value)
+
+	return id
+}
+
+// insertAt links a new record in after the path. The node is filled in completely
+// before it is published, from the bottom level up, so that a reader can follow it as
+// soon as it can reach it. The caller must hold the write lock.
+func (t *skipList[K, V]) insertAt(path *[MaxLevel]*node[K, V], ranks *[MaxLevel]int, key K, id collections.RecordID, value V) {
+	levels := t.randomLevel()
+	height := int(t.height.Load())
+	length := int(t.length.Load())
+
+	// New levels start out at the head, skipping every record
+	for level := height; level < levels; level++ {
+		path[level] = t.head
+		ranks[level] = 0
+		t.head.links[level].span = length
+	}
+
+	inserted := newRecord(key, id, value, levels)
+	for level := 0; level < levels; level++ {
+		previous := &path[level].links[level]
+		inserted.links[level].next.Store(previous.next.Load())
+		inserted.links[level].span = previous.span - (ranks[0] - ranks[level])
+		previous.span = ranks[0] - ranks[level] + 1
+	}
+	for level := levels; level < height; level++ {
+		path[level].links[level].span++
+	}
+	if path[0] != t.head {
+		inserted.prev.Store(path[0])
+	}
+
+	for level := 0; level < levels; level++ {
+		path[level].links[level].next.Store(inserted)
+	}
+	if next := inserted.next(); next != nil {
+		next.prev.Store(inserted)
+	} else {
+		t.tail.Store(inserted)
+	}
+
+	height = max(height, levels)
+	t.height.Store(int32(height))
+	t.length.Add(1)
+	t.index[id] = key
+	t.refreshPath(path, inserted, height)
+}
@@ -0,0 +1,102 @@
+package skiplist
+
+import (
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// Merge moves the records of another list into this one, leaving it empty. The records
+// are linked in one at a time. They keep their IDs, unless one is already taken by a
+// record of ours, in which case they are all given new IDs following on from ours,
+// offset so that they keep their order among themselves. Lists split apart by SplitAt
+// never share an ID, so can always be put back together as they were.
+//
+// Read-only lists, such as snapshots, and other implementations are copied from rather
+// than emptied.
+func (t *skipList[K, V]) Merge(other collections.TreeMap[K, V]) {
+	if t.readOnly {
+		panic(collections.ErrReadOnly)
+	}
+
+	source, ok := other.(*skipList[K, V])
+	if !ok || source.readOnly {
+		t.copyFrom(other)
+		return
+	} else if source == t {
+		return
+	}
+
+	// Lock both lists, always in the same order, so that two lists merging into each
+	// other can't deadlock.
+	first, second := t, source
+	if second.serial < first.serial {
+		first, second = second, first
+	}
+	first.lock.Lock()
+	defer first.lock.Unlock()
+	second.lock.Lock()
+	defer second.lock.Unlock()
+
+	// The nodes stay linked to each other once the source lets go of them
+	records := source.head.next()
+	recordCount := source.recordCount
+	offset := t.recordCount
+	if !t.sharesID(source.index) {
+		offset = 0
+	}
+	source.reset()
+
+	for current := records; current != nil; current = current.next() {
+		t.mergeRecord(current.key, current.id+offset, current.loadValue())
+	}
+	t.recordCount = max(t.recordCount, offset+recordCount)
+}
+
+// sharesID checks if any record in the index of another list has the ID of one of ours.
+// The caller must hold the write lock.
+func (t *skipList[K, V]) sharesID(index map[collections.RecordID]K) bool {
+	for id := range index {
+		if _, found := t.index[id]; found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeRecord links in a record from another list, with its ID already renumbered. If
+// the list has unique keys and the key already exists, its value is replaced instead.
+// The caller must hold the write lock.
+func (t *skipList[K, V]) mergeRecord(key K, id collections.RecordID, value V) {
+	if !t.uniqueKeys {
+		path, ranks := t.path(t.atOrBefore(key))
+		t.insertAt(&path, &ranks, key, id, value)
+		return
+	}
+
+	path, ranks := t.path(t.before(key))
+	if existing := path[0].next(); existing != nil && t.compare(existing.key, key) == 0 {
+		t.replace(&path, existing, value)
+		return
+	}
+	t.insertAt(&path, &ranks, key, id, value)
+}
+
+// copyFrom inserts the records of a list we can't take the nodes of. They are read
+// before taking our lock, as the other list may be a snapshot of this one.
+func (t *skipList[K, V]) copyFrom(other collections.TreeMap[K, V]) {
+	var records []generics.KeyValuePair[K, V]
+	for k, v := range other.All() {
+		records = append(records, generics.KeyValuePair[K, V]{
+			Key:   k,
+			Value: v,
+		})
+	}
+
+	t.lockWrite()
+	defer t.lock.Unlock()
+
+	for _, kvp := range records {
+		t.insertOrReplace(kvp.Key, kvp.Value)
+	}
+}
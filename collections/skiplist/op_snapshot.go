@@ -0,0 +1,82 @@
+package skiplist
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Snapshot takes a point-in-time, read-only copy of the list. Unlike a tree, a skip
+// list has no single root to freeze, so the records are copied, in time proportional
+// to their number, while the list is locked. Lookups and iteration carry on meanwhile,
+// as they take no lock. Writes to the snapshot panic with ErrReadOnly.
+func (t *skipList[K, V]) Snapshot() collections.TreeMap[K, V] {
+	if t.readOnly {
+		return t
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	result := t.emptyLike()
+
+	a := newAppender(result)
+	for current := t.head.next(); current != nil; current = current.next() {
+		a.append(current.key, current.id, current.loadValue())
+	}
+	a.finish()
+	result.readOnly = true
+
+	return result
+}
+
+// appender builds a list up by adding records to its end, for lists that nobody else
+// can see yet. It keeps the last node of each level, and its rank, to link the next
+// node to.
+type appender[K any, V any] struct {
+	list  *skipList[K, V]
+	last  [MaxLevel]*node[K, V]
+	ranks [MaxLevel]int
+}
+
+// newAppender creates an appender for an empty list
+func newAppender[K any, V any](list *skipList[K, V]) *appender[K, V] {
+	result := &appender[K, V]{
+		list: list,
+	}
+	for level := range result.last {
+		result.last[level] = list.head
+	}
+
+	return result
+}
+
+// append adds a record after all of those appended so far
+func (a *appender[K, V]) append(key K, id collections.RecordID, value V) {
+	list := a.list
+	rank := int(list.length.Load()) + 1
+	levels := list.randomLevel()
+
+	appended := newRecord(key, id, value, levels)
+	if a.last[0] != list.head {
+		appended.prev.Store(a.last[0])
+	}
+	for level := 0; level < levels; level++ {
+		a.last[level].links[level].next.Store(appended)
+		a.last[level].links[level].span = rank - a.ranks[level]
+		a.last[level] = appended
+		a.ranks[level] = rank
+	}
+
+	list.tail.Store(appended)
+	list.height.Store(int32(max(int(list.height.Load()), levels)))
+	list.length.Add(1)
+	list.index[id] = key
+}
+
+// finish sets the spans of the links that lead nowhere, now that we know how many
+// records there are, and works out the aggregates.
+func (a *appender[K, V]) finish() {
+	length := int(a.list.length.Load())
+	for level, last := range a.last {
+		last.links[level].span = length - a.ranks[level]
+	}
+
+	a.list.refreshAll()
+}
@@ -0,0 +1,72 @@
+package skiplist
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// SplitAt moves the records of the list into two new lists: those with keys less than
+// the key, and those with keys greater than or equal to it. The nodes move across as
+// they are, with only the links that cross the split point cut, though the record index
+// is shared out an entry at a time. The list is left empty, and its cursors are
+// invalidated.
+func (t *skipList[K, V]) SplitAt(key K) (collections.TreeMap[K, V], collections.TreeMap[K, V]) {
+	t.lockWrite()
+	defer t.lock.Unlock()
+
+	left := t.emptyLike()
+	right := t.emptyLike()
+
+	path, ranks := t.path(t.before(key))
+	height := int(t.height.Load())
+	length := int(t.length.Load())
+	split := ranks[0]
+
+	// The left list takes over the links of our head
+	for level := 0; level < height; level++ {
+		left.head.links[level].next.Store(t.head.links[level].next.Load())
+		left.head.links[level].span = t.head.links[level].span
+		left.head.links[level].aggregate = t.head.links[level].aggregate
+	}
+
+	// Each link crossing the split point now ends the left list, and the right list
+	// starts where it led.
+	for level := 0; level < height; level++ {
+		last := path[level]
+		if last == t.head {
+			last = left.head
+		}
+		crossing := &last.links[level]
+
+		right.head.links[level].next.Store(crossing.next.Load())
+		right.head.links[level].span = ranks[level] + crossing.span - split
+		crossing.next.Store(nil)
+		crossing.span = split - ranks[level]
+	}
+
+	if first := right.head.next(); first != nil {
+		first.prev.Store(nil)
+		right.tail.Store(t.tail.Load())
+	}
+	if path[0] != t.head {
+		left.tail.Store(path[0])
+	}
+
+	left.length.Store(int64(split))
+	left.settleHeight(height)
+	right.length.Store(int64(length - split))
+	right.settleHeight(height)
+	if right.aggregates != nil {
+		for level := 0; level < height; level++ {
+			right.summarize(right.head, level)
+		}
+	}
+
+	for id, k := range t.index {
+		if t.compare(k, key) < 0 {
+			left.index[id] = k
+		} else {
+			right.index[id] = k
+		}
+	}
+
+	t.reset()
+	return left, right
+}
@@ -0,0 +1,174 @@
+package skiplist
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/bplustree"
+)
+
+// sum is the aggregate used by most of the tests
+func sum(a int, b int) int {
+	return a + b
+}
+
+// fill inserts random records into a new list, keeping a model of them
+func fill(t *testing.T, rnd *rand.Rand, count int, opts ...Option) (collections.TreeMap[int, int], model) {
+	list, err := New[int, int](append(opts, WithSeed(rnd.Int63()), WithAggregate(0, sum))...)
+	require.NoError(t, err, "Should be able to initialize")
+
+	var expected model
+	for i := 0; i < count; i++ {
+		k, v := rnd.Intn(200), rnd.Intn(100)
+		expected = expected.add(k, v, list.Insert(k, v))
+	}
+
+	return list, expected
+}
+
+// TestSplitAt splits lists at random points, checking both halves keep every record,
+// and that the list left behind is empty but usable.
+func TestSplitAt(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for round := 0; round < 20; round++ {
+		t.Run(fmt.Sprintf("Round_%d", round), func(t *testing.T) {
+			list, expected := fill(t, rnd, rnd.Intn(300))
+			key := rnd.Intn(220) - 10
+
+			left, right := list.SplitAt(key)
+			split := slices.IndexFunc(expected, func(r modelRecord) bool { return r.key >= key })
+			if split < 0 {
+				split = len(expected)
+			}
+			requireMatches(t, rnd, left, expected[:split])
+			requireMatches(t, rnd, right, expected[split:])
+			requireMatches(t, rnd, list, nil)
+
+			// IDs carry on past those of the original list
+			var last collections.RecordID
+			for _, r := range expected {
+				last = max(last, r.id)
+			}
+			id := left.Insert(key, 1)
+			require.Greater(t, id, last, "Should not reuse IDs")
+			require.Equal(t, id, right.Insert(key, 1), "Halves should carry on from the same counter")
+		})
+	}
+}
+
+// TestMerge merges lists back together, whole and from other implementations
+func TestMerge(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	for round := 0; round < 20; round++ {
+		t.Run(fmt.Sprintf("Round_%d", round), func(t *testing.T) {
+			list, expected := fill(t, rnd, rnd.Intn(300))
+			other, incoming := fill(t, rnd, rnd.Intn(300))
+
+			// Merged records are renumbered after ours, keeping their order among themselves
+			offset := collections.RecordID(0)
+			for _, r := range expected {
+				offset = max(offset, r.id)
+			}
+			for _, r := range incoming {
+				expected = expected.add(r.key, r.value, r.id+offset)
+			}
+
+			list.Merge(other)
+			requireMatches(t, rnd, list, expected)
+			requireMatches(t, rnd, other, nil)
+		})
+	}
+
+	// Another implementation is copied from
+	list, err := New[int, int](WithSeed(1))
+	require.NoError(t, err, "Should be able to initialize")
+	tree, err := bplustree.New[int, int](4, 10)
+	require.NoError(t, err, "Should be able to initialize")
+	for i := 0; i < 20; i++ {
+		tree.Insert(i, i)
+	}
+	list.Merge(tree)
+	require.Equal(t, 20, list.Count(), "Should copy every record")
+	require.Equal(t, 20, tree.Count(), "Should leave the other tree alone")
+	requireConsistent(t, asList(t, list))
+}
+
+// TestMergeSplitRoundTrip splits lists and merges the halves back, checking every
+// record keeps its ID
+func TestMergeSplitRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for round := 0; round < 20; round++ {
+		list, expected := fill(t, rnd, rnd.Intn(300))
+
+		left, right := list.SplitAt(rnd.Intn(220) - 10)
+		right.Merge(left)
+		requireMatches(t, rnd, right, expected)
+		requireMatches(t, rnd, left, nil)
+		for _, r := range expected {
+			k, _, found := right.GetByID(r.id)
+			require.True(t, found, "Record %d should keep its ID", r.id)
+			require.Equal(t, r.key, k, "Record %d should keep its key", r.id)
+		}
+	}
+}
+
+// TestMergeUniqueKeys checks merged records replace those with the same key
+func TestMergeUniqueKeys(t *testing.T) {
+	list, err := New[int, int](WithUniqueKeys())
+	require.NoError(t, err, "Should be able to initialize")
+	other, err := New[int, int]()
+	require.NoError(t, err, "Should be able to initialize")
+
+	kept := list.Insert(1, 1)
+	list.Insert(2, 2)
+	other.Insert(2, 20)
+	other.Insert(2, 21)
+	other.Insert(3, 30)
+
+	list.Merge(other)
+	require.Equal(t, []int{21}, list.GetAll(2), "Should hold the last value merged")
+	require.Equal(t, 3, list.Count(), "Should hold a record per key")
+	_, v, found := list.GetByID(kept)
+	require.True(t, found && v == 1, "Should keep untouched records")
+	requireConsistent(t, asList(t, list))
+}
+
+// TestSnapshot checks a snapshot holds its records while the list changes, and can't be
+// written to.
+func TestSnapshot(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	list, expected := fill(t, rnd, 300)
+
+	snap := list.Snapshot()
+	require.Same(t, snap, snap.Snapshot(), "Snapshot of a snapshot should be itself")
+
+	for i := 0; i < 300; i++ {
+		list.Insert(rnd.Intn(200), rnd.Intn(100))
+		list.Delete(rnd.Intn(200))
+	}
+	list.UpdateByID(expected[0].id, -1)
+	requireMatches(t, rnd, snap, expected)
+
+	for name, write := range map[string]func(){
+		"Insert":      func() { snap.Insert(1, 1) },
+		"Upsert":      func() { snap.Upsert(1, 1) },
+		"Update":      func() { snap.Update(1, func(old int, exists bool) int { return old }) },
+		"UpdateByID":  func() { snap.UpdateByID(expected[0].id, 1) },
+		"Delete":      func() { snap.Delete(1) },
+		"DeleteByID":  func() { snap.DeleteByID(expected[0].id) },
+		"DeleteRange": func() { snap.DeleteRange(0, 10) },
+		"SplitAt":     func() { snap.SplitAt(10) },
+		"Merge":       func() { snap.Merge(list) },
+	} {
+		require.PanicsWithError(t, collections.ErrReadOnly.Error(), write, "%s should panic", name)
+	}
+
+	// Merging a snapshot copies it
+	list.Merge(snap)
+	require.Equal(t, len(expected), snap.Count(), "Snapshot should keep its records")
+}
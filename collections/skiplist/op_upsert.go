@@ -0,0 +1,72 @@
+package skiplist
+
+import "github.com/zeroflucs-given/generics/collections"
+
+// Upsert replaces the value of the first record stored against a key, or inserts a new
+// record if there is none. Returns the ID of the record, and true if an existing value
+// was replaced.
+func (t *skipList[K, V]) Upsert(key K, value V) (collections.RecordID, bool) {
+	t.lockWrite()
+	defer t.lock.Unlock()
+
+	return t.upsertInternal(key, value)
+}
+
+// Update computes a new value for the first record stored against a key, or for a new
+// record if there is none. The function is called under the write lock, so must not
+// call back into the list.
+func (t *skipList[K, V]) Update(key K, fn func(old V, exists bool) V) collections.RecordID {
+	t.lockWrite()
+	defer t.lock.Unlock()
+
+	path, ranks := t.path(t.before(key))
+	if existing := path[0].next(); existing != nil && t.compare(existing.key, key) == 0 {
+		t.replace(&path, existing, fn(existing.loadValue(), true))
+		return existing.id
+	}
+
+	var blank V
+	id := t.nextID()
+	t.insertAt(&path, &ranks, key, id, fn(blank, false))
+
+	return id
+}
+
+// UpdateByID replaces the value of the record with the specified ID. Returns true if
+// the record was found.
+func (t *skipList[K, V]) UpdateByID(id collections.RecordID, value V) bool {
+	t.lockWrite()
+	defer t.lock.Unlock()
+
+	key, found := t.index[id]
+	if !found {
+		return false
+	}
+
+	path, _ := t.path(t.recordBefore(key, id))
+	t.replace(&path, path[0].next(), value)
+
+	return true
+}
+
+// upsertInternal replaces the value of the first record for a key, or inserts a new
+// one. The caller must hold the write lock.
+func (t *skipList[K, V]) upsertInternal(key K, value V) (collections.RecordID, bool) {
+	path, ranks := t.path(t.before(key))
+	if existing := path[0].next(); existing != nil && t.compare(existing.key, key) == 0 {
+		t.replace(&path, existing, value)
+		return existing.id, true
+	}
+
+	id := t.nextID()
+	t.insertAt(&path, &ranks, key, id, value)
+
+	return id, false
+}
+
+// replace swaps the value of a record, which follows the path. The caller must hold the
+// write lock.
+func (t *skipList[K, V]) replace(path *[MaxLevel]*node[K, V], existing *node[K, V], value V) {
+	existing.value.Store(&value)
+	t.refreshPath(path, nil, int(t.height.Load()))
+}
@@ -0,0 +1,45 @@
+package skiplist
+
+// Option configures optional behaviour of a list when it is constructed.
+type Option func(o *options)
+
+// options holds the optional behaviours selected at construction
+type options struct {
+	uniqueKeys bool
+	seed       *int64
+	aggregates any // Aggregator of the value type, checked when the list is created
+}
+
+// WithUniqueKeys makes the list hold at most one record per key, like a map. Inserting
+// a key that already exists replaces the value of its record. Without this option the
+// list is a multimap, and every insert adds a new record.
+func WithUniqueKeys() Option {
+	return func(o *options) {
+		o.uniqueKeys = true
+	}
+}
+
+// WithSeed seeds the random source that picks the level of each new node, so that the
+// same sequence of writes always builds the same list. Without this option the list is
+// seeded at random.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = &seed
+	}
+}
+
+// WithAggregate keeps an aggregate of the values skipped over by every link, so that
+// Aggregate can reduce a range by following the longest links that fit rather than
+// visiting every record. Combining the identity with a value must give back the value,
+// and combine must be associative. It need not be commutative, as values are always
+// combined in key order.
+func WithAggregate[V any](identity V, combine func(a, b V) V) Option {
+	aggregates := &aggregator[V]{
+		identity: identity,
+		combine:  combine,
+	}
+
+	return func(o *options) {
+		o.aggregates = aggregates
+	}
+}
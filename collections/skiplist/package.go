@@ -0,0 +1,20 @@
+// Package skiplist is a skip list implementation of collections.TreeMap in pure Go,
+// using generics.
+//
+// Every record sits in a node that is linked into a random number of levels, with each
+// level skipping over about three in four of the nodes of the level below. Searches
+// start at the top level and drop down a level whenever the next node would overshoot,
+// giving logarithmic lookups on average without any rebalancing.
+//
+// # Concurrency
+//
+// Writers take the list to themselves, but readers take no lock at all. A writer links
+// a new node in from the bottom level up, only once the node is complete, and marks a
+// node as removed before unlinking it. Readers follow the links with atomic loads and
+// step over removed nodes, so they never wait on a writer and always see the records in
+// order. Lookups, iteration and cursors all read this way.
+//
+// Each link also records how many records it skips, and optionally an aggregate of
+// their values. These are not kept atomically, so Rank, Select, CountRange, Aggregate
+// and GetByID take a read lock instead, which only waits for writers.
+package skiplist
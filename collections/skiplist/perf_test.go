@@ -0,0 +1,105 @@
+package skiplist
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/bplustree"
+)
+
+// benchmarkDataSize is the number of records each workload inserts or scans
+const benchmarkDataSize = 100000
+
+// benchmarkTargets builds each implementation the benchmarks compare
+var benchmarkTargets = []struct {
+	name  string
+	build func(b *testing.B) collections.TreeMap[int, int]
+}{
+	{
+		name: "SkipList",
+		build: func(b *testing.B) collections.TreeMap[int, int] {
+			list, err := New[int, int](WithSeed(1))
+			if err != nil {
+				b.Logf("Error: %v", err)
+				b.FailNow()
+			}
+			return list
+		},
+	},
+	{
+		name: "BPlusTree",
+		build: func(b *testing.B) collections.TreeMap[int, int] {
+			tree, err := bplustree.New[int, int](27, 100)
+			if err != nil {
+				b.Logf("Error: %v", err)
+				b.FailNow()
+			}
+			return tree
+		},
+	},
+}
+
+// BenchmarkInsertsRandom inserts random keys into each implementation
+func BenchmarkInsertsRandom(b *testing.B) {
+	for _, target := range benchmarkTargets {
+		b.Run(target.name, func(b *testing.B) {
+			for b.Loop() {
+				m := target.build(b)
+				rnd := rand.New(rand.NewSource(benchmarkDataSize))
+				for i := 0; i < benchmarkDataSize; i++ {
+					m.Insert(int(rnd.Int31n(1000000)), i)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkInsertsSequential inserts ascending keys into each implementation
+func BenchmarkInsertsSequential(b *testing.B) {
+	for _, target := range benchmarkTargets {
+		b.Run(target.name, func(b *testing.B) {
+			for b.Loop() {
+				m := target.build(b)
+				for i := 0; i < benchmarkDataSize; i++ {
+					m.Insert(i, i)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkScan measures a full scan through the All iterator of each implementation
+func BenchmarkScan(b *testing.B) {
+	for _, target := range benchmarkTargets {
+		b.Run(target.name, func(b *testing.B) {
+			m := target.build(b)
+			for i, k := range rand.New(rand.NewSource(27)).Perm(benchmarkDataSize) {
+				m.Insert(k, i)
+			}
+
+			for b.Loop() {
+				for k, v := range m.All() {
+					_, _ = k, v
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGet measures point lookups of random keys in each implementation
+func BenchmarkGet(b *testing.B) {
+	for _, target := range benchmarkTargets {
+		b.Run(target.name, func(b *testing.B) {
+			m := target.build(b)
+			for i, k := range rand.New(rand.NewSource(27)).Perm(benchmarkDataSize) {
+				m.Insert(k, i)
+			}
+
+			rnd := rand.New(rand.NewSource(1))
+			for b.Loop() {
+				m.Get(rnd.Intn(benchmarkDataSize))
+			}
+		})
+	}
+}
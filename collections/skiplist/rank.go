@@ -0,0 +1,49 @@
+package skiplist
+
+// Rank gets the number of records with keys less than the key. This is the zero-based
+// position of the first record for the key, or where it would go.
+func (t *skipList[K, V]) Rank(key K) int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	_, ranks := t.path(t.before(key))
+	return ranks[0]
+}
+
+// Select gets the record at the zero-based position i in key order, by following the
+// longest links that don't overshoot it. The boolean indicates if the position was
+// within the list.
+func (t *skipList[K, V]) Select(i int) (K, V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if i < 0 || i >= int(t.length.Load()) {
+		return recordOf[K, V](nil)
+	}
+
+	current := t.head
+	traversed := 0
+	for level := int(t.height.Load()) - 1; level >= 0; level-- {
+		for next := current.links[level].next.Load(); next != nil && traversed+current.links[level].span <= i+1; next = current.links[level].next.Load() {
+			traversed += current.links[level].span
+			current = next
+		}
+	}
+
+	return recordOf(current)
+}
+
+// CountRange counts the records with keys between from and to, with both bounds
+// inclusive.
+func (t *skipList[K, V]) CountRange(from K, to K) int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.compare(from, to) > 0 {
+		return 0
+	}
+
+	_, start := t.path(t.before(from))
+	_, end := t.path(t.atOrBefore(to))
+	return end[0] - start[0]
+}
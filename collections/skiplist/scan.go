@@ -0,0 +1,46 @@
+package skiplist
+
+import (
+	"iter"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections/internal/scan"
+)
+
+// Count records
+func (t *skipList[K, V]) Count() int {
+	return int(t.length.Load())
+}
+
+// All iterates the records of the list in key order. No lock is held, so the loop body
+// may write to the list. Records written meanwhile may or may not be seen, but those
+// that are seen are always in order.
+func (t *skipList[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for current := live(t.head.next()); current != nil; current = live(current.next()) {
+			if !yield(current.key, current.loadValue()) {
+				return
+			}
+		}
+	}
+}
+
+// Backward iterates the records of the list in reverse key order, on the same terms
+// as All.
+func (t *skipList[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for current := liveBefore(t.tail.Load()); current != nil; current = liveBefore(current.prev.Load()) {
+			if !yield(current.key, current.loadValue()) {
+				return
+			}
+		}
+	}
+}
+
+// Scan records into a channel. The records are read up front, so the channel holds them
+// all and nothing is left running to feed it.
+//
+// Deprecated: Use All, which streams the records rather than holding them all at once.
+func (t *skipList[K, V]) Scan() chan generics.KeyValuePair[K, V] {
+	return scan.Buffered(t.All())
+}
@@ -0,0 +1,297 @@
+package skiplist
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+const (
+	// MaxLevel is the most levels a node can be linked into, which comfortably covers
+	// lists of billions of records.
+	MaxLevel = 32
+
+	// branching is the inverse of the chance that a node rises to the next level
+	branching = 4
+)
+
+// serials numbers lists, so that a merge always locks the pair in the same order
+var serials atomic.Uint64
+
+// New creates a new, empty skip list.
+func New[K generics.Comparable, V any](opts ...Option) (collections.TreeMap[K, V], error) {
+	return NewFunc[K, V](cmp.Compare[K], opts...)
+}
+
+// NewFunc creates a new, empty skip list with keys ordered by a comparator. The
+// comparator returns a negative number when a < b, a positive number when a > b and zero
+// when they are equal, in the style of cmp.Compare.
+func NewFunc[K any, V any](compare func(a, b K) int, opts ...Option) (collections.TreeMap[K, V], error) {
+	if compare == nil {
+		return nil, fmt.Errorf("a key comparator is required")
+	}
+
+	var settings options
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	var aggregates *aggregator[V]
+	if settings.aggregates != nil {
+		var ok bool
+		if aggregates, ok = settings.aggregates.(*aggregator[V]); !ok {
+			return nil, fmt.Errorf("invalid aggregate %T: does not match the value type", settings.aggregates)
+		} else if aggregates.combine == nil {
+			return nil, fmt.Errorf("an aggregate needs a combine function")
+		}
+	}
+
+	seed := rand.Int63()
+	if settings.seed != nil {
+		seed = *settings.seed
+	}
+
+	return newList(compare, settings.uniqueKeys, aggregates, seed), nil
+}
+
+// newList creates an empty list
+func newList[K any, V any](compare func(a, b K) int, uniqueKeys bool, aggregates *aggregator[V], seed int64) *skipList[K, V] {
+	result := &skipList[K, V]{
+		head:       newNode[K, V](MaxLevel),
+		index:      map[collections.RecordID]K{},
+		random:     rand.New(rand.NewSource(seed)),
+		compare:    compare,
+		uniqueKeys: uniqueKeys,
+		aggregates: aggregates,
+		serial:     serials.Add(1),
+	}
+	result.height.Store(1)
+
+	return result
+}
+
+// emptyLike creates an empty list with the same settings and record counter as this
+// one, so that record IDs carry on where ours left off. Its random source is seeded
+// from ours, keeping seeded lists repeatable.
+func (t *skipList[K, V]) emptyLike() *skipList[K, V] {
+	result := newList(t.compare, t.uniqueKeys, t.aggregates, t.random.Int63())
+	result.recordCount = t.recordCount
+
+	return result
+}
+
+type skipList[K any, V any] struct {
+	head        *node[K, V]                // Sentinel ahead of the first record, linked into every level
+	tail        atomic.Pointer[node[K, V]] // Last record, or nil if there are none
+	height      atomic.Int32               // Number of levels in use
+	length      atomic.Int64               // Number of records currently stored
+	resets      atomic.Uint64              // Bumped when the list hands its nodes on
+	lock        sync.RWMutex               // Held exclusively by writers, and shared by reads of spans, aggregates and the index
+	recordCount collections.RecordID       // Record counter
+	index       map[collections.RecordID]K // Key of each record, by ID
+	random      *rand.Rand                 // Picks the level of new nodes, only used by writers
+	compare     func(a, b K) int           // Orders the keys
+	uniqueKeys  bool                       // Hold at most one record per key?
+	aggregates  *aggregator[V]             // Aggregate kept per link, if any
+	readOnly    bool                       // Snapshots can't be written to
+	serial      uint64                     // Orders the locking of lists that are merged
+}
+
+// node holds a record, and its links to the following nodes at each of its levels.
+// Everything but the value and links is fixed once the node is published.
+type node[K any, V any] struct {
+	key     K
+	id      collections.RecordID
+	value   atomic.Pointer[V]
+	prev    atomic.Pointer[node[K, V]] // Preceding record at the bottom level, or nil for the first
+	removed atomic.Bool                // Set before the node is unlinked
+	links   []link[K, V]
+}
+
+// link joins a node to the next one at a level. It skips over span records, counting
+// the one it leads to, or all of those that follow if it leads nowhere.
+type link[K any, V any] struct {
+	next      atomic.Pointer[node[K, V]]
+	span      int
+	aggregate V // Values of the records skipped over, if the link leads anywhere
+}
+
+// newNode creates an unlinked node with a number of levels
+func newNode[K any, V any](levels int) *node[K, V] {
+	return &node[K, V]{
+		links: make([]link[K, V], levels),
+	}
+}
+
+// newRecord creates an unlinked node holding a record
+func newRecord[K any, V any](key K, id collections.RecordID, value V, levels int) *node[K, V] {
+	result := newNode[K, V](levels)
+	result.key = key
+	result.id = id
+	result.value.Store(&value)
+
+	return result
+}
+
+// loadValue gets the value of the record held by the node
+func (n *node[K, V]) loadValue() V {
+	return *n.value.Load()
+}
+
+// next gets the following node at the bottom level
+func (n *node[K, V]) next() *node[K, V] {
+	return n.links[0].next.Load()
+}
+
+// live steps forward from a node, which may be nil, to the first that hasn't been
+// removed. A removed node keeps the links it had when it was removed, so the nodes we
+// reach still follow it in order.
+func live[K any, V any](n *node[K, V]) *node[K, V] {
+	for n != nil && n.removed.Load() {
+		n = n.next()
+	}
+
+	return n
+}
+
+// liveBefore steps back from a node, which may be nil, to the first that hasn't been
+// removed.
+func liveBefore[K any, V any](n *node[K, V]) *node[K, V] {
+	for n != nil && n.removed.Load() {
+		n = n.prev.Load()
+	}
+
+	return n
+}
+
+// lockWrite takes the list to ourselves, panicking if it is read-only
+func (t *skipList[K, V]) lockWrite() {
+	if t.readOnly {
+		panic(collections.ErrReadOnly)
+	}
+
+	t.lock.Lock()
+}
+
+// nextID allocates a record ID. The caller must hold the write lock.
+func (t *skipList[K, V]) nextID() collections.RecordID {
+	t.recordCount++
+	return t.recordCount
+}
+
+// randomLevel picks the number of levels for a new node. The caller must hold the write
+// lock.
+func (t *skipList[K, V]) randomLevel() int {
+	level := 1
+	for level < MaxLevel && t.random.Intn(branching) == 0 {
+		level++
+	}
+
+	return level
+}
+
+// before places the records with keys less than the key before the position
+func (t *skipList[K, V]) before(key K) func(n *node[K, V]) bool {
+	return func(n *node[K, V]) bool {
+		return t.compare(n.key, key) < 0
+	}
+}
+
+// atOrBefore places the records with keys less than or equal to the key before the
+// position
+func (t *skipList[K, V]) atOrBefore(key K) func(n *node[K, V]) bool {
+	return func(n *node[K, V]) bool {
+		return t.compare(n.key, key) <= 0
+	}
+}
+
+// recordBefore places the records ahead of the one with a key and ID before the
+// position. Records sharing a key are held in the order of their IDs.
+func (t *skipList[K, V]) recordBefore(key K, id collections.RecordID) func(n *node[K, V]) bool {
+	return func(n *node[K, V]) bool {
+		comparison := t.compare(n.key, key)
+		return comparison < 0 || (comparison == 0 && n.id < id)
+	}
+}
+
+// predecessor gets the last node that precedes a position, or the head if there is
+// none, along with the node we found following it. It takes no lock, so either node
+// may have been removed by the time we reach it. The follower is the one we compared
+// against, rather than whatever follows the predecessor now, as a record added since
+// may belong before the position.
+func (t *skipList[K, V]) predecessor(precedes func(n *node[K, V]) bool) (*node[K, V], *node[K, V]) {
+	current := t.head
+	var next *node[K, V]
+	for level := int(t.height.Load()) - 1; level >= 0; level-- {
+		for next = current.links[level].next.Load(); next != nil && precedes(next); next = current.links[level].next.Load() {
+			current = next
+		}
+	}
+
+	return current, next
+}
+
+// firstAfter gets the first live node that doesn't precede a position, or nil if there
+// is none.
+func (t *skipList[K, V]) firstAfter(precedes func(n *node[K, V]) bool) *node[K, V] {
+	_, found := t.predecessor(precedes)
+	return live(found)
+}
+
+// lastBefore gets the last live node that precedes a position, or nil if there is none
+func (t *skipList[K, V]) lastBefore(precedes func(n *node[K, V]) bool) *node[K, V] {
+	found, _ := t.predecessor(precedes)
+	if found == t.head {
+		return nil
+	}
+
+	return liveBefore(found)
+}
+
+// path gets the last node that precedes a position at each level in use, along with
+// the number of records up to and including it. The caller must hold the lock.
+func (t *skipList[K, V]) path(precedes func(n *node[K, V]) bool) (path [MaxLevel]*node[K, V], ranks [MaxLevel]int) {
+	current := t.head
+	rank := 0
+	for level := int(t.height.Load()) - 1; level >= 0; level-- {
+		for next := current.links[level].next.Load(); next != nil && precedes(next); next = current.links[level].next.Load() {
+			rank += current.links[level].span
+			current = next
+		}
+
+		path[level] = current
+		ranks[level] = rank
+	}
+
+	return path, ranks
+}
+
+// settleHeight lowers the height from where it was to the highest level still in use.
+// The caller must hold the write lock.
+func (t *skipList[K, V]) settleHeight(height int) {
+	for height > 1 && t.head.links[height-1].next.Load() == nil {
+		height--
+	}
+
+	t.height.Store(int32(height))
+}
+
+// reset empties the list once its nodes have been handed to another, so cursors learn
+// that they've been left behind. The record counter is kept, so IDs are never reused.
+func (t *skipList[K, V]) reset() {
+	for level := range t.head.links {
+		t.head.links[level].next.Store(nil)
+		t.head.links[level].span = 0
+	}
+
+	t.tail.Store(nil)
+	t.height.Store(1)
+	t.length.Store(0)
+	t.index = map[collections.RecordID]K{}
+	t.resets.Add(1)
+}
@@ -0,0 +1,287 @@
+package skiplist
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeroflucs-given/generics"
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// modelRecord is a record of the model the tests check the list against
+type modelRecord struct {
+	key   int
+	value int
+	id    collections.RecordID
+}
+
+// model is a sorted slice of records, in the order the list should hold them
+type model []modelRecord
+
+// add inserts a record, after any others with the same key
+func (m model) add(key int, value int, id collections.RecordID) model {
+	at, _ := slices.BinarySearchFunc(m, key+1, func(r modelRecord, k int) int {
+		return cmp.Compare(r.key, k)
+	})
+	return slices.Insert(m, at, modelRecord{key: key, value: value, id: id})
+}
+
+// first gets the index of the first record with a key, or -1
+func (m model) first(key int) int {
+	at, found := slices.BinarySearchFunc(m, key, func(r modelRecord, k int) int {
+		return cmp.Compare(r.key, k)
+	})
+	if !found {
+		return -1
+	}
+	return at
+}
+
+// requireMatches checks every read of the list against the model
+func requireMatches(t *testing.T, rnd *rand.Rand, m collections.TreeMap[int, int], expected model) {
+	t.Helper()
+
+	var all []generics.KeyValuePair[int, int]
+	for k, v := range m.All() {
+		all = append(all, generics.KeyValuePair[int, int]{Key: k, Value: v})
+	}
+	var backward []generics.KeyValuePair[int, int]
+	for k, v := range m.Backward() {
+		backward = append(backward, generics.KeyValuePair[int, int]{Key: k, Value: v})
+	}
+	slices.Reverse(backward)
+
+	var records []generics.KeyValuePair[int, int]
+	for _, r := range expected {
+		records = append(records, generics.KeyValuePair[int, int]{Key: r.key, Value: r.value})
+	}
+	require.Equal(t, records, all, "All should visit the records in order")
+	require.Equal(t, records, backward, "Backward should visit the records in reverse")
+	require.Equal(t, len(expected), m.Count(), "Count should match")
+
+	for i := 0; i < 20; i++ {
+		probe := rnd.Intn(220) - 10
+		lower, _ := slices.BinarySearchFunc(expected, probe, func(r modelRecord, k int) int { return cmp.Compare(r.key, k) })
+		upper, _ := slices.BinarySearchFunc(expected, probe+1, func(r modelRecord, k int) int { return cmp.Compare(r.key, k) })
+
+		v, found := m.Get(probe)
+		if at := expected.first(probe); at >= 0 {
+			require.True(t, found, "Should find %d", probe)
+			require.Equal(t, expected[at].value, v, "Should get the first value of %d", probe)
+		} else {
+			require.False(t, found, "Should not find %d", probe)
+		}
+
+		var values []int
+		for _, r := range expected[lower:upper] {
+			values = append(values, r.value)
+		}
+		require.Equal(t, values, m.GetAll(probe), "Should get every value of %d", probe)
+		require.Equal(t, lower, m.Rank(probe), "Rank of %d should match", probe)
+
+		requireRecord := func(name string, at int, k int, v int, found bool) {
+			if at < 0 || at >= len(expected) {
+				require.False(t, found, "%s of %d should not be found", name, probe)
+				return
+			}
+			require.True(t, found, "%s of %d should be found", name, probe)
+			require.Equal(t, expected[at].key, k, "%s of %d should have the right key", name, probe)
+			require.Equal(t, expected[at].value, v, "%s of %d should have the right value", name, probe)
+		}
+		k, v, found := m.Floor(probe)
+		requireRecord("Floor", upper-1, k, v, found)
+		k, v, found = m.Lower(probe)
+		requireRecord("Lower", lower-1, k, v, found)
+		k, v, found = m.Ceiling(probe)
+		requireRecord("Ceiling", lower, k, v, found)
+		k, v, found = m.Higher(probe)
+		requireRecord("Higher", upper, k, v, found)
+
+		selected := rnd.Intn(len(expected)+2) - 1
+		k, v, found = m.Select(selected)
+		requireRecord("Select", selected, k, v, found)
+
+		to := probe + rnd.Intn(30)
+		end, _ := slices.BinarySearchFunc(expected, to+1, func(r modelRecord, k int) int { return cmp.Compare(r.key, k) })
+		var within []generics.KeyValuePair[int, int]
+		within = append(within, records[lower:end]...)
+		require.Equal(t, within, m.Range(probe, to), "Range of %d to %d should match", probe, to)
+		require.Equal(t, end-lower, m.CountRange(probe, to), "Count of %d to %d should match", probe, to)
+		total := 0
+		for _, r := range expected[lower:end] {
+			total += r.value
+		}
+		require.Equal(t, total, m.Aggregate(probe, to), "Aggregate of %d to %d should match", probe, to)
+	}
+
+	k, v, found := m.Min()
+	if len(expected) == 0 {
+		require.False(t, found, "Empty list should have no minimum")
+	} else {
+		require.Equal(t, []int{expected[0].key, expected[0].value}, []int{k, v}, "Should have the minimum")
+	}
+	k, v, found = m.Max()
+	if len(expected) == 0 {
+		require.False(t, found, "Empty list should have no maximum")
+	} else {
+		last := expected[len(expected)-1]
+		require.Equal(t, []int{last.key, last.value}, []int{k, v}, "Should have the maximum")
+	}
+
+	for _, r := range expected {
+		k, v, found := m.GetByID(r.id)
+		require.True(t, found, "Should find record %d", r.id)
+		require.Equal(t, []int{r.key, r.value}, []int{k, v}, "Should get record %d", r.id)
+	}
+
+	requireConsistent(t, asList(t, m))
+}
+
+// TestNew checks construction rejects what it can't work with
+func TestNew(t *testing.T) {
+	_, err := NewFunc[int, int](nil)
+	require.Error(t, err, "Should require a comparator")
+
+	_, err = New[int, int](WithAggregate("", func(a string, b string) string { return a + b }))
+	require.Error(t, err, "Should refuse an aggregate of another type")
+	_, err = New[int, int](WithAggregate[int](0, nil))
+	require.Error(t, err, "Should refuse an aggregate without a combine function")
+
+	list, err := New[int, int]()
+	require.NoError(t, err, "Should be able to initialize")
+	require.Zero(t, list.Count(), "Should start empty")
+	require.PanicsWithError(t, collections.ErrNoAggregate.Error(), func() { list.Aggregate(0, 1) }, "Should have no aggregate")
+}
+
+// TestNewFunc orders the keys with a comparator
+func TestNewFunc(t *testing.T) {
+	list, err := NewFunc[int, string](func(a, b int) int { return b - a })
+	require.NoError(t, err, "Should be able to initialize")
+
+	for i := 0; i < 10; i++ {
+		list.Insert(i, fmt.Sprint(i))
+	}
+
+	var keys []int
+	for k := range list.All() {
+		keys = append(keys, k)
+	}
+	require.Equal(t, []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}, keys, "Should hold the keys in the comparator order")
+	k, _, _ := list.Floor(4)
+	require.Equal(t, 4, k, "Floor should follow the comparator")
+	k, _, _ = list.Lower(4)
+	require.Equal(t, 5, k, "Lower should follow the comparator")
+}
+
+// TestSeed checks lists seeded alike are built alike
+func TestSeed(t *testing.T) {
+	levels := func(seed int64) []int {
+		list, err := New[int, int](WithSeed(seed))
+		require.NoError(t, err, "Should be able to initialize")
+		for i := 0; i < 1000; i++ {
+			list.Insert(i, i)
+		}
+
+		var result []int
+		for current := asList(t, list).head.next(); current != nil; current = current.next() {
+			result = append(result, len(current.links))
+		}
+		return result
+	}
+
+	require.Equal(t, levels(7), levels(7), "Same seed should give the same levels")
+	require.NotEqual(t, levels(7), levels(8), "Different seeds should give different levels")
+	require.Greater(t, slices.Max(levels(7)), 2, "Some nodes should rise above the lower levels")
+}
+
+// TestUniqueKeys checks inserts replace records rather than adding more
+func TestUniqueKeys(t *testing.T) {
+	list, err := New[int, int](WithUniqueKeys())
+	require.NoError(t, err, "Should be able to initialize")
+
+	first := list.Insert(1, 10)
+	require.Equal(t, first, list.Insert(1, 11), "Should keep the record ID")
+	require.Equal(t, []int{11}, list.GetAll(1), "Should replace the value")
+	require.Equal(t, 1, list.Count(), "Should hold a single record")
+}
+
+// TestModel runs random writes against the list, checking every read against a model
+// along the way.
+func TestModel(t *testing.T) {
+	for _, unique := range []bool{false, true} {
+		t.Run(fmt.Sprintf("UniqueKeys_%v", unique), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			opts := []Option{WithSeed(1), WithAggregate(0, func(a int, b int) int { return a + b })}
+			if unique {
+				opts = append(opts, WithUniqueKeys())
+			}
+			list, err := New[int, int](opts...)
+			require.NoError(t, err, "Should be able to initialize")
+
+			var expected model
+			for round := 0; round < 20; round++ {
+				for i := 0; i < 100; i++ {
+					k := rnd.Intn(200)
+					v := rnd.Intn(100)
+					switch op := rnd.Intn(10); {
+					case op < 4:
+						id := list.Insert(k, v)
+						if at := expected.first(k); unique && at >= 0 {
+							require.Equal(t, expected[at].id, id, "Should replace the record of %d", k)
+							expected[at].value = v
+						} else {
+							expected = expected.add(k, v, id)
+						}
+					case op == 4:
+						id, replaced := list.Upsert(k, v)
+						at := expected.first(k)
+						require.Equal(t, at >= 0, replaced, "Should replace the value of %d if it exists", k)
+						if replaced {
+							require.Equal(t, expected[at].id, id, "Should replace the first record of %d", k)
+							expected[at].value = v
+						} else {
+							expected = expected.add(k, v, id)
+						}
+					case op == 5:
+						id := list.Update(k, func(old int, exists bool) int { return old + 1 })
+						if at := expected.first(k); at >= 0 {
+							require.Equal(t, expected[at].id, id, "Should update the first record of %d", k)
+							expected[at].value++
+						} else {
+							expected = expected.add(k, 1, id)
+						}
+					case op == 6:
+						require.Equal(t, expected.first(k) >= 0, list.Delete(k), "Should delete %d if it exists", k)
+						expected = slices.DeleteFunc(expected, func(r modelRecord) bool { return r.key == k })
+					case op == 7 && len(expected) > 0:
+						at := rnd.Intn(len(expected))
+						require.True(t, list.DeleteByID(expected[at].id), "Should delete by ID")
+						require.False(t, list.DeleteByID(expected[at].id), "Should only delete once")
+						expected = slices.Delete(expected, at, at+1)
+					case op == 8 && len(expected) > 0:
+						at := rnd.Intn(len(expected))
+						require.True(t, list.UpdateByID(expected[at].id, v), "Should update by ID")
+						expected[at].value = v
+					case op == 9:
+						to := k + rnd.Intn(20)
+						before := len(expected)
+						expected = slices.DeleteFunc(expected, func(r modelRecord) bool { return r.key >= k && r.key <= to })
+						require.Equal(t, before-len(expected), list.DeleteRange(k, to), "Should delete the range %d to %d", k, to)
+					}
+				}
+
+				requireMatches(t, rnd, list, expected)
+			}
+
+			require.False(t, list.UpdateByID(-1, 0), "Should not update a missing record")
+			_, _, found := list.GetByID(-1)
+			require.False(t, found, "Should not find a missing record")
+			require.Zero(t, list.DeleteRange(10, 5), "Reversed bounds should delete nothing")
+		})
+	}
+}
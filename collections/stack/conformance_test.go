@@ -0,0 +1,23 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/zeroflucs-given/generics/collections"
+	"github.com/zeroflucs-given/generics/collections/collectionstest"
+)
+
+// TestQueueConformance checks the stack honours the Queue[T] contract, handing back the
+// newest item first, within its capacity
+func TestQueueConformance(t *testing.T) {
+	collectionstest.RunQueueSuite(t, collectionstest.LIFO, func(capacity int) collections.Queue[int] {
+		return NewStack[int](capacity)
+	}, collectionstest.WithBoundedCapacity())
+}
+
+// TestBlockingQueueConformance checks the stack honours the BlockingQueue[T] contract
+func TestBlockingQueueConformance(t *testing.T) {
+	collectionstest.RunBlockingQueueSuite(t, func(capacity int) collections.BlockingQueue[int] {
+		return NewStack[int](capacity)
+	}, collectionstest.WithBoundedCapacity())
+}
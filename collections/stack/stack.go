@@ -2,6 +2,8 @@ package stack
 
 import (
 	"sync"
+
+	"github.com/zeroflucs-given/generics/collections/internal/signal"
 )
 
 // NewStack creates a new instance of a stack with an initial capacity
@@ -13,9 +15,11 @@ func NewStack[T any](capacity int) *Stack[T] {
 
 // Stack is our type that implements a stack of data items
 type Stack[T any] struct {
-	data []T
-	head int
-	lock sync.RWMutex
+	data    []T
+	head    int
+	lock    sync.RWMutex
+	closed  bool
+	changed signal.Broadcast // Notified as values are pushed or popped, and on close
 }
 
 // Count of the data inside the stack
@@ -0,0 +1,67 @@
+package stack
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeroflucs-given/generics/collections"
+)
+
+// Ensure we meet the BlockingQueue[T] interface at compile time
+var _ collections.BlockingQueue[int] = (*Stack[int])(nil)
+
+// PushWait pushes a value onto the stack, waiting for room if it is full
+func (s *Stack[T]) PushWait(ctx context.Context, value T) error {
+	for {
+		s.lock.Lock()
+
+		err := s.pushInternal(value)
+		if !errors.Is(err, collections.ErrBufferFull) {
+			s.lock.Unlock()
+			return err
+		}
+
+		changed := s.changed.Wait()
+		s.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// PopWait pops a value from the stack, waiting for one if it is empty
+func (s *Stack[T]) PopWait(ctx context.Context) (T, error) {
+	for {
+		s.lock.Lock()
+
+		found, result := s.popInternal()
+		if found {
+			s.lock.Unlock()
+			return result, nil
+		}
+		if s.closed {
+			s.lock.Unlock()
+			return result, collections.ErrClosed
+		}
+
+		changed := s.changed.Wait()
+		s.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+}
+
+// Close the stack to further pushes, waking everything waiting on it
+func (s *Stack[T]) Close() {
+	s.lock.Lock()
+	s.closed = true
+	s.changed.Notify()
+	s.lock.Unlock()
+}
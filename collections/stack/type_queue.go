@@ -8,18 +8,10 @@ var _ collections.Queue[int] = (*Stack[int])(nil)
 // Push a value into the stack
 func (s *Stack[T]) Push(value T) error {
 	s.lock.Lock()
-
-	if len(s.data) == s.head {
-		s.lock.Unlock()
-
-		return collections.ErrBufferFull
-	}
-
-	s.data[s.head] = value
-	s.head = s.head + 1
-
+	err := s.pushInternal(value)
 	s.lock.Unlock()
-	return nil
+
+	return err
 }
 
 // Peek the item that would be returned from pop. Note that for LIFO situations the
@@ -43,21 +35,46 @@ func (s *Stack[T]) Peek() (bool, T) {
 
 // Pop a value from the stack
 func (s *Stack[T]) Pop() (bool, T) {
+	s.lock.Lock()
+	found, v := s.popInternal()
+	s.lock.Unlock()
+
+	return found, v
+}
+
+// pushInternal pushes a value with the lock held
+func (s *Stack[T]) pushInternal(value T) error {
+	if s.closed {
+		return collections.ErrClosed
+	}
+
+	if len(s.data) == s.head {
+		return collections.ErrBufferFull
+	}
+
+	s.data[s.head] = value
+	s.head = s.head + 1
+
+	s.changed.Notify()
+
+	return nil
+}
+
+// popInternal pops a value with the lock held
+func (s *Stack[T]) popInternal() (bool, T) {
 	found := false
 	var v T
 	var blank T
 
-	s.lock.Lock()
-
 	if s.head > 0 {
 		dataIndex := s.head - 1
 		v = s.data[dataIndex]
 		found = true
 		s.data[dataIndex] = blank
 		s.head = s.head - 1
-	}
 
-	s.lock.Unlock()
+		s.changed.Notify()
+	}
 
 	return found, v
 }
@@ -1,6 +1,8 @@
 package collections
 
 import (
+	"iter"
+
 	"github.com/zeroflucs-given/generics"
 )
 
@@ -8,14 +10,169 @@ import (
 // differentiate tree records.
 type RecordID int64
 
-// TreeMap is our interface for a key-value map stored in a seekable tree format.
-type TreeMap[K generics.Comparable, V any] interface {
-	// Insert a value into the tree.
+// Cursor is a position within a TreeMap that can be moved back and forth. Cursors are
+// not safe for use by multiple goroutines at once.
+//
+// A cursor on a live tree holds no lock between moves, so an open cursor never blocks
+// writers. In exchange it only sees the tree as it is at each move, and implementations
+// may stop it with ErrCursorInvalidated when a write changes the records around it.
+// For a cursor that sees a fixed set of records and is never invalidated, seek on a
+// Snapshot of the tree instead:
+//
+//	c := tree.Snapshot().Seek(key)
+type Cursor[K any, V any] interface {
+	// Next moves to the following record. Returns false if there are no more records,
+	// or the cursor is no longer usable (see Err).
+	Next() bool
+
+	// Prev moves to the preceding record. Returns false if there are no more records,
+	// or the cursor is no longer usable (see Err).
+	Prev() bool
+
+	// Key of the current record
+	Key() K
+
+	// Value of the current record
+	Value() V
+
+	// RecordID of the current record
+	RecordID() RecordID
+
+	// Err gets the reason the cursor stopped being usable, such as ErrCursorInvalidated.
+	Err() error
+
+	// Close the cursor, releasing any resources it holds
+	Close()
+}
+
+// TreeMap is our interface for a key-value map stored in a seekable tree format. Keys
+// are kept in the order defined by the comparator of the implementation.
+type TreeMap[K any, V any] interface {
+	// Insert a value into the tree. Depending on the implementation, the tree may hold
+	// many records per key, or replace the value of an existing record.
 	Insert(key K, value V) RecordID
 
-	// Scan records
+	// Upsert replaces the value of the first record stored against a key, or inserts
+	// a new record if there is none. Returns the ID of the record, and true if an
+	// existing value was replaced.
+	Upsert(key K, value V) (RecordID, bool)
+
+	// Update computes a new value for the first record stored against a key, from the
+	// existing value if there is one. The function must not call back into the tree.
+	Update(key K, fn func(old V, exists bool) V) RecordID
+
+	// Delete all records stored against a key. Returns true if any records were
+	// removed.
+	Delete(key K) bool
+
+	// UpdateByID replaces the value of the record with the specified ID. Returns true
+	// if the record was found.
+	UpdateByID(id RecordID, value V) bool
+
+	// DeleteByID deletes the record with the specified ID. Returns true if the record
+	// was found.
+	DeleteByID(id RecordID) bool
+
+	// DeleteRange deletes the records with keys between from and to, with both bounds
+	// inclusive. Returns the number of records removed.
+	DeleteRange(from K, to K) int
+
+	// SplitAt moves the records of the tree into two new trees: those with keys less
+	// than the key, and those with keys greater than or equal to it. The tree itself is
+	// left empty. Record IDs are kept.
+	SplitAt(key K) (left TreeMap[K, V], right TreeMap[K, V])
+
+	// Merge moves the records of another tree into this one, leaving the other tree
+	// empty. Where none of the records share an ID with one of this tree, they may keep
+	// their IDs, and the two halves of a SplitAt always do. Otherwise the records are
+	// given new IDs following on from those of this tree, so that IDs stay unique: any
+	// RecordID held for a record of the other tree no longer refers to it, and may come
+	// to refer to another record. Read-only trees, such as snapshots, are copied from
+	// rather than emptied. Both trees must order their keys the same way.
+	Merge(other TreeMap[K, V])
+
+	// Get the value of the first record stored against a key. The boolean indicates
+	// if the key was found.
+	Get(key K) (V, bool)
+
+	// GetAll gets the values of every record stored against a key, in the order
+	// they were inserted.
+	GetAll(key K) []V
+
+	// GetByID gets the key and value of the record with the specified ID. The boolean
+	// indicates if the record was found.
+	GetByID(id RecordID) (K, V, bool)
+
+	// Range gets the records with keys between from and to, with both bounds
+	// inclusive, in key order.
+	Range(from K, to K) []generics.KeyValuePair[K, V]
+
+	// All iterates the records in key order. The tree is read-locked until the
+	// iteration completes or is stopped, so the loop body must not call back into
+	// the tree.
+	All() iter.Seq2[K, V]
+
+	// Backward iterates the records in reverse key order, with the same locking
+	// rules as All.
+	Backward() iter.Seq2[K, V]
+
+	// Rank gets the number of records with keys less than the key. This is the
+	// zero-based position of the first record for the key, or where it would go.
+	Rank(key K) int
+
+	// Select gets the record at the zero-based position i in key order. The boolean
+	// indicates if the position was within the tree.
+	Select(i int) (K, V, bool)
+
+	// CountRange counts the records with keys between from and to, with both bounds
+	// inclusive.
+	CountRange(from K, to K) int
+
+	// Aggregate combines the values of the records with keys between from and to, with
+	// both bounds inclusive, in key order. An empty range gives the identity. Panics
+	// with ErrNoAggregate if the tree was not built to keep an aggregate.
+	Aggregate(from K, to K) V
+
+	// Floor gets the record with the largest key less than or equal to the key. The
+	// boolean indicates if there was such a record.
+	Floor(key K) (K, V, bool)
+
+	// Ceiling gets the record with the smallest key greater than or equal to the key.
+	// The boolean indicates if there was such a record.
+	Ceiling(key K) (K, V, bool)
+
+	// Lower gets the record with the largest key strictly less than the key. The
+	// boolean indicates if there was such a record.
+	Lower(key K) (K, V, bool)
+
+	// Higher gets the record with the smallest key strictly greater than the key. The
+	// boolean indicates if there was such a record.
+	Higher(key K) (K, V, bool)
+
+	// Min gets the record with the smallest key. The boolean indicates if the tree
+	// had any records.
+	Min() (K, V, bool)
+
+	// Max gets the record with the largest key. The boolean indicates if the tree
+	// had any records.
+	Max() (K, V, bool)
+
+	// Seek creates a cursor positioned just before the first record with a key greater
+	// than or equal to the key. Next moves onto that record, Prev onto the one before.
+	// Seeking on a Snapshot gives a cursor that writes to the tree can't invalidate.
+	Seek(key K) Cursor[K, V]
+
+	// Scan records into a channel. Every record is read into the channel before it is
+	// returned, so a consumer may stop reading at any point.
+	//
+	// Deprecated: Scan holds every record at once. Use All, which streams them.
 	Scan() chan generics.KeyValuePair[K, V]
 
 	// Count records
 	Count() int
+
+	// Snapshot takes a point-in-time, read-only copy of the tree. Later writes to the
+	// tree are not visible through the snapshot, and reading the snapshot does not
+	// block writers. Writes to the snapshot itself panic with ErrReadOnly.
+	Snapshot() TreeMap[K, V]
 }
@@ -6,8 +6,10 @@ import (
 )
 
 // KeyValuePair is a pairing of key/values, for when we have to represent map
-// sets as a list/slice.
-type KeyValuePair[K comparable, V any] struct {
+// sets as a list/slice. Keys need not be comparable, so that ordered structures
+// using their own comparators can share the type. This was loosened from comparable,
+// so generic code that compares or hashes the key must constrain it itself.
+type KeyValuePair[K any, V any] struct {
 	Key   K
 	Value V
 }